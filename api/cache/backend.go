@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Backend stores encoded cache entries by kind and key
+type Backend interface {
+	// Load returns the encoded entry, or ErrNotFound if it does not exist
+	Load(kind, key string) ([]byte, error)
+	// Store stores the encoded entry, replacing the existing one atomically
+	Store(kind, key string, data []byte) error
+	// Touch records the access to the entry for the least-recently-used eviction
+	Touch(kind, key string)
+	// Delete removes the entry
+	// Removing a missing entry is not an error.
+	Delete(kind, key string) error
+	// List returns the stats of all entries
+	List() ([]Stat, error)
+	// Lock acquires the lock to fill the entry, shared with other processes using the same storage
+	// It returns the function releasing the lock.
+	Lock(kind, key string) (unlock func())
+	// Clear removes all entries
+	Clear() error
+	// Location describes where the entries are stored, such as a directory or a file
+	Location() string
+}
+
+// Stat describes a stored entry
+type Stat struct {
+	Kind string
+	Key  string
+	Size int64
+	// AccessedAt is the time the entry was last used
+	AccessedAt time.Time
+	// Path is the file of the entry, if it is stored in its own file
+	Path string
+}
+
+// ErrNotFound is returned by backends when the entry does not exist
+var ErrNotFound = errors.New("cache: entry not found")
+
+// Backend names accepted by Open
+const (
+	BackendFS     = "fs"
+	BackendBolt   = "bolt"
+	BackendMemory = "memory"
+)
+
+var (
+	defaultMu      sync.RWMutex
+	defaultBackend Backend
+)
+
+// Default returns the backend of caches without their own backend
+func Default() Backend {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultBackend
+}
+
+// SetDefault sets the backend of caches without their own backend
+func SetDefault(b Backend) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultBackend = b
+}
+
+// Open returns the backend of the name storing entries in DefaultDir
+// "fs" stores each entry in its own file, "bolt" stores all entries in a single file,
+// and "memory" keeps entries in memory until the process exits.
+func Open(name string) (Backend, error) {
+	switch name {
+	case BackendFS, "":
+		return NewFS(DefaultDir), nil
+	case BackendBolt:
+		return NewBolt(filepath.Join(DefaultDir, "cache.db")), nil
+	case BackendMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("cache: unknown backend %q", name)
+	}
+}
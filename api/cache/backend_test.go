@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackends(t *testing.T) {
+	backends := map[string]func(dir string) Backend{
+		BackendFS:     NewFS,
+		BackendBolt:   func(dir string) Backend { return NewBolt(filepath.Join(dir, "cache.db")) },
+		BackendMemory: func(string) Backend { return NewMemory() },
+	}
+	interval := boltTouchInterval
+	boltTouchInterval = 0
+	t.Cleanup(func() { boltTouchInterval = interval })
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			b := open(t.TempDir())
+
+			if _, err := b.Load("fetch", "npmjs.com:react"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load() of missing entry error = %v, want ErrNotFound", err)
+			}
+			if stats, err := b.List(); err != nil || len(stats) != 0 {
+				t.Errorf("List() of empty backend = %v, %v", stats, err)
+			}
+
+			for _, e := range []struct{ kind, key, data string }{
+				{"fetch", "npmjs.com:react", "react"},
+				{"fetch", "npmjs.com:@types/node", "node"},
+				{"html", "https://example.com/", "<html>"},
+				{"fetch", "npmjs.com:react", "react v2"},
+			} {
+				if err := b.Store(e.kind, e.key, []byte(e.data)); err != nil {
+					t.Fatalf("Store() error = %v", err)
+				}
+			}
+			data, err := b.Load("fetch", "npmjs.com:react")
+			if err != nil || string(data) != "react v2" {
+				t.Errorf("Load() = %q, %v", data, err)
+			}
+
+			before := time.Now()
+			time.Sleep(10 * time.Millisecond)
+			b.Touch("html", "https://example.com/")
+
+			stats, err := b.List()
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var got []string
+			for _, st := range stats {
+				got = append(got, st.Kind+" "+st.Key)
+				if st.Kind == "html" && st.AccessedAt.Before(before) {
+					t.Errorf("Touch() did not update the access time: %v", st.AccessedAt)
+				}
+			}
+			sort.Strings(got)
+			want := []string{"fetch npmjs.com:@types/node", "fetch npmjs.com:react", "html https://example.com/"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+
+			if err := b.Delete("fetch", "npmjs.com:react"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := b.Load("fetch", "npmjs.com:react"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load() of deleted entry error = %v, want ErrNotFound", err)
+			}
+
+			unlock := b.Lock("fetch", "npmjs.com:react")
+			unlock()
+
+			if err := b.Clear(); err != nil {
+				t.Fatalf("Clear() error = %v", err)
+			}
+			if stats, err := b.List(); err != nil || len(stats) != 0 {
+				t.Errorf("List() after Clear() = %v, %v", stats, err)
+			}
+		})
+	}
+}
+
+func TestBoltTouch(t *testing.T) {
+	b := NewBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err := b.Store("fetch", "npmjs.com:react", []byte("react")); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	stats, err := b.List()
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("List() = %v, %v", stats, err)
+	}
+	stored := stats[0].AccessedAt
+
+	// Access times within the interval are not written again
+	time.Sleep(10 * time.Millisecond)
+	b.Touch("fetch", "npmjs.com:react")
+	if stats, _ := b.List(); !stats[0].AccessedAt.Equal(stored) {
+		t.Errorf("Touch() within the interval updated the access time to %v", stats[0].AccessedAt)
+	}
+
+	// Evicting and pruning several entries removes them in a single transaction
+	if err := b.Store("fetch", "npmjs.com:vue", []byte("vue")); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if err := deleteAll(b, []Stat{{Kind: "fetch", Key: "npmjs.com:react"}, {Kind: "fetch", Key: "npmjs.com:vue"}}); err != nil {
+		t.Fatalf("deleteAll() error = %v", err)
+	}
+	if stats, err := b.List(); err != nil || len(stats) != 0 {
+		t.Errorf("List() after deleteAll() = %v, %v", stats, err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	b := NewMemory()
+	pages, strs := New[string]("test-page"), New[string]("test-str")
+	for _, c := range []*Cache[string]{pages, strs} {
+		c.SetBackend(b)
+		if _, err := c.GetOrSet("key", func() (string, error) { return "v1", nil }, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Bumping the schema of a kind invalidates only the entries of the kind
+	pages.SetSchema(1)
+	refetch := func() (string, error) { return "v2", nil }
+	if got, _ := pages.GetOrSet("key", refetch, false); got != "v2" {
+		t.Errorf("GetOrSet() after schema change = %q, want refetched value", got)
+	}
+	if got, _ := strs.GetOrSet("key", refetch, false); got != "v1" {
+		t.Errorf("GetOrSet() of other kind = %q, want cached value", got)
+	}
+	if got, _ := pages.GetOrSet("key", refetch, false); got != "v2" {
+		t.Errorf("GetOrSet() of entry with new schema = %q", got)
+	}
+}
+
+func TestSchemaOfChangedType(t *testing.T) {
+	b := NewMemory()
+	pages := New[string]("test-page")
+	pages.SetBackend(b)
+	if err := pages.Set("https://example.com/", "<html>"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The type of the values changed along with the schema version
+	type response struct {
+		Body string
+		ETag string
+	}
+	responses := New[response]("test-page")
+	responses.SetBackend(b)
+	responses.SetSchema(1)
+	if _, err := responses.loadEntry(b, "https://example.com/"); !errors.Is(err, errSchemaMismatch) {
+		t.Errorf("loadEntry() error = %v, want %v", err, errSchemaMismatch)
+	}
+	got, err := responses.GetOrSet("https://example.com/", func() (response, error) {
+		return response{Body: "<html>", ETag: `"v2"`}, nil
+	}, false)
+	if err != nil || got.ETag != `"v2"` {
+		t.Errorf("GetOrSet() after schema change = %+v, %v", got, err)
+	}
+	if e, ok := responses.Get("https://example.com/"); !ok || e.Schema != 1 {
+		t.Errorf("Get() of refetched entry = %+v, %v", e, ok)
+	}
+}
+
+func TestFSKeys(t *testing.T) {
+	dir := t.TempDir()
+	b := NewFS(dir)
+
+	// Keys normalized to the same name by older versions
+	keys := []string{"npmjs.com:@scope/pkg", "npmjs.com:_scope/pkg", "https://example.com/a/b/c/d/e/f/g?q=1"}
+	for _, key := range keys {
+		if err := b.Store("fetch", key, []byte(key)); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+	for _, key := range keys {
+		if data, err := b.Load("fetch", key); err != nil || string(data) != key {
+			t.Errorf("Load(%q) = %q, %v", key, data, err)
+		}
+	}
+
+	stats, err := b.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var got []string
+	for _, st := range stats {
+		got = append(got, st.Key)
+		if rel, _ := filepath.Rel(dir, st.Path); !entryFilePattern.MatchString(filepath.ToSlash(rel)) {
+			t.Errorf("entry of %q stored in %s", st.Key, rel)
+		}
+	}
+	sort.Strings(got)
+	want := append([]string(nil), keys...)
+	sort.Strings(want)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The index lists the keys of the files
+	index, err := os.ReadFile(filepath.Join(dir, fsIndexFile))
+	if err != nil {
+		t.Fatalf("reading index error = %v", err)
+	}
+	for _, key := range keys {
+		if !strings.Contains(string(index), "\t"+key+"\n") {
+			t.Errorf("index does not contain %q:\n%s", key, index)
+		}
+	}
+
+	// Compacting drops deleted entries from the index
+	if err := b.Delete("fetch", keys[0]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := b.(*fsBackend).cleanup(); err != nil {
+		t.Fatalf("cleanup() error = %v", err)
+	}
+	if index, _ := os.ReadFile(filepath.Join(dir, fsIndexFile)); strings.Contains(string(index), "\t"+keys[0]+"\n") {
+		t.Errorf("index contains deleted %q:\n%s", keys[0], index)
+	}
+}
+
+func TestFSLegacyEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	// baselineEntry is the entry written by released versions, without the key
+	type baselineEntry struct {
+		Value     string
+		CreatedAt time.Time
+	}
+	// Entries named after the normalized key by older versions
+	legacy := map[string]any{
+		"npmjs.com__scope/pkg_fetch.gob":   baselineEntry{Value: "pkg", CreatedAt: time.Now()},
+		"https_/example.com/docs_html.gob": Entry[string]{Key: "https://example.com/docs", Kind: "html", Value: "docs", CreatedAt: time.Now()},
+		"example.com_old/pkg_fetch.gob":    baselineEntry{Value: "old", CreatedAt: time.Now()},
+	}
+	for name, e := range legacy {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idle := time.Now().Add(-MaxIdle - time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "example.com_old/pkg_fetch.gob"), idle, idle); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewFS(dir)
+	pages := New[string]("fetch")
+	pages.SetBackend(b)
+	docs := New[string]("html")
+	docs.SetBackend(b)
+
+	// Loaded from the legacy files and moved to the hashed paths
+	got, err := pages.GetOrSet("npmjs.com:@scope/pkg", func() (string, error) {
+		t.Error("GetOrSet() fetched the legacy entry again")
+		return "", nil
+	}, false)
+	if err != nil || got != "pkg" {
+		t.Errorf("GetOrSet() of legacy entry = %q, %v", got, err)
+	}
+	if e, ok := docs.Get("https://example.com/docs"); !ok || e.Value != "docs" {
+		t.Errorf("Get() of legacy entry = %+v, %v", e, ok)
+	}
+	for _, name := range []string{"npmjs.com__scope/pkg_fetch.gob", "https_/example.com/docs_html.gob"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("legacy file %s not moved: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "https_")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("legacy directory not removed: %v", err)
+	}
+
+	stats, err := b.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var keys []string
+	for _, st := range stats {
+		keys = append(keys, st.Kind+" "+st.Key)
+	}
+	sort.Strings(keys)
+	want := []string{"fetch npmjs.com:@scope/pkg", "html https://example.com/docs"}
+	if diff := cmp.Diff(want, keys); diff != "" {
+		t.Errorf("List() after loading mismatch (-want +got):\n%s", diff)
+	}
+
+	// Legacy files not loaded are evicted when not used within MaxIdle
+	if err := evict(b); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com_old")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("idle legacy file not evicted: %v", err)
+	}
+	if e, ok := pages.Get("npmjs.com:@scope/pkg"); !ok || e.Value != "pkg" {
+		t.Errorf("Get() after eviction = %+v, %v", e, ok)
+	}
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// boltTouchInterval is the precision of the access times of entries
+	// Access times are written only when they are older, so that reading an entry does not write to the database each time.
+	boltTouchInterval = time.Hour
+
+	// boltEntries maps "kind\x00key" to the encoded entry
+	boltEntries = []byte("entries")
+	// boltAccessed maps "kind\x00key" to the last access time in Unix nanoseconds
+	boltAccessed = []byte("accessed")
+)
+
+// boltBackend stores all entries in a single bbolt database file
+// The database is opened for each operation, since bbolt locks the file while it is open
+// and other processes such as the MCP server share it. Listing and evicting entries use a single
+// transaction, and access times are written at most once per boltTouchInterval.
+type boltBackend struct {
+	path string
+}
+
+// NewBolt returns the backend storing entries in the bbolt database file
+func NewBolt(path string) Backend {
+	return &boltBackend{path: path}
+}
+
+// boltKey returns the database key of the entry
+func boltKey(kind, key string) []byte {
+	return []byte(kind + "\x00" + key)
+}
+
+// view runs fn in a read-only transaction
+// It returns ErrNotFound if the database does not exist yet.
+func (b *boltBackend) view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(b.path); errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	db, err := bolt.Open(b.path, 0644, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+// update runs fn in a read-write transaction, creating the database and buckets
+func (b *boltBackend) update(fn func(entries, accessed *bolt.Bucket) error) error {
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	db, err := bolt.Open(b.path, 0644, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		entries, err := tx.CreateBucketIfNotExists(boltEntries)
+		if err != nil {
+			return err
+		}
+		accessed, err := tx.CreateBucketIfNotExists(boltAccessed)
+		if err != nil {
+			return err
+		}
+		return fn(entries, accessed)
+	})
+}
+
+func (b *boltBackend) Load(kind, key string) ([]byte, error) {
+	var data []byte
+	err := b.view(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(boltEntries)
+		if entries == nil {
+			return ErrNotFound
+		}
+		v := entries.Get(boltKey(kind, key))
+		if v == nil {
+			return ErrNotFound
+		}
+		// Values are only valid in the transaction
+		data = append([]byte(nil), v...)
+		return nil
+	})
+	return data, err
+}
+
+func (b *boltBackend) Store(kind, key string, data []byte) error {
+	return b.update(func(entries, accessed *bolt.Bucket) error {
+		k := boltKey(kind, key)
+		if err := entries.Put(k, data); err != nil {
+			return err
+		}
+		return accessed.Put(k, boltTime(time.Now()))
+	})
+}
+
+func (b *boltBackend) Touch(kind, key string) {
+	k := boltKey(kind, key)
+	var recent bool
+	_ = b.view(func(tx *bolt.Tx) error {
+		if accessed := tx.Bucket(boltAccessed); accessed != nil {
+			recent = time.Since(parseBoltTime(accessed.Get(k))) < boltTouchInterval
+		}
+		return nil
+	})
+	if recent {
+		return
+	}
+
+	_ = b.update(func(entries, accessed *bolt.Bucket) error {
+		if entries.Get(k) == nil {
+			return nil
+		}
+		return accessed.Put(k, boltTime(time.Now()))
+	})
+}
+
+func (b *boltBackend) Delete(kind, key string) error {
+	if _, err := os.Stat(b.path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return b.update(func(entries, accessed *bolt.Bucket) error {
+		k := boltKey(kind, key)
+		if err := entries.Delete(k); err != nil {
+			return err
+		}
+		return accessed.Delete(k)
+	})
+}
+
+// deleteAll removes the entries in a single transaction
+func (b *boltBackend) deleteAll(stats []Stat) error {
+	if _, err := os.Stat(b.path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return b.update(func(entries, accessed *bolt.Bucket) error {
+		for _, st := range stats {
+			k := boltKey(st.Kind, st.Key)
+			if err := entries.Delete(k); err != nil {
+				return err
+			}
+			if err := accessed.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltBackend) List() ([]Stat, error) {
+	var stats []Stat
+	err := b.listEntries(func(st Stat, data []byte) {
+		stats = append(stats, st)
+	})
+	return stats, err
+}
+
+// listEntries calls fn with the stat and the encoded entry of each entry in a single transaction
+// The data is only valid during the call.
+func (b *boltBackend) listEntries(fn func(st Stat, data []byte)) error {
+	err := b.view(func(tx *bolt.Tx) error {
+		entries, accessed := tx.Bucket(boltEntries), tx.Bucket(boltAccessed)
+		if entries == nil || accessed == nil {
+			return nil
+		}
+		return entries.ForEach(func(k, v []byte) error {
+			kind, key, _ := bytes.Cut(k, []byte{0})
+			fn(Stat{
+				Kind:       string(kind),
+				Key:        string(key),
+				Size:       int64(len(v)),
+				AccessedAt: parseBoltTime(accessed.Get(k)),
+			}, v)
+			return nil
+		})
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (b *boltBackend) Lock(kind, key string) func() {
+	return lockFile(filepath.Join(filepath.Dir(b.path), ".locks"), string(boltKey(kind, key))).unlock
+}
+
+// cleanup removes unused lock files
+func (b *boltBackend) cleanup() error {
+	return cleanupLocks(filepath.Join(filepath.Dir(b.path), ".locks"))
+}
+
+func (b *boltBackend) Clear() error {
+	if err := os.Remove(b.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (b *boltBackend) Location() string {
+	return b.path
+}
+
+func (b *boltBackend) stateDir() string {
+	return filepath.Dir(b.path)
+}
+
+// boltTime encodes the time as Unix nanoseconds
+func boltTime(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+// parseBoltTime decodes the time encoded by boltTime
+func parseBoltTime(b []byte) time.Time {
+	if len(b) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Export writes the entries to w as a gzip-compressed tar archive, to be imported on another machine
+// Each file of the archive is named "<kind>/<key>" and contains the encoded entry.
+// It returns the number of exported entries.
+func Export(w io.Writer, infos []Info) (int, error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	b := Default()
+	var n int
+	for _, info := range infos {
+		data, err := b.Load(info.Kind, info.Key)
+		if errors.Is(err, ErrNotFound) {
+			// Removed after listing
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		hdr := &tar.Header{
+			Name:    info.Kind + "/" + info.Key,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: info.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return n, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if err := tw.Close(); err != nil {
+		return n, err
+	}
+	return n, gw.Close()
+}
+
+// Import stores the entries of an archive written by Export in the default backend
+// Entries are skipped if the cached ones are as new or newer.
+// It returns the number of imported and skipped entries.
+func Import(r io.Reader) (imported, skipped int, err error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cache: invalid archive: %w", err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+
+	b := Default()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, skipped, fmt.Errorf("cache: invalid archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		kind, key, ok := strings.Cut(hdr.Name, "/")
+		if !ok || kind == "" || key == "" {
+			return imported, skipped, fmt.Errorf("cache: invalid archive entry %q", hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return imported, skipped, err
+		}
+
+		createdAt, err := entryCreatedAt(data)
+		if err != nil {
+			return imported, skipped, fmt.Errorf("cache: invalid archive entry %q: %w", hdr.Name, err)
+		}
+		if current, err := b.Load(kind, key); err == nil {
+			if at, err := entryCreatedAt(current); err == nil && !at.Before(createdAt) {
+				skipped++
+				continue
+			}
+		}
+
+		if err := b.Store(kind, key, data); err != nil {
+			return imported, skipped, err
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+// entryCreatedAt returns the creation time of the encoded entry
+func entryCreatedAt(data []byte) (time.Time, error) {
+	var header entryHeader
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&header); err != nil {
+		return time.Time{}, err
+	}
+	return header.CreatedAt, nil
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	useTempDir(t)
+
+	strs := New[string]("test-str")
+	for key, value := range map[string]string{
+		"example.com:@scope/widget": "widget",
+		"example.com:gadget":        "gadget",
+	} {
+		if err := strs.Set(key, value); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	infos, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := Export(&buf, infos); err != nil || n != 2 {
+		t.Fatalf("Export() = %d, %v, want 2 entries", n, err)
+	}
+
+	// Import into another cache, where the gadget is cached more recently
+	useTempDir(t)
+	time.Sleep(10 * time.Millisecond)
+	if err := strs.Set("example.com:gadget", "gadget v2"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	imported, skipped, err := Import(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if imported != 1 || skipped != 1 {
+		t.Errorf("Import() = %d imported, %d skipped, want 1 and 1", imported, skipped)
+	}
+
+	for key, want := range map[string]string{
+		"example.com:@scope/widget": "widget",
+		"example.com:gadget":        "gadget v2",
+	} {
+		e, ok := strs.Get(key)
+		if !ok || e.Value != want {
+			t.Errorf("Get(%q) = %+v, %v, want %q", key, e, ok, want)
+		}
+	}
+
+	if _, _, err := Import(bytes.NewReader([]byte("not an archive"))); err == nil {
+		t.Error("Import() of invalid archive succeeded")
+	}
+}
@@ -1,13 +1,17 @@
 package cache
 
 import (
+	"bytes"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 var (
@@ -18,23 +22,39 @@ var (
 	// DefaultTTL is the default time-to-live for cached entries
 	DefaultTTL = 24 * time.Hour
 
+	// MaxAge limits the age of entries used without being refetched if not zero
+	// Entries older than MaxAge are refetched, while entries with a shorter TTL still expire after their TTL.
+	// New entries keep the TTL of the cache.
+	MaxAge time.Duration
+
 	// DefaultDir is the default cache directory
 	DefaultDir string
 )
 
 // Entry represents a cached item
 type Entry[T any] struct {
+	Key       string // Key of the entry, to be listed and looked up
+	Kind      string
 	Value     T
 	CreatedAt time.Time
+	TTL       time.Duration // TTL in effect when the entry was created, to show when it expires
+	Schema    int           // Schema version of the value, see SetSchema
 }
 
+// CACHE_VERSION is the version of the cache layout
+// Changes of cached values are handled by the schema version of their kind instead, see SetSchema.
 const CACHE_VERSION = "v2"
 
+// fills deduplicates concurrent fills of the same entry in the process
+var fills singleflight.Group
+
 // Cache provides a generic caching mechanism
 type Cache[T any] struct {
-	kind string
-	dir  string
-	ttl  time.Duration
+	kind    string
+	schema  int
+	backend Backend
+	ttl     time.Duration
+	ttlFunc func(key string) time.Duration
 }
 
 func init() {
@@ -43,24 +63,28 @@ func init() {
 
 func prepareCacheDir() {
 	var baseDir string
-	if os.Getenv("MIRU_NO_CACHE") == "1" {
-		tmp, err := os.MkdirTemp(os.TempDir(), "miru")
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error creating temporary directory with MkdirTemp:", err)
-			tmp = filepath.Join(os.TempDir(), "miru")
-		}
-		baseDir = tmp
+	cacheHome, err := os.UserCacheDir()
+	if err != nil {
+		baseDir = filepath.Join(os.TempDir(), "miru")
 	} else {
-		cacheHome, err := os.UserCacheDir()
-		if err != nil {
-			baseDir = filepath.Join(os.TempDir(), "miru")
-		} else {
-			baseDir = filepath.Join(cacheHome, "miru")
-		}
+		baseDir = filepath.Join(cacheHome, "miru")
 	}
 
 	DefaultDir = filepath.Join(baseDir, CACHE_VERSION)
 
+	// Nothing is written when caching is disabled
+	if os.Getenv("MIRU_NO_CACHE") == "1" {
+		SetDefault(NewMemory())
+		return
+	}
+
+	backend, err := Open(os.Getenv("MIRU_CACHE_BACKEND"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error opening cache backend:", err)
+		backend = NewFS(DefaultDir)
+	}
+	SetDefault(backend)
+
 	if err := os.MkdirAll(DefaultDir, 0755); err != nil {
 		// The functionality works even if the cache is unavailable
 		fmt.Fprintln(os.Stderr, "Error creating cache directory:", err)
@@ -95,107 +119,208 @@ func cleanupOldCache(baseDir string) {
 }
 
 func New[T any](kind string) *Cache[T] {
+	registerKind[T](kind)
 	return &Cache[T]{
 		kind: kind,
-		dir:  DefaultDir,
 		ttl:  DefaultTTL,
 	}
 }
 
-// normalizeKey converts a cache key into a filesystem-safe format
-func normalizeKey(key string) string {
-	// Replace any character that's not allowed with underscore
-	normalized := strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') ||
-			(r >= 'A' && r <= 'Z') ||
-			(r >= '0' && r <= '9') ||
-			r == '-' || r == '_' || r == '.' || r == '/' {
-			return r
-		}
-		return '_'
-	}, key)
+// backendOf returns the backend of the cache, which is the default one unless set by SetBackend
+func (c *Cache[T]) backendOf() Backend {
+	if c.backend != nil {
+		return c.backend
+	}
+	return Default()
+}
+
+// GetOrSet retrieves a value from cache or stores it if it doesn't exist
+// Concurrent calls for the same key share a single call of fn, and other processes filling
+// the same entry are waited for, so that a value is fetched only once.
+func (c *Cache[T]) GetOrSet(key string, fn func() (T, error), forceUpdate bool) (T, error) {
+	value, _, err := c.GetOrSetStale(key, fn, forceUpdate)
+	return value, err
+}
 
-	// Replace consecutive dots with a single dot
-	for strings.Contains(normalized, "..") {
-		normalized = strings.ReplaceAll(normalized, "..", ".")
+// GetOrSetStale is GetOrSet that may return an expired entry, reporting whether the value is stale
+// Expired entries are returned in offline mode, in stale-while-revalidate mode while refreshing them
+// in the background, and when fn fails.
+func (c *Cache[T]) GetOrSetStale(key string, fn func() (T, error), forceUpdate bool) (T, bool, error) {
+	b := c.backendOf()
+	entry, err := c.loadEntry(b, key)
+	cached := err == nil
+
+	if offline() {
+		if !cached {
+			var zero T
+			return zero, false, ErrNotCached
+		}
+		b.Touch(c.kind, key)
+		return entry.Value, time.Since(entry.CreatedAt) >= c.freshFor(key), nil
 	}
 
-	// Replace consecutive slashes with a single slash
-	for strings.Contains(normalized, "//") {
-		normalized = strings.ReplaceAll(normalized, "//", "/")
+	// Attempt to load from cache (only if forceUpdate=false)
+	if cached && !forceUpdate {
+		// TTL check
+		if time.Since(entry.CreatedAt) < c.freshFor(key) {
+			b.Touch(c.kind, key)
+			return entry.Value, false, nil
+		}
+		if staleWhileRevalidate() {
+			b.Touch(c.kind, key)
+			c.refresh(b, key, fn)
+			return entry.Value, true, nil
+		}
 	}
 
-	return normalized
+	start := time.Now()
+	v, err, _ := fills.Do(c.kind+"\x00"+key, func() (any, error) {
+		return c.fill(b, key, fn, forceUpdate, start)
+	})
+	if err != nil {
+		if cached {
+			// Serve the expired entry rather than failing
+			b.Touch(c.kind, key)
+			return entry.Value, true, nil
+		}
+		var zero T
+		return zero, false, err
+	}
+	f := v.(filled[T])
+	return f.value, false, f.saveErr // Return the value even if cache saving fails
 }
 
-// GetOrSet retrieves a value from cache or stores it if it doesn't exist
-func (c *Cache[T]) GetOrSet(key string, fn func() (T, error), forceUpdate bool) (T, error) {
-	normalizedKey := normalizeKey(key)
-	path := filepath.Join(c.dir, normalizedKey+"_"+c.kind+".gob")
+// filled is the result of filling an entry
+type filled[T any] struct {
+	value   T
+	saveErr error
+}
 
-	// Attempt to load from cache (only if forceUpdate=false)
-	if !forceUpdate {
-		if entry, err := c.loadEntry(path); err == nil {
-			// TTL check
-			if time.Since(entry.CreatedAt) < c.ttl {
-				return entry.Value, nil
-			}
+// fill generates the value and stores it while holding the lock of the entry
+// It returns the error of fn, and the value with the error saving it.
+func (c *Cache[T]) fill(b Backend, key string, fn func() (T, error), forceUpdate bool, start time.Time) (filled[T], error) {
+	unlock := b.Lock(c.kind, key)
+	defer unlock()
+
+	// Another process may have filled the entry while waiting for the lock
+	if entry, err := c.loadEntry(b, key); err == nil {
+		fresh := time.Since(entry.CreatedAt) < c.freshFor(key)
+		if (!forceUpdate && fresh) || entry.CreatedAt.After(start) {
+			b.Touch(c.kind, key)
+			return filled[T]{value: entry.Value}, nil
 		}
 	}
 
 	// Generate value
 	value, err := fn()
 	if err != nil {
-		var zero T
-		return zero, err
+		return filled[T]{}, err
 	}
 
 	// Save to cache
 	entry := Entry[T]{
+		Key:       key,
+		Kind:      c.kind,
 		Value:     value,
 		CreatedAt: time.Now(),
+		TTL:       c.ttlOf(key),
+		Schema:    c.schema,
 	}
 
-	if err := c.saveEntry(path, entry); err != nil {
-		return value, err // Return the value even if cache saving fails
+	if err := c.saveEntry(b, entry); err != nil {
+		return filled[T]{value: value, saveErr: err}, nil
 	}
 
-	return value, nil
+	// Keep the cache within the limits, removing entries not used for a long time
+	maybeEvict(b)
+
+	return filled[T]{value: value}, nil
 }
 
-func (c *Cache[T]) loadEntry(path string) (*Entry[T], error) {
-	f, err := os.Open(path)
+// Get returns the entry of the key, even if it is expired
+// Expired entries are used to revalidate the value with its source.
+func (c *Cache[T]) Get(key string) (*Entry[T], bool) {
+	b := c.backendOf()
+	entry, err := c.loadEntry(b, key)
 	if err != nil {
+		return nil, false
+	}
+	b.Touch(c.kind, key)
+	return entry, true
+}
+
+// Set stores the value with the key
+func (c *Cache[T]) Set(key string, value T) error {
+	return c.SetWithTTL(key, value, c.ttlOf(key))
+}
+
+// SetWithTTL stores the value with the key, expiring after the TTL instead of the TTL of the cache
+func (c *Cache[T]) SetWithTTL(key string, value T, ttl time.Duration) error {
+	b := c.backendOf()
+	entry := Entry[T]{
+		Key:       key,
+		Kind:      c.kind,
+		Value:     value,
+		CreatedAt: time.Now(),
+		TTL:       ttl,
+		Schema:    c.schema,
+	}
+	if err := c.saveEntry(b, entry); err != nil {
+		return err
+	}
+	maybeEvict(b)
+	return nil
+}
+
+// Delete removes the entry of the key
+func (c *Cache[T]) Delete(key string) error {
+	return c.backendOf().Delete(c.kind, key)
+}
+
+// errSchemaMismatch is returned for entries written with another schema version of the kind
+var errSchemaMismatch = errors.New("cache: schema version mismatch")
+
+// loadEntry loads the entry of the key, which must have the schema version of the cache
+// Entries of another schema version are skipped even if their values cannot be decoded into T.
+func (c *Cache[T]) loadEntry(b Backend, key string) (*Entry[T], error) {
+	data, err := b.Load(c.kind, key)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := decodeEntry[T](data)
+	if err != nil {
+		var header entryHeader
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(&header) == nil && header.Schema != c.schema {
+			return nil, errSchemaMismatch
+		}
 		return nil, err
 	}
-	defer f.Close()
+	if entry.Schema != c.schema {
+		return nil, errSchemaMismatch
+	}
+	return entry, nil
+}
 
+func decodeEntry[T any](data []byte) (*Entry[T], error) {
 	var entry Entry[T]
-	if err := gob.NewDecoder(f).Decode(&entry); err != nil {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
 		return nil, err
 	}
-
 	return &entry, nil
 }
 
-func (c *Cache[T]) saveEntry(path string, entry Entry[T]) error {
-	// Create directory
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	f, err := os.Create(path)
-	if err != nil {
+// saveEntry encodes and stores the entry
+func (c *Cache[T]) saveEntry(b Backend, entry Entry[T]) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
 		return err
 	}
-	defer f.Close()
-
-	return gob.NewEncoder(f).Encode(entry)
+	return b.Store(c.kind, entry.Key, buf.Bytes())
 }
 
 // Clear removes all cached entries
 func (c *Cache[T]) Clear() error {
-	return os.RemoveAll(c.dir)
+	return c.backendOf().Clear()
 }
 
 // SetTTL updates the cache TTL
@@ -203,15 +328,53 @@ func (c *Cache[T]) SetTTL(d time.Duration) {
 	c.ttl = d
 }
 
-// SetDir updates the cache directory
+// SetTTLFunc sets the function returning the TTL of the entry for the key
+// The cache TTL is used when the function returns 0.
+func (c *Cache[T]) SetTTLFunc(fn func(key string) time.Duration) {
+	c.ttlFunc = fn
+}
+
+// freshFor returns how long the entry for the key is used without being refetched, which is its TTL limited by MaxAge
+func (c *Cache[T]) freshFor(key string) time.Duration {
+	ttl := c.ttlOf(key)
+	if MaxAge > 0 {
+		return min(ttl, MaxAge)
+	}
+	return ttl
+}
+
+// ttlOf returns the TTL of the entry for the key
+func (c *Cache[T]) ttlOf(key string) time.Duration {
+	if c.ttlFunc != nil {
+		if d := c.ttlFunc(key); d > 0 {
+			return d
+		}
+	}
+	return c.ttl
+}
+
+// SetSchema sets the schema version of the values
+// Bump it when the meaning or the type of the values changes, so that entries written before are ignored.
+// Entries of other kinds are kept.
+func (c *Cache[T]) SetSchema(version int) {
+	c.schema = version
+}
+
+// SetBackend sets the backend of the cache instead of the default one
+func (c *Cache[T]) SetBackend(b Backend) {
+	c.backend = b
+}
+
+// SetDir stores the entries of the cache as files in the directory
 func (c *Cache[T]) SetDir(dir string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
-	c.dir = dir
+	c.backend = NewFS(dir)
 	return nil
 }
 
+// Clear removes all entries of the default backend
 func Clear() error {
-	return os.RemoveAll(DefaultDir)
+	return Default().Clear()
 }
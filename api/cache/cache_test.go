@@ -0,0 +1,487 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// useTempDir points the default cache directory and backend to a temporary directory during the test
+func useTempDir(t *testing.T) *fsBackend {
+	t.Helper()
+	dir, backend := DefaultDir, Default()
+	DefaultDir = t.TempDir()
+	fs := NewFS(DefaultDir).(*fsBackend)
+	SetDefault(fs)
+	t.Cleanup(func() {
+		DefaultDir = dir
+		SetDefault(backend)
+	})
+	return fs
+}
+
+func TestListAndPrune(t *testing.T) {
+	useTempDir(t)
+
+	type page struct{ Title string }
+	pages := New[page]("test-page")
+	strs := New[string]("test-str")
+	for key, title := range map[string]string{
+		"example.com:@scope/widget": "widget",
+		"example.com:gadget":        "gadget",
+	} {
+		if _, err := pages.GetOrSet(key, func() (page, error) { return page{Title: title}, nil }, false); err != nil {
+			t.Fatalf("GetOrSet() error = %v", err)
+		}
+	}
+	if _, err := strs.GetOrSet("https://example.com/docs", func() (string, error) { return "<html></html>", nil }, false); err != nil {
+		t.Fatalf("GetOrSet() error = %v", err)
+	}
+
+	infos, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var got [][2]string
+	for _, info := range infos {
+		got = append(got, [2]string{info.Kind, info.Key})
+		if info.Size == 0 || info.Expired || info.Age() > time.Minute {
+			t.Errorf("List() info = %+v", info)
+		}
+	}
+	want := [][2]string{
+		{"test-page", "example.com:@scope/widget"},
+		{"test-page", "example.com:gadget"},
+		{"test-str", "https://example.com/docs"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+
+	found, err := Lookup("test-page", "example.com:@scope/widget")
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Lookup() = %v, %v", found, err)
+	}
+	value, err := Decode(found[0])
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if diff := cmp.Diff(page{Title: "widget"}, value); diff != "" {
+		t.Errorf("Decode() mismatch (-want +got):\n%s", diff)
+	}
+
+	removed, freed, err := Prune(func(info Info) bool { return info.Kind == "test-str" })
+	if err != nil || removed != 1 || freed == 0 {
+		t.Errorf("Prune() = %d, %d, %v", removed, freed, err)
+	}
+	// Directories left empty are removed
+	if _, err := os.Stat(filepath.Join(DefaultDir, "https_")); !os.IsNotExist(err) {
+		t.Errorf("Prune() left the directory of the removed entry: %v", err)
+	}
+	if infos, _ := List(); len(infos) != 2 {
+		t.Errorf("List() after Prune() = %d entries, want 2", len(infos))
+	}
+}
+
+func TestListEntryWithoutKey(t *testing.T) {
+	useTempDir(t)
+
+	// Entries written by older versions do not record the key
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(Entry[string]{Value: "old", CreatedAt: time.Now().Add(-48 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Default().Store("fetch", "example.com_old/pkg", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("List() = %v", infos)
+	}
+	if infos[0].Kind != "fetch" || infos[0].Key != "example.com_old/pkg" || !infos[0].Expired {
+		t.Errorf("List() info = %+v", infos[0])
+	}
+}
+
+func TestEvict(t *testing.T) {
+	fs := useTempDir(t)
+	t.Setenv("MIRU_CACHE_MAX_SIZE", "")
+	t.Setenv("MIRU_CACHE_MAX_ENTRIES", "3")
+
+	c := New[string]("test")
+	now := time.Now()
+	accessed := map[string]time.Time{
+		"idle":   now.Add(-8 * 24 * time.Hour), // Not used for longer than MaxIdle
+		"oldest": now.Add(-3 * time.Hour),
+		"older":  now.Add(-2 * time.Hour),
+		"recent": now.Add(-1 * time.Hour),
+		"newest": now,
+	}
+	for key, at := range accessed {
+		if _, err := c.GetOrSet(key, func() (string, error) { return key, nil }, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(fs.path("test", key), at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Using an entry makes it the most recently used
+	if _, err := c.GetOrSet("oldest", func() (string, error) { return "", nil }, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := evict(fs); err != nil {
+		t.Fatalf("evict() error = %v", err)
+	}
+
+	infos, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, info := range infos {
+		keys = append(keys, info.Key)
+	}
+	if diff := cmp.Diff([]string{"newest", "oldest", "recent"}, keys); diff != "" {
+		t.Errorf("entries after evict() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMaybeEvictShared(t *testing.T) {
+	fs := useTempDir(t)
+	t.Setenv("MIRU_CACHE_MAX_SIZE", "")
+	t.Setenv("MIRU_CACHE_MAX_ENTRIES", "1")
+
+	c := New[string]("test")
+	for _, key := range []string{"a", "b"} {
+		if err := c.Set(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func() int {
+		stats, err := fs.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(stats)
+	}
+
+	// Another process evicted recently
+	stamp := filepath.Join(fs.dir, evictStampFile)
+	if err := os.WriteFile(stamp, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	lastEvict = time.Time{}
+	maybeEvict(fs)
+	if n := count(); n != 2 {
+		t.Errorf("entries after maybeEvict() = %d, want eviction to be skipped", n)
+	}
+
+	old := time.Now().Add(-evictInterval)
+	if err := os.Chtimes(stamp, old, old); err != nil {
+		t.Fatal(err)
+	}
+	lastEvict = time.Time{}
+	maybeEvict(fs)
+	if n := count(); n != 1 {
+		t.Errorf("entries after maybeEvict() = %d, want 1", n)
+	}
+	if info, err := os.Stat(stamp); err != nil || time.Since(info.ModTime()) >= evictInterval {
+		t.Errorf("last eviction not recorded: %v", err)
+	}
+}
+
+func TestGetOrSetConcurrent(t *testing.T) {
+	fs := useTempDir(t)
+
+	c := New[string]("test")
+	var calls atomic.Int32
+	fill := func() (string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.GetOrSet("example.com:pkg", fill, false)
+			if err != nil || got != "value" {
+				t.Errorf("GetOrSet() = %q, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+	tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(fs.path("test", "example.com:pkg")), "*.tmp"))
+	if len(tmps) != 0 {
+		t.Errorf("temporary files left: %v", tmps)
+	}
+}
+
+func TestGetOrSetFilledByOtherProcess(t *testing.T) {
+	fs := useTempDir(t)
+
+	c := New[string]("test")
+
+	// Another process holds the lock while filling the entry
+	lock := lockFile(filepath.Join(DefaultDir, ".locks"), fs.path("test", "example.com:pkg"))
+	if lock == nil {
+		t.Skip("file locking is not supported")
+	}
+	done := make(chan string)
+	go func() {
+		got, _ := c.GetOrSet("example.com:pkg", func() (string, error) { return "refetched", nil }, false)
+		done <- got
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := c.saveEntry(fs, Entry[string]{Key: "example.com:pkg", Kind: "test", Value: "filled", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	lock.unlock()
+
+	if got := <-done; got != "filled" {
+		t.Errorf("GetOrSet() = %q, want the value filled by the other process", got)
+	}
+}
+
+func TestGetOrSetNested(t *testing.T) {
+	useTempDir(t)
+
+	pages, strs := New[string]("test-page"), New[string]("test-str")
+
+	// Entries shared lock files of 64 stripes, so that some of the nested fills waited for the outer one
+	done := make(chan error)
+	go func() {
+		_, err := pages.GetOrSet("example.com:outer", func() (string, error) {
+			for i := range 100 {
+				if _, err := strs.GetOrSet(fmt.Sprintf("https://example.com/%d", i), func() (string, error) { return "inner", nil }, false); err != nil {
+					return "", err
+				}
+			}
+			return "outer", nil
+		}, false)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("GetOrSet() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("nested GetOrSet() waited for the lock of the outer fill")
+	}
+}
+
+func TestCleanupLocks(t *testing.T) {
+	dir := t.TempDir()
+	held := lockFile(dir, "held")
+	if held == nil {
+		t.Skip("file locking is not supported")
+	}
+	defer held.unlock()
+	lockFile(dir, "unused").unlock()
+
+	old := time.Now().Add(-2 * tempFileTTL)
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.lock"))
+	for _, path := range paths {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cleanupLocks(dir); err != nil {
+		t.Fatalf("cleanupLocks() error = %v", err)
+	}
+
+	// Only the held lock is kept
+	if paths, _ := filepath.Glob(filepath.Join(dir, "*.lock")); len(paths) != 1 || !lockedFileExists(held.f, paths[0]) {
+		t.Errorf("lock files after cleanupLocks() = %v", paths)
+	}
+}
+
+func TestGetOrSetStale(t *testing.T) {
+	useTempDir(t)
+
+	c := New[string]("test")
+	saveExpired := func(key string) {
+		t.Helper()
+		entry := Entry[string]{Key: key, Kind: "test", Value: "expired", CreatedAt: time.Now().Add(-48 * time.Hour)}
+		if err := c.saveEntry(Default(), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	refetch := func() (string, error) { return "fresh", nil }
+	failing := func() (string, error) { return "", errors.New("network is down") }
+
+	tests := []struct {
+		name                 string
+		offline              bool
+		staleWhileRevalidate bool
+		cached               bool
+		fn                   func() (string, error)
+		want                 string
+		wantStale            bool
+		wantErr              error
+	}{
+		{name: "expired entry is refetched", cached: true, fn: refetch, want: "fresh"},
+		{name: "expired entry is used when fetching fails", cached: true, fn: failing, want: "expired", wantStale: true},
+		{name: "offline uses expired entry", offline: true, cached: true, fn: refetch, want: "expired", wantStale: true},
+		{name: "offline without entry", offline: true, fn: refetch, wantErr: ErrNotCached},
+		{name: "stale while revalidate", staleWhileRevalidate: true, cached: true, fn: refetch, want: "expired", wantStale: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Offline, StaleWhileRevalidate = tt.offline, tt.staleWhileRevalidate
+			t.Cleanup(func() { Offline, StaleWhileRevalidate = false, false })
+
+			key := fmt.Sprintf("example.com:pkg%d", i)
+			if tt.cached {
+				saveExpired(key)
+			}
+			got, stale, err := c.GetOrSetStale(key, tt.fn, false)
+			if !errors.Is(err, tt.wantErr) || got != tt.want || stale != tt.wantStale {
+				t.Errorf("GetOrSetStale() = %q, %t, %v, want %q, %t, %v", got, stale, err, tt.want, tt.wantStale, tt.wantErr)
+			}
+
+			// The entry refreshed in the background is used next time
+			Wait()
+			if tt.staleWhileRevalidate {
+				if entry, ok := c.Get(key); !ok || entry.Value != "fresh" {
+					t.Errorf("entry after refresh = %v", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	useTempDir(t)
+
+	c := New[string]("test")
+	c.SetTTLFunc(func(key string) time.Duration {
+		if key == "long:pkg" {
+			return 7 * 24 * time.Hour
+		}
+		return 0
+	})
+	for _, key := range []string{"long:pkg", "default:pkg"} {
+		if _, err := c.GetOrSet(key, func() (string, error) { return "old", nil }, false); err != nil {
+			t.Fatal(err)
+		}
+		// Created two days ago
+		entry, _ := c.Get(key)
+		entry.CreatedAt = entry.CreatedAt.Add(-48 * time.Hour)
+		if err := c.saveEntry(Default(), *entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]time.Duration{}
+	for _, info := range infos {
+		got[info.Key] = info.TTL
+		if info.Expired != (info.Key == "default:pkg") {
+			t.Errorf("List() %s expired = %t", info.Key, info.Expired)
+		}
+	}
+	if diff := cmp.Diff(map[string]time.Duration{"long:pkg": 7 * 24 * time.Hour, "default:pkg": DefaultTTL}, got); diff != "" {
+		t.Errorf("List() TTL mismatch (-want +got):\n%s", diff)
+	}
+
+	refetch := func() (string, error) { return "new", nil }
+	if got, _ := c.GetOrSet("long:pkg", refetch, false); got != "old" {
+		t.Errorf("GetOrSet() with the TTL of the key = %q, want the cached value", got)
+	}
+
+	// MaxAge limits the age of entries, but new entries keep their TTL
+	MaxAge = time.Hour
+	t.Cleanup(func() { MaxAge = 0 })
+	if got, _ := c.GetOrSet("long:pkg", refetch, false); got != "new" {
+		t.Errorf("GetOrSet() with MaxAge = %q, want the refetched value", got)
+	}
+	if entry, _ := c.Get("long:pkg"); entry.TTL != 7*24*time.Hour {
+		t.Errorf("TTL of the refetched entry = %v, want the TTL of the key", entry.TTL)
+	}
+
+	// MaxAge does not extend shorter TTLs
+	c.SetTTL(time.Minute)
+	if _, err := c.GetOrSet("short:pkg", func() (string, error) { return "old", nil }, false); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := c.Get("short:pkg")
+	entry.CreatedAt = entry.CreatedAt.Add(-2 * time.Minute)
+	if err := c.saveEntry(Default(), *entry); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.GetOrSet("short:pkg", refetch, false); got != "new" {
+		t.Errorf("GetOrSet() of expired entry with MaxAge = %q, want the refetched value", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"36h", 36 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0.5d", 12 * time.Hour, false},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDuration(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseDuration() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"4096", 4096, false},
+		{"512MB", 512 << 20, false},
+		{"1GiB", 1 << 30, false},
+		{"1.5k", 1536, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSize(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseSize() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
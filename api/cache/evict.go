@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// MaxSize is the maximum total size of cached entries in bytes, or 0 for unlimited
+	// The MIRU_CACHE_MAX_SIZE environment variable takes precedence.
+	MaxSize int64 = 256 << 20
+
+	// MaxEntries is the maximum number of cached entries, or 0 for unlimited
+	// The MIRU_CACHE_MAX_ENTRIES environment variable takes precedence.
+	MaxEntries = 10000
+
+	// MaxIdle is how long entries are kept without being used
+	// Expired entries are kept, since they can be revalidated cheaply with their source.
+	MaxIdle = 7 * 24 * time.Hour
+
+	// evictInterval is the minimum interval between evictions
+	// The time of the last eviction is shared by processes using the same directory, so that
+	// short-lived processes such as CLI runs do not evict each time. The cache may exceed the limits until then.
+	evictInterval = 10 * time.Minute
+
+	// tempFileTTL is the age after which temporary files are left by interrupted writes
+	tempFileTTL = time.Hour
+
+	evictMu   sync.Mutex
+	lastEvict time.Time
+)
+
+// limits returns the maximum size and number of entries
+func limits() (int64, int) {
+	maxSize, maxEntries := MaxSize, MaxEntries
+	if v := os.Getenv("MIRU_CACHE_MAX_SIZE"); v != "" {
+		if n, err := ParseSize(v); err == nil {
+			maxSize = n
+		}
+	}
+	if v := os.Getenv("MIRU_CACHE_MAX_ENTRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			maxEntries = n
+		}
+	}
+	return maxSize, maxEntries
+}
+
+// ParseSize parses a size such as "512MB", "1GiB" or "4096"
+// Units are powers of 1024, with or without the "i".
+func ParseSize(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(strings.TrimSuffix(v, "B"), "I")
+
+	shift := 0
+	if v != "" {
+		if i := strings.IndexByte("KMGT", v[len(v)-1]); i >= 0 {
+			shift = 10 * (i + 1)
+			v = v[:len(v)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cache: invalid size %q", s)
+	}
+	return int64(n * float64(int64(1)<<shift)), nil
+}
+
+// ParseDuration parses a duration, which also accepts days such as "7d"
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(strings.TrimSpace(s), "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("cache: invalid duration %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("cache: invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// evictStampFile is the file in the state directory of the backend whose modification time is the time of the last eviction
+const evictStampFile = "last-evict"
+
+// stater is implemented by backends storing files in a directory shared with other processes
+type stater interface {
+	// stateDir returns the directory for files recording the state of the cache
+	stateDir() string
+}
+
+// maybeEvict evicts entries unless it was done recently in this process or another one
+func maybeEvict(b Backend) {
+	evictMu.Lock()
+	if time.Since(lastEvict) < evictInterval {
+		evictMu.Unlock()
+		return
+	}
+	lastEvict = time.Now()
+	evictMu.Unlock()
+
+	if s, ok := b.(stater); ok {
+		stamp := filepath.Join(s.stateDir(), evictStampFile)
+		if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < evictInterval {
+			return
+		}
+		// Recorded before evicting, so that other processes do not evict at the same time
+		_ = writeFile(stamp, nil)
+	}
+
+	if err := evict(b); err != nil {
+		fmt.Fprintln(os.Stderr, "Error evicting cache entries:", err)
+	}
+}
+
+// cleaner is implemented by backends leaving garbage such as temporary files of interrupted writes
+type cleaner interface {
+	cleanup() error
+}
+
+// batchDeleter is implemented by backends removing several entries at once more cheaply than one by one
+type batchDeleter interface {
+	deleteAll(stats []Stat) error
+}
+
+// deleteAll removes the entries
+func deleteAll(b Backend, stats []Stat) error {
+	if d, ok := b.(batchDeleter); ok {
+		return d.deleteAll(stats)
+	}
+	for _, st := range stats {
+		if err := b.Delete(st.Kind, st.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evict removes entries not accessed within MaxIdle, and then
+// the least recently used entries until the cache fits in the limits.
+func evict(b Backend) error {
+	if c, ok := b.(cleaner); ok {
+		if err := c.cleanup(); err != nil {
+			return err
+		}
+	}
+
+	stats, err := b.List()
+	if err != nil {
+		return err
+	}
+
+	var kept, removed []Stat
+	var total int64
+	for _, st := range stats {
+		if time.Since(st.AccessedAt) >= MaxIdle {
+			removed = append(removed, st)
+			continue
+		}
+		kept = append(kept, st)
+		total += st.Size
+	}
+
+	maxSize, maxEntries := limits()
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].AccessedAt.Before(kept[j].AccessedAt)
+	})
+	count := len(kept)
+	for _, st := range kept {
+		if (maxSize <= 0 || total <= maxSize) && (maxEntries <= 0 || count <= maxEntries) {
+			break
+		}
+		removed = append(removed, st)
+		total -= st.Size
+		count--
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	return deleteAll(b, removed)
+}
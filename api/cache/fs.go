@@ -0,0 +1,377 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fsBackend stores each entry in its own file named after the hash of the key
+// Files are laid out as <kind>/<hash[:2]>/<hash>.gob, so that distinct keys never share a file
+// and long keys do not become deep directory trees. The keys of the files are recorded in
+// the human-readable index file, one "<file>\t<key>" line per entry.
+// Entries written by older versions, named after the normalized key, are moved to the hashed paths when loaded.
+type fsBackend struct {
+	dir string
+}
+
+const (
+	// fsIndexFile is the name of the index of the keys of the entry files
+	fsIndexFile = "index"
+)
+
+// entryFilePattern matches the paths of entry files relative to the directory
+var entryFilePattern = regexp.MustCompile(`^[^/]+/[0-9a-f]{2}/[0-9a-f]{64}\.gob$`)
+
+// NewFS returns the backend storing each entry as a file in the directory
+func NewFS(dir string) Backend {
+	return &fsBackend{dir: dir}
+}
+
+// path returns the file path of the entry
+func (b *fsBackend) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	return filepath.Join(b.dir, kind, hash[:2], hash+".gob")
+}
+
+// legacyPath returns the file path of the entry written by older versions
+func (b *fsBackend) legacyPath(kind, key string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(normalizeKey(key)+"_"+kind+".gob"))
+}
+
+// normalizeKey converts a cache key into the filesystem-safe format of the legacy file names
+func normalizeKey(key string) string {
+	// Replace any character that's not allowed with underscore
+	normalized := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') ||
+			r == '-' || r == '_' || r == '.' || r == '/' {
+			return r
+		}
+		return '_'
+	}, key)
+
+	// Replace consecutive dots with a single dot
+	for strings.Contains(normalized, "..") {
+		normalized = strings.ReplaceAll(normalized, "..", ".")
+	}
+
+	// Replace consecutive slashes with a single slash
+	for strings.Contains(normalized, "//") {
+		normalized = strings.ReplaceAll(normalized, "//", "/")
+	}
+
+	return normalized
+}
+
+func (b *fsBackend) Load(kind, key string) ([]byte, error) {
+	path := b.path(kind, key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b.loadLegacy(kind, key, path)
+	}
+	return data, err
+}
+
+// loadLegacy moves the entry written by older versions to the hashed path, recording the key in the index
+// The legacy file is linked rather than renamed, so that an entry stored at the path in the meantime is kept.
+func (b *fsBackend) loadLegacy(kind, key, path string) ([]byte, error) {
+	legacy := b.legacyPath(kind, key)
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return data, nil
+	}
+	if err := os.Link(legacy, path); err != nil {
+		if !errors.Is(err, fs.ErrExist) {
+			// Moved on the next load
+			return data, nil
+		}
+		if current, err := os.ReadFile(path); err == nil {
+			data = current
+		}
+	} else {
+		b.addIndex(path, key)
+	}
+	_ = removeFile(b.dir, legacy)
+	return data, nil
+}
+
+// Store writes the entry to a temporary file and renames it to the path,
+// so that readers never see a partially written entry.
+func (b *fsBackend) Store(kind, key string, data []byte) error {
+	path := b.path(kind, key)
+	_, err := os.Stat(path)
+	added := errors.Is(err, fs.ErrNotExist)
+
+	if err := writeFile(path, data); err != nil {
+		return err
+	}
+	if added {
+		b.addIndex(path, key)
+	}
+	return nil
+}
+
+// writeFile writes the file atomically
+func writeFile(path string, data []byte) error {
+	// Create directory
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // No-op after the rename
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Touch sets the modification time of the entry file, which is the last access time
+func (b *fsBackend) Touch(kind, key string) {
+	now := time.Now()
+	_ = os.Chtimes(b.path(kind, key), now, now)
+}
+
+// Delete removes the entry, and the legacy file of the key not loaded yet
+func (b *fsBackend) Delete(kind, key string) error {
+	if err := removeFile(b.dir, b.legacyPath(kind, key)); err != nil {
+		return err
+	}
+	return removeFile(b.dir, b.path(kind, key))
+}
+
+// List walks the entry files
+// The keys are taken from the index, or from the entries if they are missing in the index.
+func (b *fsBackend) List() ([]Stat, error) {
+	index := b.readIndex()
+
+	var stats []Stat
+	err := b.walkEntries(func(path, rel, kind string, info fs.FileInfo) error {
+		key, ok := index[rel]
+		if !ok {
+			if key, ok = readEntryKey(path); !ok {
+				// Removed by cleanup
+				return nil
+			}
+		}
+		stats = append(stats, Stat{
+			Kind:       kind,
+			Key:        key,
+			Size:       info.Size(),
+			AccessedAt: info.ModTime(),
+			Path:       path,
+		})
+		return nil
+	})
+	return stats, err
+}
+
+// walkEntries calls fn with the path, the slash-separated path relative to the directory and the kind of each entry file
+func (b *fsBackend) walkEntries(fn func(path, rel, kind string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(b.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".gob") {
+			return nil
+		}
+		rel, _ := filepath.Rel(b.dir, path)
+		rel = filepath.ToSlash(rel)
+		if !entryFilePattern.MatchString(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		kind, _, _ := strings.Cut(rel, "/")
+		return fn(path, rel, kind, info)
+	})
+}
+
+func (b *fsBackend) Lock(kind, key string) func() {
+	return lockFile(filepath.Join(b.dir, ".locks"), b.path(kind, key)).unlock
+}
+
+func (b *fsBackend) Clear() error {
+	return os.RemoveAll(b.dir)
+}
+
+func (b *fsBackend) Location() string {
+	return b.dir
+}
+
+func (b *fsBackend) stateDir() string {
+	return b.dir
+}
+
+// cleanup removes temporary files left by interrupted writes, legacy files not used within MaxIdle
+// and unused lock files, and compacts the index
+func (b *fsBackend) cleanup() error {
+	if err := cleanupLocks(filepath.Join(b.dir, ".locks")); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(b.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(path, ".tmp"):
+			if time.Since(info.ModTime()) >= tempFileTTL {
+				return removeFile(b.dir, path)
+			}
+		case strings.HasSuffix(path, ".gob"):
+			// Legacy files are evicted like entries, as they are not listed
+			rel, _ := filepath.Rel(b.dir, path)
+			if !entryFilePattern.MatchString(filepath.ToSlash(rel)) && time.Since(info.ModTime()) >= MaxIdle {
+				return removeFile(b.dir, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return b.compactIndex()
+}
+
+// indexLock acquires the lock of the index file
+func (b *fsBackend) indexLock() *fileLock {
+	return lockFile(filepath.Join(b.dir, ".locks"), fsIndexFile)
+}
+
+// addIndex records the key of the entry file in the index
+// Keys containing line breaks are not recorded, and are read from the entry instead.
+func (b *fsBackend) addIndex(path, key string) {
+	if strings.ContainsAny(key, "\r\n") {
+		return
+	}
+	rel, err := filepath.Rel(b.dir, path)
+	if err != nil {
+		return
+	}
+
+	defer b.indexLock().unlock()
+	f, err := os.OpenFile(filepath.Join(b.dir, fsIndexFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.WriteString(filepath.ToSlash(rel) + "\t" + key + "\n")
+}
+
+// readIndex returns the keys of the entry files by their slash-separated paths relative to the directory
+func (b *fsBackend) readIndex() map[string]string {
+	index := map[string]string{}
+	f, err := os.Open(filepath.Join(b.dir, fsIndexFile))
+	if err != nil {
+		return index
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if rel, key, ok := strings.Cut(scanner.Text(), "\t"); ok {
+			index[rel] = key
+		}
+	}
+	return index
+}
+
+// compactIndex rewrites the index with the entry files that exist
+// Entry files whose key is neither in the index nor in the entry cannot be looked up, and are removed.
+func (b *fsBackend) compactIndex() error {
+	defer b.indexLock().unlock()
+
+	index := b.readIndex()
+	var buf bytes.Buffer
+	err := b.walkEntries(func(path, rel, kind string, info fs.FileInfo) error {
+		key, ok := index[rel]
+		if !ok {
+			if key, ok = readEntryKey(path); !ok {
+				return removeFile(b.dir, path)
+			}
+		}
+		if !strings.ContainsAny(key, "\r\n") {
+			buf.WriteString(rel + "\t" + key + "\n")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if buf.Len() == 0 {
+		return removeFile(b.dir, filepath.Join(b.dir, fsIndexFile))
+	}
+	return writeFile(filepath.Join(b.dir, fsIndexFile), buf.Bytes())
+}
+
+// readEntryKey returns the key recorded in the entry file
+func readEntryKey(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	var header entryHeader
+	if err := gob.NewDecoder(f).Decode(&header); err != nil || header.Key == "" {
+		return "", false
+	}
+	return header.Key, true
+}
+
+// removeFile removes the file, and the directories under dir left empty by removing it
+func removeFile(dir, path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for d := filepath.Dir(path); d != dir && strings.HasPrefix(d, dir); d = filepath.Dir(d) {
+		if os.Remove(d) != nil {
+			// Not empty
+			break
+		}
+	}
+	return nil
+}
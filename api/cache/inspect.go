@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Info describes a cached entry
+type Info struct {
+	Kind string
+	// Key is the key of the entry
+	// Entries written by older versions do not record the key, so the key recorded by the backend is used instead.
+	Key string
+	// Path is the file of the entry, if the backend stores each entry in its own file
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+	// AccessedAt is the time the entry was last used
+	AccessedAt time.Time
+	// TTL is the TTL of the entry when it was created
+	// Entries written by older versions do not record it, so DefaultTTL is used instead.
+	TTL     time.Duration
+	Expired bool
+	// Schema is the schema version of the value
+	Schema int
+}
+
+// Age returns the time elapsed since the entry was created
+func (i Info) Age() time.Duration {
+	return time.Since(i.CreatedAt)
+}
+
+// ExpiresAt returns the time the entry expires
+func (i Info) ExpiresAt() time.Time {
+	return i.CreatedAt.Add(i.TTL)
+}
+
+// entryHeader is decoded from entries to read their metadata without knowing the value type
+type entryHeader struct {
+	Key       string
+	Kind      string
+	CreatedAt time.Time
+	TTL       time.Duration
+	Schema    int
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]func(data []byte) (any, error){}
+)
+
+// registerKind registers the value type of the kind, so that entries of the kind can be decoded by Decode
+func registerKind[T any](kind string) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[kind] = func(data []byte) (any, error) {
+		entry, err := decodeEntry[T](data)
+		if err != nil {
+			return nil, err
+		}
+		return entry.Value, nil
+	}
+}
+
+// List returns all entries in the default backend, sorted by kind and key
+func List() ([]Info, error) {
+	return list(Default(), DefaultTTL)
+}
+
+// entryLister is implemented by backends reading all entries at once more cheaply than one by one
+type entryLister interface {
+	// listEntries calls fn with the stat and the encoded entry of each entry
+	listEntries(fn func(st Stat, data []byte)) error
+}
+
+func list(b Backend, ttl time.Duration) ([]Info, error) {
+	var infos []Info
+	if l, ok := b.(entryLister); ok {
+		err := l.listEntries(func(st Stat, data []byte) {
+			infos = append(infos, readInfo(st, data, ttl))
+		})
+		if err != nil {
+			return nil, err
+		}
+		sortInfos(infos)
+		return infos, nil
+	}
+
+	stats, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+
+	infos = make([]Info, 0, len(stats))
+	for _, st := range stats {
+		data, err := b.Load(st.Kind, st.Key)
+		if err != nil {
+			data = nil
+		}
+		infos = append(infos, readInfo(st, data, ttl))
+	}
+
+	sortInfos(infos)
+	return infos, nil
+}
+
+// sortInfos sorts the entries by kind and key
+func sortInfos(infos []Info) {
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind < infos[j].Kind
+		}
+		return infos[i].Key < infos[j].Key
+	})
+}
+
+// readInfo reads the metadata of the encoded entry
+// The stat of the entry is used when the entry cannot be read or decoded.
+func readInfo(st Stat, data []byte, ttl time.Duration) Info {
+	info := Info{
+		Kind:       st.Kind,
+		Key:        st.Key,
+		Path:       st.Path,
+		Size:       st.Size,
+		CreatedAt:  st.AccessedAt,
+		AccessedAt: st.AccessedAt,
+		TTL:        ttl,
+	}
+
+	if data != nil {
+		var header entryHeader
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&header); err == nil {
+			if header.Key != "" {
+				info.Key = header.Key
+			}
+			if header.Kind != "" {
+				info.Kind = header.Kind
+			}
+			info.CreatedAt = header.CreatedAt
+			if header.TTL > 0 {
+				info.TTL = header.TTL
+			}
+			info.Schema = header.Schema
+		}
+	}
+
+	info.Expired = time.Since(info.CreatedAt) >= info.TTL
+	return info
+}
+
+// Lookup returns the entries with the key
+// If kind is empty, entries of all kinds are returned.
+func Lookup(kind, key string) ([]Info, error) {
+	infos, err := List()
+	if err != nil {
+		return nil, err
+	}
+	var found []Info
+	for _, info := range infos {
+		if info.Key == key && (kind == "" || info.Kind == kind) {
+			found = append(found, info)
+		}
+	}
+	return found, nil
+}
+
+// Decode returns the value of the entry
+// The value type of the kind must be known by creating a cache of the kind with New.
+func Decode(info Info) (any, error) {
+	decodersMu.RLock()
+	decode, ok := decoders[info.Kind]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown kind %q", info.Kind)
+	}
+	data, err := Default().Load(info.Kind, info.Key)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// Remove removes the entry
+func Remove(info Info) error {
+	return Default().Delete(info.Kind, info.Key)
+}
+
+// Prune removes the entries matching the function
+// It returns the number of removed entries and the bytes freed.
+func Prune(match func(Info) bool) (int, int64, error) {
+	infos, err := List()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var stats []Stat
+	var freed int64
+	for _, info := range infos {
+		if !match(info) {
+			continue
+		}
+		stats = append(stats, Stat{Kind: info.Kind, Key: info.Key})
+		freed += info.Size
+	}
+	if len(stats) == 0 {
+		return 0, 0, nil
+	}
+	if err := deleteAll(Default(), stats); err != nil {
+		return 0, 0, err
+	}
+	return len(stats), freed, nil
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for another process filling the same entry
+	// The entry is filled without the lock after the timeout, as the other process may be stuck.
+	lockTimeout = time.Minute
+
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// fileLock is an exclusive lock shared between processes
+type fileLock struct {
+	f *os.File
+}
+
+// lockFile acquires the lock identified by id, using a lock file of its own in lockDir
+// Locks of different ids never share a file, since file locks taken through separate descriptors
+// exclude each other even in the same process, and a fill nested in another would wait for itself.
+// It returns nil if the lock could not be acquired within the timeout or locking is not supported.
+func lockFile(lockDir, id string) *fileLock {
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(id))
+	path := filepath.Join(lockDir, hex.EncodeToString(sum[:16])+".lock")
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil
+		}
+		locked, err := tryLockFile(f)
+		if locked {
+			if lockedFileExists(f, path) {
+				return &fileLock{f: f}
+			}
+			// Removed by cleanupLocks while waiting, so lock the new file instead
+			unlockFile(f)
+			f.Close()
+			continue
+		}
+		f.Close()
+		if err != nil || time.Now().After(deadline) {
+			return nil
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// lockedFileExists reports whether the open lock file is still the file at the path
+func lockedFileExists(f *os.File, path string) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	pi, err := os.Stat(path)
+	return err == nil && os.SameFile(fi, pi)
+}
+
+// cleanupLocks removes the lock files in lockDir created more than tempFileTTL ago and not locked
+// Files are removed while locked, and lockFile retries when the file it locked has been removed.
+func cleanupLocks(lockDir string) error {
+	entries, err := os.ReadDir(lockDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".lock") {
+			continue
+		}
+		if info, err := entry.Info(); err != nil || time.Since(info.ModTime()) < tempFileTTL {
+			continue
+		}
+		path := filepath.Join(lockDir, entry.Name())
+		f, err := os.OpenFile(path, os.O_RDWR, 0644)
+		if err != nil {
+			continue
+		}
+		if locked, _ := tryLockFile(f); locked {
+			// Removing open files fails on some platforms, where the file is kept
+			_ = os.Remove(path)
+			unlockFile(f)
+		}
+		f.Close()
+	}
+	return nil
+}
+
+// unlock releases the lock
+func (l *fileLock) unlock() {
+	if l == nil {
+		return
+	}
+	unlockFile(l.f)
+	l.f.Close()
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package cache
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLockFile reports that file locking is not supported on this platform
+func tryLockFile(f *os.File) (bool, error) {
+	return false, errors.New("cache: file locking is not supported")
+}
+
+// unlockFile does nothing on this platform
+func unlockFile(f *os.File) {}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryBackend keeps entries in memory until the process exits
+type memoryBackend struct {
+	mu      sync.Mutex
+	entries map[[2]string]memoryEntry
+}
+
+type memoryEntry struct {
+	data       []byte
+	accessedAt time.Time
+}
+
+// NewMemory returns the backend keeping entries in memory
+// It is used for tests and by processes which should not leave a cache behind.
+func NewMemory() Backend {
+	return &memoryBackend{entries: map[[2]string]memoryEntry{}}
+}
+
+func (b *memoryBackend) Load(kind, key string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e, ok := b.entries[[2]string{kind, key}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return e.data, nil
+}
+
+func (b *memoryBackend) Store(kind, key string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[[2]string{kind, key}] = memoryEntry{data: data, accessedAt: time.Now()}
+	return nil
+}
+
+func (b *memoryBackend) Touch(kind, key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if e, ok := b.entries[[2]string{kind, key}]; ok {
+		e.accessedAt = time.Now()
+		b.entries[[2]string{kind, key}] = e
+	}
+}
+
+func (b *memoryBackend) Delete(kind, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, [2]string{kind, key})
+	return nil
+}
+
+func (b *memoryBackend) List() ([]Stat, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := make([]Stat, 0, len(b.entries))
+	for k, e := range b.entries {
+		stats = append(stats, Stat{Kind: k[0], Key: k[1], Size: int64(len(e.data)), AccessedAt: e.accessedAt})
+	}
+	return stats, nil
+}
+
+// Lock does nothing, since fills in the process are already deduplicated
+func (b *memoryBackend) Lock(kind, key string) func() {
+	return func() {}
+}
+
+func (b *memoryBackend) Clear() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.entries)
+	return nil
+}
+
+func (b *memoryBackend) Location() string {
+	return "memory"
+}
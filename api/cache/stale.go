@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// Offline serves values only from the cache, including expired entries
+	// The MIRU_OFFLINE environment variable also enables it.
+	Offline bool
+
+	// StaleWhileRevalidate returns expired entries immediately and refreshes them in the background
+	// The MIRU_STALE_WHILE_REVALIDATE environment variable also enables it.
+	StaleWhileRevalidate bool
+
+	// ErrNotCached is returned in offline mode when the entry is not cached
+	ErrNotCached = errors.New("cache: entry not cached in offline mode")
+
+	// refreshes tracks the background refreshes started by stale-while-revalidate
+	refreshes sync.WaitGroup
+)
+
+// offline reports whether the offline mode is enabled
+func offline() bool {
+	return Offline || os.Getenv("MIRU_OFFLINE") == "1"
+}
+
+// staleWhileRevalidate reports whether expired entries are refreshed in the background
+func staleWhileRevalidate() bool {
+	return StaleWhileRevalidate || os.Getenv("MIRU_STALE_WHILE_REVALIDATE") == "1"
+}
+
+// refresh fills the entry in the background
+func (c *Cache[T]) refresh(b Backend, key string, fn func() (T, error)) {
+	refreshes.Add(1)
+	go func() {
+		defer refreshes.Done()
+		start := time.Now()
+		_, _, _ = fills.Do(c.kind+"\x00"+key, func() (any, error) {
+			return c.fill(b, key, fn, false, start)
+		})
+	}()
+}
+
+// Wait waits for the background refreshes to finish
+// Processes exiting right after using the cache call it, so that expired entries are refreshed for the next run.
+func Wait() {
+	refreshes.Wait()
+}
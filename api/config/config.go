@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/morikuni/failure/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfig is the environment variable name for specifying the config file path
+const EnvConfig = "MIRU_CONFIG"
+
+// Config is the user configuration of miru
+type Config struct {
+	// Sources are custom documentation sources declared by the user
+	Sources []Source `yaml:"sources"`
+
+	// Cache configures the cache of fetched documentation
+	Cache Cache `yaml:"cache"`
+}
+
+// Cache configures the cache of fetched documentation
+type Cache struct {
+	// Backend is the storage of the cache: "fs" (default), "bolt" or "memory"
+	Backend string `yaml:"backend"`
+	// MaxSize is the maximum total size of the cache, such as "512MB"
+	MaxSize string `yaml:"max_size"`
+	// MaxEntries is the maximum number of cached entries
+	MaxEntries int `yaml:"max_entries"`
+	// TTL is how long entries are fresh, such as "6h" or "7d", by source type, language or cache kind
+	// The source type takes precedence over the kind ("fetch", "html"), and "default" applies to the others.
+	TTL map[string]string `yaml:"ttl"`
+	// StaleWhileRevalidate shows expired documentation immediately while refreshing it in the background
+	StaleWhileRevalidate bool `yaml:"stale_while_revalidate"`
+}
+
+// Source declares a documentation source backed by a JSON API
+// Templates may contain "{package}", which is replaced by the package path.
+type Source struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Kind    string   `yaml:"kind"`
+	Aliases []string `yaml:"aliases"`
+
+	// APIURL is the template of the JSON API URL of a package
+	APIURL string `yaml:"api_url"`
+	// Headers are sent with API requests. Environment variables in values are expanded.
+	Headers map[string]string `yaml:"headers"`
+	// BrowserURL is the template of the web page URL of a package
+	BrowserURL string `yaml:"browser_url"`
+	// URLPattern is a regular expression capturing the package path from URLs
+	URLPattern string `yaml:"url_pattern"`
+
+	Selectors Selectors `yaml:"selectors"`
+}
+
+// Selectors are JSONPath-style expressions selecting values from the API response
+// Example: $.info.homepage, $.versions[0].readme
+type Selectors struct {
+	README      string `yaml:"readme"`
+	READMEURL   string `yaml:"readme_url"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+	Homepage    string `yaml:"homepage"`
+	Repository  string `yaml:"repository"`
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *Config
+	loadErr  error
+)
+
+// Path returns the path of the config file
+// Defaults to miru/config.yaml in the user config directory.
+func Path() string {
+	if p := os.Getenv(EnvConfig); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "miru", "config.yaml")
+}
+
+// Load reads the config file once and returns it
+// A missing config file is not an error and results in an empty config.
+func Load() (*Config, error) {
+	loadOnce.Do(func() {
+		loaded, loadErr = load(Path())
+	})
+	return loaded, loadErr
+}
+
+func load(path string) (*Config, error) {
+	if path == "" {
+		return &Config{}, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return &Config{}, failure.Wrap(err)
+	}
+	cfg, err := Parse(b)
+	if err != nil {
+		return &Config{}, failure.Wrap(err, failure.Context{"path": path})
+	}
+	return cfg, nil
+}
+
+// Parse parses the YAML content of a config file
+func Parse(b []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, failure.Wrap(err)
+	}
+	return &cfg, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"strings"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -15,11 +16,19 @@ func detectInitialQuery(pkgPath string, explicitLang string) (InitialQuery, erro
 
 	// If explicit language is provided, try to resolve it
 	if explicitLang != "" {
-		if source, ok := languageAliases[explicitLang]; ok {
+		if source, ok := LookupLanguageAlias(explicitLang); ok {
 			sourceType = source
 		}
 	}
 
+	// Check for URLs of registered sources
+	if sourceType == source.TypeUnknown {
+		if t, path := investigator.FromURL(pkgPath); t != source.TypeUnknown {
+			sourceType = t
+			pkgPath = path
+		}
+	}
+
 	// Check for JavaScript package prefixes
 	if sourceType == source.TypeJSR {
 		// Append the "@" prefix if not present
@@ -46,54 +55,49 @@ func detectInitialQuery(pkgPath string, explicitLang string) (InitialQuery, erro
 			ForceUpdate: false,
 		}, nil
 	}
-	if sourceType == source.TypeNPM {
-		return InitialQuery{
-			SourceRef: source.Reference{
-				Type: source.TypeNPM,
-				Path: pkgPath,
-			},
-			ForceUpdate: false,
-		}, nil
-	}
 
-	// Check for Rust package names (from crates.io)
-	if sourceType == source.TypeCratesIO {
-		return InitialQuery{
-			SourceRef: source.Reference{
-				Type: source.TypeCratesIO,
-				Path: pkgPath,
-			},
-			ForceUpdate: false,
-		}, nil
-	}
+	// Check for Swift packages (from swiftpackageindex.com)
+	if sourceType == source.TypeSwiftPackageIndex {
+		// Swift packages are identified by their repository URL or Swift Package Index page
+		pkgPath = strings.TrimPrefix(pkgPath, "https://")
+		pkgPath = strings.TrimPrefix(pkgPath, "http://")
+		pkgPath = strings.TrimPrefix(pkgPath, "www.")
+		pkgPath = strings.TrimPrefix(pkgPath, "github.com/")
+		pkgPath = strings.TrimPrefix(pkgPath, "swiftpackageindex.com/")
+		pkgPath = strings.TrimSuffix(pkgPath, "/")
+		pkgPath = strings.TrimSuffix(pkgPath, ".git")
+
+		// Check if the package path is formatted as "<owner>/<repo>"
+		if strings.Count(pkgPath, "/") != 1 {
+			return InitialQuery{}, failure.New(
+				ErrInvalidPackagePath,
+				failure.Message("Swift package path must be formatted as '<owner>/<repo>' or 'github.com/<owner>/<repo>'"),
+				failure.Field(failure.Context{
+					"explicitLang": explicitLang,
+					"pkgPath":      pkgPath,
+				}))
+		}
 
-	// Check for Ruby package names (from rubygems.org)
-	if sourceType == source.TypeRubyGems {
 		return InitialQuery{
 			SourceRef: source.Reference{
-				Type: source.TypeRubyGems,
+				Type: source.TypeSwiftPackageIndex,
 				Path: pkgPath,
 			},
 			ForceUpdate: false,
 		}, nil
 	}
 
-	// Check for Python package names (from pypi.org)
-	if sourceType == source.TypePyPI {
-		return InitialQuery{
-			SourceRef: source.Reference{
-				Type: source.TypePyPI,
-				Path: pkgPath,
-			},
-			ForceUpdate: false,
-		}, nil
+	// Addresses of the OpenTofu registry are fetched from it even when given as Terraform addresses
+	if sourceType == source.TypeTerraform && strings.HasPrefix(pkgPath, "registry.opentofu.org/") {
+		sourceType = source.TypeOpenTofu
 	}
 
-	// Check for PHP package names (from packagist.org)
-	if sourceType == source.TypePackagist {
+	// Other registered sources use the package path as is
+	// GitHub and GitLab are handled below to detect Go modules from the repository name.
+	if sourceType != source.TypeUnknown && sourceType != source.TypeGitHub && sourceType != source.TypeGitLab {
 		return InitialQuery{
 			SourceRef: source.Reference{
-				Type: source.TypePackagist,
+				Type: sourceType,
 				Path: pkgPath,
 			},
 			ForceUpdate: false,
@@ -101,8 +105,7 @@ func detectInitialQuery(pkgPath string, explicitLang string) (InitialQuery, erro
 	}
 
 	// Check for known Go package domains
-	if sourceType == source.TypeGoPkgDev ||
-		strings.HasPrefix(pkgPath, "pkg.go.dev/") {
+	if strings.HasPrefix(pkgPath, "pkg.go.dev/") {
 		return InitialQuery{
 			SourceRef: source.Reference{
 				Type: source.TypeGoPkgDev,
@@ -164,44 +167,17 @@ func detectInitialQuery(pkgPath string, explicitLang string) (InitialQuery, erro
 
 // GetLanguageAliases returns a map of language aliases to their documentation source types
 func GetLanguageAliases() map[string]source.Type {
-	return languageAliases
+	return investigator.Aliases()
+}
+
+// LookupLanguageAlias returns the documentation source type of the language alias
+// Unlike GetLanguageAliases, source plugins are not loaded for the aliases of builtin sources.
+func LookupLanguageAlias(lang string) (source.Type, bool) {
+	return investigator.LookupAlias(lang)
 }
 
-// languageAliases maps language aliases to their canonical language name
-var languageAliases = map[string]source.Type{
-	// go
-	"go":     source.TypeGoPkgDev,
-	"golang": source.TypeGoPkgDev,
-
-	// JavaScript, TypeScript
-	"js":         source.TypeNPM,
-	"javascript": source.TypeNPM,
-	"npm":        source.TypeNPM,
-	"node":       source.TypeNPM,
-	"nodejs":     source.TypeNPM,
-	"ts":         source.TypeNPM,
-	"tsx":        source.TypeNPM,
-	"typescript": source.TypeNPM,
-	"jsr":        source.TypeJSR,
-
-	// rust
-	"rust":   source.TypeCratesIO,
-	"rs":     source.TypeCratesIO,
-	"crates": source.TypeCratesIO,
-
-	// ruby
-	"ruby": source.TypeRubyGems,
-	"rb":   source.TypeRubyGems,
-	"gem":  source.TypeRubyGems,
-
-	// python
-	"python": source.TypePyPI,
-	"py":     source.TypePyPI,
-	"pypi":   source.TypePyPI,
-	"pip":    source.TypePyPI,
-
-	// php
-	"php":       source.TypePackagist,
-	"packagist": source.TypePackagist,
-	"composer":  source.TypePackagist,
+// ResolveSourceName returns the documentation source type named by a source type or a language alias
+// Source plugins are not loaded for the names of builtin sources.
+func ResolveSourceName(name string) (source.Type, bool) {
+	return investigator.Resolve(name)
 }
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestDetectInitialQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkgPath string
+		lang    string
+		want    source.Reference
+	}{
+		{
+			name:    "go package by alias",
+			pkgPath: "golang.org/x/sync",
+			lang:    "go",
+			want:    source.Reference{Type: source.TypeGoPkgDev, Path: "golang.org/x/sync"},
+		},
+		{
+			name:    "pkg.go.dev URL",
+			pkgPath: "https://pkg.go.dev/golang.org/x/sync",
+			want:    source.Reference{Type: source.TypeGoPkgDev, Path: "golang.org/x/sync"},
+		},
+		{
+			name:    "pkg.go.dev URL with version",
+			pkgPath: "https://pkg.go.dev/golang.org/x/sync@v0.10.0",
+			want:    source.Reference{Type: source.TypeGoPkgDev, Path: "golang.org/x/sync"},
+		},
+		{
+			name:    "crates.io URL",
+			pkgPath: "https://crates.io/crates/serde/1.0.0",
+			want:    source.Reference{Type: source.TypeCratesIO, Path: "serde"},
+		},
+		{
+			name:    "Bioconductor page",
+			pkgPath: "https://bioconductor.org/packages/release/bioc/html/DESeq2.html",
+			want:    source.Reference{Type: source.TypeBioconductor, Path: "DESeq2"},
+		},
+		{
+			name:    "URL in text",
+			pkgPath: "see https://crates.io/crates/serde",
+			want:    source.Reference{Type: source.TypeUnknown, Path: "see https://crates.io/crates/serde"},
+		},
+		{
+			name:    "Swift package by repository URL",
+			pkgPath: "https://github.com/apple/swift-nio.git",
+			lang:    "swift",
+			want:    source.Reference{Type: source.TypeSwiftPackageIndex, Path: "apple/swift-nio"},
+		},
+		{
+			name:    "Swift package by Swift Package Index page",
+			pkgPath: "swiftpackageindex.com/apple/swift-nio/",
+			lang:    "swift",
+			want:    source.Reference{Type: source.TypeSwiftPackageIndex, Path: "apple/swift-nio"},
+		},
+		{
+			name:    "Swift Package Index URL",
+			pkgPath: "https://swiftpackageindex.com/apple/swift-nio",
+			want:    source.Reference{Type: source.TypeSwiftPackageIndex, Path: "apple/swift-nio"},
+		},
+		{
+			name:    "OpenTofu module by alias",
+			pkgPath: "terraform-aws-modules/vpc/aws",
+			lang:    "tofu",
+			want:    source.Reference{Type: source.TypeOpenTofu, Path: "terraform-aws-modules/vpc/aws"},
+		},
+		{
+			name:    "OpenTofu provider address",
+			pkgPath: "registry.opentofu.org/hashicorp/aws",
+			lang:    "terraform",
+			want:    source.Reference{Type: source.TypeOpenTofu, Path: "registry.opentofu.org/hashicorp/aws"},
+		},
+		{
+			name:    "Terraform provider address",
+			pkgPath: "registry.terraform.io/hashicorp/aws",
+			lang:    "tf",
+			want:    source.Reference{Type: source.TypeTerraform, Path: "registry.terraform.io/hashicorp/aws"},
+		},
+		{
+			name:    "GitHub repository",
+			pkgPath: "github.com/owner/repo",
+			want:    source.Reference{Type: source.TypeGitHub, Path: "owner/repo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectInitialQuery(tt.pkgPath, tt.lang)
+			if err != nil {
+				t.Fatalf("detectInitialQuery() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.SourceRef); diff != "" {
+				t.Errorf("detectInitialQuery() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,4 +4,5 @@ type ErrorCode string
 
 const (
 	ErrInvalidPackagePath ErrorCode = "InvalidPackagePath"
+	ErrInvalidManifest    ErrorCode = "InvalidManifest"
 )
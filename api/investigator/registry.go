@@ -0,0 +1,244 @@
+package investigator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ka2n/miru/api/source"
+)
+
+// Pattern detects references to packages of a source in documents
+// The first capture group of each pattern is the package path.
+type Pattern struct {
+	URL         *regexp.Regexp // Pattern for matching URLs
+	Command     *regexp.Regexp // Pattern for matching installation commands
+	Description string         // Description of the reference
+}
+
+// Registration declares a source type and how to investigate it
+type Registration struct {
+	Type         source.Type
+	Capabilities source.Capability
+
+	// Aliases are the language aliases selecting the source, such as "rust" for crates.io
+	Aliases []string
+
+	// Hosts are URL fragments identifying the source, such as "crates.io" or "archlinux.org/packages"
+	// Sources without hosts are identified by the URL patterns instead.
+	Hosts []string
+
+	// Patterns detect references to packages of the source in documents
+	Patterns []Pattern
+
+	// New creates the investigator of the source
+	New func() SourceInvestigator
+}
+
+var (
+	mu            sync.RWMutex
+	registrations []Registration
+	aliases       = map[string]source.Type{}
+
+	loadersMu sync.Mutex
+	loaders   []func()
+	loadOnce  sync.Once
+)
+
+// Register registers a source
+// Registrations are consulted in the order they were registered, so earlier ones take precedence
+// when a URL matches several sources. Aliases that are already taken are ignored.
+// It returns an error if the source type is already registered.
+func Register(r Registration) error {
+	if r.Type == source.TypeUnknown || r.New == nil {
+		return fmt.Errorf("investigator: registration requires Type and New")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, existing := range registrations {
+		if existing.Type == r.Type {
+			return fmt.Errorf("investigator: source type %q is already registered", r.Type)
+		}
+	}
+	registrations = append(registrations, r)
+	for _, alias := range r.Aliases {
+		if _, ok := aliases[alias]; !ok {
+			aliases[alias] = r.Type
+		}
+	}
+
+	d := source.Definition{
+		Type:         r.Type,
+		Capabilities: r.Capabilities,
+		Hosts:        r.Hosts,
+	}
+	if len(r.Hosts) == 0 {
+		for _, p := range r.Patterns {
+			if p.URL != nil {
+				d.URLPatterns = append(d.URLPatterns, p.URL)
+			}
+		}
+	}
+	source.Define(d)
+	return nil
+}
+
+// MustRegister is like Register but panics if the source cannot be registered
+func MustRegister(r Registration) {
+	if err := Register(r); err != nil {
+		panic(err)
+	}
+}
+
+func init() {
+	// Source types of lazily registered sources are defined before they are first detected
+	source.SetLoader(load)
+}
+
+// RegisterLoader registers a function that registers sources lazily, such as by discovering plugins
+// Loaders run once, in the order they were registered, when a source is not found among the sources
+// registered so far or all of them are listed, so that builtin sources are looked up without running loaders.
+// Loaders must not consult the registry themselves.
+func RegisterLoader(fn func()) {
+	loadersMu.Lock()
+	defer loadersMu.Unlock()
+	loaders = append(loaders, fn)
+}
+
+// load runs the registered loaders once
+func load() {
+	loadOnce.Do(func() {
+		loadersMu.Lock()
+		fns := loaders
+		loadersMu.Unlock()
+		for _, fn := range fns {
+			fn()
+		}
+	})
+}
+
+// Registrations returns all registered sources in the order of precedence
+func Registrations() []Registration {
+	load()
+	return registered()
+}
+
+// registered returns the sources registered so far, without running the loaders
+func registered() []Registration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Registration(nil), registrations...)
+}
+
+// Lookup returns the registration of the source type
+// The loaders run only if the source type is not registered yet.
+func Lookup(t source.Type) (Registration, bool) {
+	if r, ok := lookup(t); ok {
+		return r, true
+	}
+	load()
+	return lookup(t)
+}
+
+// lookup returns the registration of the source type among the sources registered so far
+func lookup(t source.Type) (Registration, bool) {
+	for _, r := range registered() {
+		if r.Type == t {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+// New creates the investigator for the source type, or returns nil if the type is not registered
+func New(t source.Type) SourceInvestigator {
+	if r, ok := Lookup(t); ok {
+		return r.New()
+	}
+	return nil
+}
+
+// Aliases returns a map of language aliases to their source types
+func Aliases() map[string]source.Type {
+	load()
+	mu.RLock()
+	defer mu.RUnlock()
+	m := make(map[string]source.Type, len(aliases))
+	for alias, t := range aliases {
+		m[alias] = t
+	}
+	return m
+}
+
+// LookupAlias returns the source type of the language alias
+// The loaders run only if the alias is not registered yet.
+func LookupAlias(alias string) (source.Type, bool) {
+	if t, ok := lookupAlias(alias); ok {
+		return t, true
+	}
+	load()
+	return lookupAlias(alias)
+}
+
+// Resolve returns the source type named by a source type or a language alias
+// The loaders run only if the name is neither registered yet.
+func Resolve(name string) (source.Type, bool) {
+	if t, ok := resolve(name); ok {
+		return t, true
+	}
+	load()
+	return resolve(name)
+}
+
+// resolve returns the source type named by the name among the sources registered so far
+func resolve(name string) (source.Type, bool) {
+	if t, ok := lookupAlias(name); ok {
+		return t, true
+	}
+	if r, ok := lookup(source.Type(name)); ok {
+		return r.Type, true
+	}
+	return source.TypeUnknown, false
+}
+
+// lookupAlias returns the source type of the alias among the sources registered so far
+func lookupAlias(alias string) (source.Type, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := aliases[alias]
+	return t, ok
+}
+
+// FromURL returns the source type whose URL pattern matches the URL and the package path captured from it
+// Unlike in documents, the pattern must match from the start of the URL, and the package path must be followed by
+// the end of the URL or by a path, query, fragment or version, so that a pattern capturing part of the package path
+// does not match.
+// The loaders run only if no source registered so far matches.
+func FromURL(url string) (source.Type, string) {
+	if t, path := fromURL(registered(), url); t != source.TypeUnknown {
+		return t, path
+	}
+	load()
+	return fromURL(registered(), url)
+}
+
+// fromURL returns the first of the registrations matching the URL and the package path captured from it
+func fromURL(registrations []Registration, url string) (source.Type, string) {
+	for _, r := range registrations {
+		for _, p := range r.Patterns {
+			if p.URL == nil {
+				continue
+			}
+			loc := p.URL.FindStringSubmatchIndex(url)
+			if len(loc) < 4 || loc[0] != 0 || loc[2] < 0 {
+				continue
+			}
+			if rest := url[loc[3]:]; loc[1] == len(url) || rest == "" || strings.ContainsAny(rest[:1], "/?#@") {
+				return r.Type, url[loc[2]:loc[3]]
+			}
+		}
+	}
+	return source.TypeUnknown, ""
+}
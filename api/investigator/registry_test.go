@@ -0,0 +1,103 @@
+package investigator
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestLoaderBeforeSourceDetection(t *testing.T) {
+	const sourceType = source.Type("loaded.test.example")
+	RegisterLoader(func() {
+		MustRegister(Registration{
+			Type:         sourceType,
+			Capabilities: source.CapabilityRegistry,
+			Hosts:        []string{"loaded.test.example"},
+			New:          func() SourceInvestigator { return nil },
+		})
+	})
+
+	// The source package is consulted before the registry
+	if got := source.DetectSourceTypeFromURL("https://loaded.test.example/widget"); got != sourceType {
+		t.Errorf("DetectSourceTypeFromURL() = %q, want %q", got, sourceType)
+	}
+	if !sourceType.IsRegistry() {
+		t.Error("IsRegistry() = false, want true")
+	}
+	if _, ok := Lookup(sourceType); !ok {
+		t.Error("Lookup() did not find the loaded source")
+	}
+}
+
+func TestLoadersRunOnMiss(t *testing.T) {
+	resetRegistry(t)
+	const builtinType, pluginType = source.Type("builtin.test.example"), source.Type("plugin.test.example")
+	MustRegister(Registration{
+		Type:     builtinType,
+		Aliases:  []string{"builtin"},
+		Patterns: []Pattern{{URL: regexp.MustCompile(`https://builtin\.test\.example/([^/\s]+)`)}},
+		New:      func() SourceInvestigator { return nil },
+	})
+	loaded := 0
+	RegisterLoader(func() {
+		loaded++
+		MustRegister(Registration{
+			Type:    pluginType,
+			Aliases: []string{"plugin"},
+			New:     func() SourceInvestigator { return nil },
+		})
+	})
+
+	// Builtin sources are found without running the loaders
+	if _, ok := Lookup(builtinType); !ok {
+		t.Error("Lookup() did not find the builtin source")
+	}
+	if got, ok := LookupAlias("builtin"); !ok || got != builtinType {
+		t.Errorf("LookupAlias() = %q, %v, want %q", got, ok, builtinType)
+	}
+	if got, path := FromURL("https://builtin.test.example/widget"); got != builtinType || path != "widget" {
+		t.Errorf("FromURL() = %q, %q", got, path)
+	}
+	if loaded != 0 {
+		t.Errorf("loaders ran %d times for builtin sources", loaded)
+	}
+
+	// The loaders run once on a miss
+	if got, ok := LookupAlias("plugin"); !ok || got != pluginType {
+		t.Errorf("LookupAlias() = %q, %v, want %q", got, ok, pluginType)
+	}
+	if _, ok := Lookup("missing.test.example"); ok {
+		t.Error("Lookup() found a missing source")
+	}
+	if loaded != 1 {
+		t.Errorf("loaders ran %d times, want 1", loaded)
+	}
+}
+
+// resetRegistry empties the registry for the test, and restores it afterwards
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	savedRegistrations, savedAliases := registrations, aliases
+	registrations, aliases = nil, map[string]source.Type{}
+	mu.Unlock()
+	loadersMu.Lock()
+	savedLoaders := loaders
+	loaders = nil
+	loadersMu.Unlock()
+	loadOnce = sync.Once{}
+
+	t.Cleanup(func() {
+		mu.Lock()
+		registrations, aliases = savedRegistrations, savedAliases
+		mu.Unlock()
+		loadersMu.Lock()
+		loaders = savedLoaders
+		loadersMu.Unlock()
+		// Loaders registered before the test are not run again
+		loadOnce = sync.Once{}
+		loadOnce.Do(func() {})
+	})
+}
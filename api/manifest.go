@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/morikuni/failure/v2"
+	"golang.org/x/mod/modfile"
+)
+
+var (
+	// requirementNamePattern matches the package name of a PEP 508 requirement
+	requirementNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+	// gemPattern matches gem declarations of a Gemfile
+	gemPattern = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["']`)
+)
+
+// ParseManifest returns the packages declared in a dependency manifest of a project
+// package.json, go.mod, requirements.txt, pyproject.toml, Cargo.toml, composer.json and Gemfile are supported.
+// Other files are read as a list of "<lang> <package>" lines, where lines starting with "#" are comments.
+func ParseManifest(path string) ([]UserInput, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	inputs, err := parseManifest(filepath.Base(path), b)
+	if err != nil {
+		return nil, failure.Wrap(err, failure.Context{"path": path})
+	}
+	return inputs, nil
+}
+
+func parseManifest(name string, b []byte) ([]UserInput, error) {
+	var language string
+	var names []string
+	var err error
+	switch {
+	case name == "package.json":
+		language = "npm"
+		names, err = parsePackageJSON(b)
+	case name == "go.mod":
+		language = "go"
+		names, err = parseGoMod(b)
+	case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+		language = "pypi"
+		names = parseRequirements(b)
+	case name == "pyproject.toml":
+		language = "pypi"
+		names, err = parsePyproject(b)
+	case name == "Cargo.toml":
+		language = "rust"
+		names, err = parseCargoToml(b)
+	case name == "composer.json":
+		language = "composer"
+		names, err = parseComposerJSON(b)
+	case name == "Gemfile":
+		language = "ruby"
+		names = parseGemfile(b)
+	default:
+		return parsePackageList(b)
+	}
+	if err != nil {
+		return nil, failure.Wrap(err, failure.WithCode(ErrInvalidManifest))
+	}
+
+	sort.Strings(names)
+	inputs := make([]UserInput, 0, len(names))
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		inputs = append(inputs, UserInput{PackagePath: name, Language: language})
+	}
+	return inputs, nil
+}
+
+// parsePackageJSON returns the dependencies of package.json
+func parsePackageJSON(b []byte) ([]string, error) {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		for name := range deps {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// parseGoMod returns the modules required directly by go.mod
+func parseGoMod(b []byte) ([]string, error) {
+	f, err := modfile.ParseLax("go.mod", b, nil)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range f.Require {
+		if !r.Indirect {
+			names = append(names, r.Mod.Path)
+		}
+	}
+	return names, nil
+}
+
+// parseRequirements returns the packages of a pip requirements file
+// Options, editable installs and URLs are skipped.
+func parseRequirements(b []byte) []string {
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if name := requirementNamePattern.FindString(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parsePyproject returns the dependencies of pyproject.toml, declared by PEP 621 or Poetry
+func parsePyproject(b []byte) ([]string, error) {
+	var pyproject struct {
+		Project struct {
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(b, &pyproject); err != nil {
+		return nil, err
+	}
+
+	requirements := pyproject.Project.Dependencies
+	for _, deps := range pyproject.Project.OptionalDependencies {
+		requirements = append(requirements, deps...)
+	}
+	var names []string
+	for _, r := range requirements {
+		if name := requirementNamePattern.FindString(strings.TrimSpace(r)); name != "" {
+			names = append(names, name)
+		}
+	}
+	for name := range pyproject.Tool.Poetry.Dependencies {
+		if name != "python" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// parseCargoToml returns the dependencies of Cargo.toml
+// Workspace and platform specific dependencies are included, and renamed dependencies are resolved to the crate name.
+func parseCargoToml(b []byte) ([]string, error) {
+	type dependencies struct {
+		Dependencies      map[string]any `toml:"dependencies"`
+		DevDependencies   map[string]any `toml:"dev-dependencies"`
+		BuildDependencies map[string]any `toml:"build-dependencies"`
+	}
+	var manifest struct {
+		dependencies
+		Workspace struct {
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"workspace"`
+		Target map[string]dependencies `toml:"target"`
+	}
+	if err := toml.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+
+	tables := []map[string]any{manifest.Dependencies, manifest.DevDependencies, manifest.BuildDependencies, manifest.Workspace.Dependencies}
+	for _, target := range manifest.Target {
+		tables = append(tables, target.Dependencies, target.DevDependencies, target.BuildDependencies)
+	}
+	var names []string
+	for _, deps := range tables {
+		for name, spec := range deps {
+			if table, ok := spec.(map[string]any); ok {
+				if pkg, ok := table["package"].(string); ok {
+					name = pkg
+				} else if table["workspace"] == true {
+					// inherited from [workspace.dependencies], which may rename it
+					if ws, ok := manifest.Workspace.Dependencies[name].(map[string]any); ok {
+						if pkg, ok := ws["package"].(string); ok {
+							name = pkg
+						}
+					}
+				}
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// parseComposerJSON returns the packages required by composer.json
+// PHP itself and extensions are skipped.
+func parseComposerJSON(b []byte) ([]string, error) {
+	var composer struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(b, &composer); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, deps := range []map[string]string{composer.Require, composer.RequireDev} {
+		for name := range deps {
+			if strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// parseGemfile returns the gems declared in a Gemfile
+func parseGemfile(b []byte) []string {
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		if m := gemPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// parsePackageList parses "<lang> <package>" lines
+func parsePackageList(b []byte) ([]UserInput, error) {
+	var inputs []UserInput
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, failure.New(ErrInvalidManifest,
+				failure.Message("Package list lines must be formatted as '<lang> <package>'"),
+				failure.Context{"line": strconv.Itoa(line), "text": text},
+			)
+		}
+		inputs = append(inputs, UserInput{Language: fields[0], PackagePath: fields[1]})
+	}
+	return inputs, nil
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/morikuni/failure/v2"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []UserInput
+		wantErr bool
+	}{
+		{
+			name: "package.json",
+			file: "package.json",
+			content: `{
+  "dependencies": {"react": "^19.0.0", "@scope/pkg": "1.0.0"},
+  "devDependencies": {"typescript": "^5.0.0"},
+  "peerDependencies": {"react": "*"},
+  "optionalDependencies": {"fsevents": "*"}
+}`,
+			want: []UserInput{
+				{PackagePath: "@scope/pkg", Language: "npm"},
+				{PackagePath: "fsevents", Language: "npm"},
+				{PackagePath: "react", Language: "npm"},
+				{PackagePath: "typescript", Language: "npm"},
+			},
+		},
+		{
+			name: "go.mod skips indirect requirements",
+			file: "go.mod",
+			content: `module example.com/m
+
+go 1.24
+
+require (
+	github.com/google/go-cmp v0.7.0
+	golang.org/x/mod v0.24.0 // indirect
+)
+
+require github.com/morikuni/failure/v2 v2.0.0
+`,
+			want: []UserInput{
+				{PackagePath: "github.com/google/go-cmp", Language: "go"},
+				{PackagePath: "github.com/morikuni/failure/v2", Language: "go"},
+			},
+		},
+		{
+			name: "requirements.txt skips options and URLs",
+			file: "requirements-dev.txt",
+			content: `# comment
+-r requirements.txt
+requests>=2.0  # http
+Django[argon2]==5.0
+-e .
+git+https://github.com/example/pkg.git
+typing_extensions ; python_version < "3.11"
+`,
+			want: []UserInput{
+				{PackagePath: "Django", Language: "pypi"},
+				{PackagePath: "requests", Language: "pypi"},
+				{PackagePath: "typing_extensions", Language: "pypi"},
+			},
+		},
+		{
+			name: "pyproject.toml",
+			file: "pyproject.toml",
+			content: `[project]
+dependencies = ["httpx>=0.27", "rich"]
+
+[project.optional-dependencies]
+test = ["pytest"]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+click = "^8.0"
+`,
+			want: []UserInput{
+				{PackagePath: "click", Language: "pypi"},
+				{PackagePath: "httpx", Language: "pypi"},
+				{PackagePath: "pytest", Language: "pypi"},
+				{PackagePath: "rich", Language: "pypi"},
+			},
+		},
+		{
+			name: "Cargo.toml",
+			file: "Cargo.toml",
+			content: `[package]
+name = "app"
+
+[dependencies]
+serde = "1"
+json = { package = "serde_json", version = "1" }
+tokio = { workspace = true }
+rt = { workspace = true }
+
+[dev-dependencies]
+insta = "1"
+
+[build-dependencies]
+cc = "1"
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[target.'cfg(windows)'.dev-dependencies]
+windows-sys = "0.59"
+
+[workspace.dependencies]
+tokio = { version = "1", features = ["full"] }
+rt = { package = "tokio-util", version = "0.7" }
+anyhow = "1"
+`,
+			want: []UserInput{
+				{PackagePath: "anyhow", Language: "rust"},
+				{PackagePath: "cc", Language: "rust"},
+				{PackagePath: "insta", Language: "rust"},
+				{PackagePath: "libc", Language: "rust"},
+				{PackagePath: "serde", Language: "rust"},
+				{PackagePath: "serde_json", Language: "rust"},
+				{PackagePath: "tokio", Language: "rust"},
+				{PackagePath: "tokio-util", Language: "rust"},
+				{PackagePath: "windows-sys", Language: "rust"},
+			},
+		},
+		{
+			name: "composer.json skips PHP and extensions",
+			file: "composer.json",
+			content: `{
+  "require": {"php": ">=8.2", "ext-json": "*", "laravel/framework": "^11.0"},
+  "require-dev": {"phpunit/phpunit": "^11.0"}
+}`,
+			want: []UserInput{
+				{PackagePath: "laravel/framework", Language: "composer"},
+				{PackagePath: "phpunit/phpunit", Language: "composer"},
+			},
+		},
+		{
+			name: "Gemfile",
+			file: "Gemfile",
+			content: `source "https://rubygems.org"
+
+gem "rails", "~> 8.0"
+  gem 'puma'
+group :test do
+  gem "rspec"
+end
+`,
+			want: []UserInput{
+				{PackagePath: "puma", Language: "ruby"},
+				{PackagePath: "rails", Language: "ruby"},
+				{PackagePath: "rspec", Language: "ruby"},
+			},
+		},
+		{
+			name: "package list",
+			file: "packages.txt",
+			content: `# tools
+go golang.org/x/sync
+
+npm react
+`,
+			want: []UserInput{
+				{PackagePath: "golang.org/x/sync", Language: "go"},
+				{PackagePath: "react", Language: "npm"},
+			},
+		},
+		{
+			name:    "malformed package list line",
+			file:    "packages.txt",
+			content: "go golang.org/x/sync extra\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid package.json",
+			file:    "package.json",
+			content: "{",
+			wantErr: true,
+		},
+		{
+			name:    "invalid Cargo.toml",
+			file:    "Cargo.toml",
+			content: "[dependencies\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifest(tt.file, []byte(tt.content))
+			if tt.wantErr {
+				if !failure.Is(err, ErrInvalidManifest) {
+					t.Fatalf("parseManifest() error = %v, want %s", err, ErrInvalidManifest)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifest() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseManifest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -13,6 +13,10 @@ type Result struct {
 	InitialQueryURL  *url.URL
 	InitialQueryType source.Type
 	Links            []Link
+
+	// Stale reports whether the result contains expired cached data
+	// See source.Data.Stale.
+	Stale bool
 }
 
 type Link struct {
@@ -35,6 +39,9 @@ func CreateResult(inv *Investigation) Result {
 		if len(readme) > len(result.README) {
 			result.README = readme
 		}
+		if data.Stale {
+			result.Stale = true
+		}
 
 		result.Links = append(result.Links, Link{
 			Type: data.Source.Type,
@@ -22,6 +22,9 @@ type Data struct {
 	// FetchedAt is the time when the data was retrieved
 	FetchedAt time.Time
 
+	// Stale reports whether the data is an expired cache entry, which could not be or is not yet refreshed
+	Stale bool
+
 	// RelatedSources are sources related to this data
 	RelatedSources []RelatedReference
 }
@@ -1,27 +1,24 @@
 package source
 
-import "strings"
-
-// detectSourceTypeFromURL detects the source type from a URL
+// DetectSourceTypeFromURL detects the source type from a URL
+// The first registered source type matching the URL wins.
+// The loader runs only if no source type defined so far matches.
 func DetectSourceTypeFromURL(url string) Type {
-	switch {
-	case strings.Contains(url, "github.com"):
-		return TypeGitHub
-	case strings.Contains(url, "gitlab.com"):
-		return TypeGitLab
-	case strings.Contains(url, "rubygems.org"):
-		return TypeRubyGems
-	case strings.Contains(url, "npmjs.com"):
-		return TypeNPM
-	case strings.Contains(url, "jsr.io"):
-		return TypeJSR
-	case strings.Contains(url, "pkg.go.dev"):
-		return TypeGoPkgDev
-	case strings.Contains(url, "crates.io"):
-		return TypeCratesIO
-	case strings.Contains(url, "packagist.org"):
-		return TypePackagist
-	default:
-		return TypeUnknown
+	if t := detect(url); t != TypeUnknown {
+		return t
+	}
+	load()
+	return detect(url)
+}
+
+// detect returns the first source type defined so far matching the URL
+func detect(url string) Type {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	for _, d := range definitions {
+		if d.matchURL(url) {
+			return d.Type
+		}
 	}
+	return TypeUnknown
 }
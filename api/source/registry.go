@@ -0,0 +1,115 @@
+package source
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Capability represents what a source type provides
+type Capability uint8
+
+const (
+	// CapabilityRegistry marks a package registry
+	CapabilityRegistry Capability = 1 << iota
+	// CapabilityRepository marks a code repository
+	CapabilityRepository
+	// CapabilityDocumentation marks a documentation site
+	CapabilityDocumentation
+	// CapabilityRepositoryURL marks a source whose package paths contain the repository URL
+	CapabilityRepositoryURL
+)
+
+// Definition describes a source type
+type Definition struct {
+	Type         Type
+	Capabilities Capability
+
+	// Hosts are URL fragments identifying the source, such as "crates.io" or "archlinux.org/packages"
+	Hosts []string
+
+	// URLPatterns identify the source from URLs when it has no fixed host
+	URLPatterns []*regexp.Regexp
+}
+
+var (
+	definitionsMu sync.RWMutex
+	definitions   []Definition
+
+	loaderMu sync.Mutex
+	loader   func()
+	loadOnce sync.Once
+)
+
+// SetLoader sets the function that defines source types lazily, such as by discovering plugins
+// It runs once when a source type is not found among the definitions so far, so that the detected types do
+// not depend on whether the investigator registry was consulted first. The loader must not consult the definitions itself.
+func SetLoader(fn func()) {
+	loaderMu.Lock()
+	defer loaderMu.Unlock()
+	loader = fn
+}
+
+// load runs the loader once
+func load() {
+	loadOnce.Do(func() {
+		loaderMu.Lock()
+		fn := loader
+		loaderMu.Unlock()
+		if fn != nil {
+			fn()
+		}
+	})
+}
+
+// Define registers a source type
+// Definitions are consulted in the order they were registered, so earlier ones take precedence.
+// Use investigator.Register to register a source type together with its investigator.
+func Define(d Definition) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	definitions = append(definitions, d)
+}
+
+// Lookup returns the definition of the source type
+// The loader runs only if the source type is not defined yet.
+func Lookup(t Type) (Definition, bool) {
+	if d, ok := lookup(t); ok {
+		return d, true
+	}
+	load()
+	return lookup(t)
+}
+
+// lookup returns the definition of the source type among the definitions so far
+func lookup(t Type) (Definition, bool) {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+	for _, d := range definitions {
+		if d.Type == t {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// has returns true if the source type is defined with the capability
+func (s Type) has(c Capability) bool {
+	d, ok := Lookup(s)
+	return ok && d.Capabilities&c != 0
+}
+
+// matchURL returns true if the URL belongs to the source
+func (d Definition) matchURL(url string) bool {
+	for _, host := range d.Hosts {
+		if strings.Contains(url, host) {
+			return true
+		}
+	}
+	for _, p := range d.URLPatterns {
+		if p.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
@@ -18,54 +18,52 @@ func SourceTypeFromString(s string) Type {
 
 // IsRegistry returns true if the source type is a package registry
 func (s Type) IsRegistry() bool {
-	switch s {
-	case TypeGoPkgDev, TypeJSR, TypeNPM, TypeCratesIO, TypeRubyGems, TypePyPI, TypePackagist:
-		return true
-	default:
-		return false
-	}
+	return s.has(CapabilityRegistry)
 }
 
 // IsRepository returns true if the source type is a code repository
 func (s Type) IsRepository() bool {
-	switch s {
-	case TypeGitHub, TypeGitLab:
-		return true
-	default:
-		return false
-	}
+	return s.has(CapabilityRepository)
 }
 
+// IsDocumentation returns true if the source type is a documentation site
 func (s Type) IsDocumentation() bool {
-	switch s {
-	case TypeGoPkgDev, TypeJSR:
-		return true
-	default:
-		return false
-	}
+	return s.has(CapabilityDocumentation)
 }
 
+// ContainRepositoryURL returns true if package paths of the source type contain the repository URL
 func (s Type) ContainRepositoryURL() bool {
-	switch s {
-	case TypeGitHub, TypeGitLab, TypeGoPkgDev:
-		return true
-	default:
-		return false
-	}
+	return s.has(CapabilityRepositoryURL)
 }
 
 const (
 	// Documentation source types
-	TypeGoPkgDev      Type = "pkg.go.dev"
-	TypeJSR           Type = "jsr.io"
-	TypeNPM           Type = "npmjs.com"
-	TypeCratesIO      Type = "crates.io"
-	TypeRubyGems      Type = "rubygems.org"
-	TypePyPI          Type = "pypi.org"
-	TypePackagist     Type = "packagist.org"
-	TypeGitHub        Type = "github.com"
-	TypeGitLab        Type = "gitlab.com"
-	TypeDocumentation Type = "documentation"
-	TypeHomepage      Type = "homepage"
-	TypeUnknown       Type = ""
+	TypeGoPkgDev          Type = "pkg.go.dev"
+	TypeJSR               Type = "jsr.io"
+	TypeNPM               Type = "npmjs.com"
+	TypeCratesIO          Type = "crates.io"
+	TypeRubyGems          Type = "rubygems.org"
+	TypePyPI              Type = "pypi.org"
+	TypePackagist         Type = "packagist.org"
+	TypeSwiftPackageIndex Type = "swiftpackageindex.com"
+	TypeCocoaPods         Type = "cocoapods.org"
+	TypeHackage           Type = "hackage.haskell.org"
+	TypeOpam              Type = "opam.ocaml.org"
+	TypeCRAN              Type = "cran.r-project.org"
+	TypeBioconductor      Type = "bioconductor.org"
+	TypeDockerHub         Type = "hub.docker.com"
+	TypeHelm              Type = "artifacthub.io"
+	TypeTerraform         Type = "registry.terraform.io"
+	TypeOpenTofu          Type = "registry.opentofu.org"
+	TypeHomebrew          Type = "formulae.brew.sh"
+	TypeAlpine            Type = "pkgs.alpinelinux.org"
+	TypeDebian            Type = "packages.debian.org"
+	TypeArch              Type = "archlinux.org"
+	TypeCondaForge        Type = "anaconda.org"
+	TypeOpenVSX           Type = "open-vsx.org"
+	TypeGitHub            Type = "github.com"
+	TypeGitLab            Type = "gitlab.com"
+	TypeDocumentation     Type = "documentation"
+	TypeHomepage          Type = "homepage"
+	TypeUnknown           Type = ""
 )
@@ -0,0 +1,177 @@
+package sourceimpl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// alpineReleases are the branches searched by default, oldest first
+var alpineReleases = []string{"latest-stable", "edge"}
+
+// alpineRepositories are the repositories searched in each branch
+var alpineRepositories = []string{"main", "community"}
+
+// findAlpinePackage finds the package in an APKINDEX.tar.gz archive
+// APKINDEX uses single letter DCF fields: P (name), V (version), T (description), U (url), L (license) and D (dependencies).
+func findAlpinePackage(r io.Reader, name string) (map[string]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		if hdr.Name != "APKINDEX" {
+			continue
+		}
+
+		var found map[string]string
+		err = scanDCF(tr, func(fields map[string]string) bool {
+			if fields["P"] == name {
+				found = fields
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		return found, nil
+	}
+}
+
+// fetchAlpine fetches the package information from the APKINDEX of each Alpine branch
+// Returns the content, related sources, and any error
+func fetchAlpine(pkgPath string) (string, []source.RelatedReference, error) {
+	mirror := distroMirror("MIRU_ALPINE_MIRROR", "https://dl-cdn.alpinelinux.org/alpine")
+
+	var pkgs []distroPackage
+	for _, release := range distroReleases("MIRU_ALPINE_RELEASES", alpineReleases) {
+		for _, repo := range alpineRepositories {
+			f, err := openMirrorFile(mirror, fmt.Sprintf("%s/%s/x86_64/APKINDEX.tar.gz", release, repo))
+			if err != nil {
+				return "", nil, err
+			}
+			if f == nil {
+				continue
+			}
+			fields, err := findAlpinePackage(f, pkgPath)
+			f.Close()
+			if err != nil {
+				return "", nil, err
+			}
+			if fields == nil {
+				continue
+			}
+
+			pkgs = append(pkgs, distroPackage{
+				Name:        fields["P"],
+				Release:     release,
+				Repository:  repo,
+				Version:     fields["V"],
+				Description: fields["T"],
+				URL:         fields["U"],
+				License:     fields["L"],
+				Depends:     strings.Fields(fields["D"]),
+			})
+			break
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return "", nil, failure.New(ErrDistroPackageNotFound,
+			failure.Message("Package not found in any Alpine Linux branch"),
+			failure.Context{
+				"pkg":    pkgPath,
+				"mirror": mirror,
+			},
+		)
+	}
+
+	return formatDistroPackageDoc(pkgs), distroSources(pkgs), nil
+}
+
+// alpineRegistration declares the Alpine source
+var alpineRegistration = investigator.Registration{
+	Type:         source.TypeAlpine,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"apk", "alpine"},
+	Hosts:        []string{"pkgs.alpinelinux.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://pkgs\.alpinelinux\.org/package/[^/\s]+/[^/\s]+/[^/\s]+/([^/\s)?]+)`),
+			Command:     regexp.MustCompile(`apk add (?:--no-cache )?([^-\s][^\s]*)`),
+			Description: "Alpine Linux package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &AlpineInvestigator{}
+	},
+}
+
+// Implementation of Alpine Investigator
+type AlpineInvestigator struct{}
+
+func (i *AlpineInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the Alpine Linux mirror
+	content, relatedSources, err := fetchAlpine(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *AlpineInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://pkgs.alpinelinux.org/packages?name=%s", url.QueryEscape(packagePath))
+}
+
+func (i *AlpineInvestigator) GetSourceType() source.Type {
+	return source.TypeAlpine
+}
+
+func (i *AlpineInvestigator) PackageFromURL(rawURL string) (string, error) {
+	// Extract package path from Alpine Linux packages URL
+	// Example: https://pkgs.alpinelinux.org/packages?name=openssl -> openssl
+	// Example: https://pkgs.alpinelinux.org/package/edge/main/x86_64/openssl -> openssl
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "pkgs.alpinelinux.org" {
+		return rawURL, nil
+	}
+	if name := u.Query().Get("name"); name != "" {
+		return name, nil
+	}
+	if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) == 5 && parts[0] == "package" {
+		return parts[4], nil
+	}
+	return "", failure.New(ErrInvalidPackagePath,
+		failure.Message("Invalid Alpine Linux package path"),
+		failure.Context{"url": rawURL},
+	)
+}
@@ -0,0 +1,203 @@
+package sourceimpl
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// archRepositories are the repositories searched by default, stable first
+var archRepositories = []string{"core", "extra", "core-testing", "extra-testing"}
+
+// parseArchDesc parses a "desc" file of an Arch Linux repository database
+// Each field starts with a "%NAME%" line followed by one value per line until a blank line.
+func parseArchDesc(content string) map[string][]string {
+	fields := map[string][]string{}
+	var field string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "":
+			field = ""
+		case strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%") && len(line) > 2:
+			field = strings.Trim(line, "%")
+		case field != "":
+			fields[field] = append(fields[field], line)
+		}
+	}
+	return fields
+}
+
+// findArchPackage finds the package in a repository database (<repo>.db)
+// The database is a tar archive, optionally gzip compressed, with a "<name>-<version>/desc" entry per package.
+func findArchPackage(r io.Reader, name string) (map[string][]string, error) {
+	br := bufio.NewReader(r)
+	var archive io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		defer gz.Close()
+		archive = gz
+	}
+
+	tr := tar.NewReader(archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+
+		// Skip entries of other packages without reading them
+		dir, file := path.Split(strings.TrimSuffix(hdr.Name, "/"))
+		if file != "desc" || !strings.HasPrefix(dir, name+"-") {
+			continue
+		}
+
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		fields := parseArchDesc(string(b))
+		if archField(fields, "NAME") == name {
+			return fields, nil
+		}
+	}
+}
+
+// archField returns the first value of the field in a parsed "desc" file
+func archField(fields map[string][]string, name string) string {
+	if len(fields[name]) == 0 {
+		return ""
+	}
+	return fields[name][0]
+}
+
+// fetchArch fetches the package information from the database of each Arch Linux repository
+// Returns the content, related sources, and any error
+func fetchArch(pkgPath string) (string, []source.RelatedReference, error) {
+	mirror := distroMirror("MIRU_ARCH_MIRROR", "https://geo.mirror.pkgbuild.com")
+
+	var pkgs []distroPackage
+	for _, repo := range distroReleases("MIRU_ARCH_REPOS", archRepositories) {
+		f, err := openMirrorFile(mirror, fmt.Sprintf("%s/os/x86_64/%s.db", repo, repo))
+		if err != nil {
+			return "", nil, err
+		}
+		if f == nil {
+			continue
+		}
+		fields, err := findArchPackage(f, pkgPath)
+		f.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		if fields == nil {
+			continue
+		}
+
+		pkgs = append(pkgs, distroPackage{
+			Name:        archField(fields, "NAME"),
+			Release:     repo,
+			Version:     archField(fields, "VERSION"),
+			Description: archField(fields, "DESC"),
+			URL:         archField(fields, "URL"),
+			License:     strings.Join(fields["LICENSE"], ", "),
+			Depends:     fields["DEPENDS"],
+		})
+	}
+
+	if len(pkgs) == 0 {
+		return "", nil, failure.New(ErrDistroPackageNotFound,
+			failure.Message("Package not found in any Arch Linux repository"),
+			failure.Context{
+				"pkg":    pkgPath,
+				"mirror": mirror,
+			},
+		)
+	}
+
+	return formatDistroPackageDoc(pkgs), distroSources(pkgs), nil
+}
+
+// archRegistration declares the Arch Linux source
+var archRegistration = investigator.Registration{
+	Type:         source.TypeArch,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"arch", "pacman"},
+	Hosts:        []string{"archlinux.org/packages"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://archlinux\.org/packages/[^/\s]+/[^/\s]+/([^/\s)]+)`),
+			Command:     regexp.MustCompile(`pacman -Sy?u? (?:--noconfirm )?([^-\s][^\s]*)`),
+			Description: "Arch Linux package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &ArchInvestigator{}
+	},
+}
+
+// Implementation of Arch Linux Investigator
+type ArchInvestigator struct{}
+
+func (i *ArchInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the Arch Linux mirror
+	content, relatedSources, err := fetchArch(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *ArchInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://archlinux.org/packages/?name=%s", url.QueryEscape(packagePath))
+}
+
+func (i *ArchInvestigator) GetSourceType() source.Type {
+	return source.TypeArch
+}
+
+func (i *ArchInvestigator) PackageFromURL(rawURL string) (string, error) {
+	// Extract package path from Arch Linux packages URL
+	// Example: https://archlinux.org/packages/?name=ripgrep -> ripgrep
+	// Example: https://archlinux.org/packages/extra/x86_64/ripgrep/ -> ripgrep
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "archlinux.org" {
+		return rawURL, nil
+	}
+	if name := u.Query().Get("name"); name != "" {
+		return name, nil
+	}
+	if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) == 4 && parts[0] == "packages" {
+		return parts[3], nil
+	}
+	return "", failure.New(ErrInvalidPackagePath,
+		failure.Message("Invalid Arch Linux package path"),
+		failure.Context{"url": rawURL},
+	)
+}
@@ -1,28 +1,183 @@
 package sourceimpl
 
 import (
+	"encoding/gob"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ka2n/miru/api/cache"
+	"github.com/ka2n/miru/api/config"
 	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/log"
 )
 
+var (
+	// fetchCache caches the data fetched by investigators
+	fetchCache = cache.New[source.Data]("fetch")
+
+	// htmlCache caches HTML pages
+	htmlCache = cache.New[httpResponse]("html")
+
+	// responseCache caches registry responses to revalidate them when the fetched data expires
+	responseCache = cache.New[httpResponse]("http")
+
+	configureCacheOnce sync.Once
+)
+
+// ConfigureCache applies the cache settings of the config file
+// It is called before the caches are used, since the config file is loaded lazily.
+func ConfigureCache() {
+	configureCacheOnce.Do(func() {
+		cfg, err := config.Load()
+		if err != nil {
+			// Reported when loading custom sources
+			return
+		}
+		// The MIRU_CACHE_BACKEND environment variable takes precedence
+		if cfg.Cache.Backend != "" && os.Getenv("MIRU_CACHE_BACKEND") == "" && os.Getenv("MIRU_NO_CACHE") != "1" {
+			backend, err := cache.Open(cfg.Cache.Backend)
+			if err != nil {
+				log.Logger.Warn("Ignoring invalid cache backend in config file", "backend", cfg.Cache.Backend)
+			} else {
+				cache.SetDefault(backend)
+			}
+		}
+		if cfg.Cache.MaxSize != "" {
+			size, err := cache.ParseSize(cfg.Cache.MaxSize)
+			if err != nil {
+				log.Logger.Warn("Ignoring invalid cache size in config file", "max_size", cfg.Cache.MaxSize)
+			} else {
+				cache.MaxSize = size
+			}
+		}
+		if cfg.Cache.MaxEntries > 0 {
+			cache.MaxEntries = cfg.Cache.MaxEntries
+		}
+		if cfg.Cache.StaleWhileRevalidate {
+			cache.StaleWhileRevalidate = true
+		}
+		configureTTL(cfg.Cache.TTL)
+	})
+}
+
+// configureTTL applies the TTL settings of the config file
+// Keys are "default", cache kinds, source types, or language aliases of source types.
+// Aliases are resolved when the TTL of fetched data is first looked up, so that source plugins are
+// not run by commands that do not fetch, and only if they are not aliases of builtin sources.
+func configureTTL(settings map[string]string) {
+	ttls := map[string]time.Duration{}
+	for name, v := range settings {
+		d, err := cache.ParseDuration(v)
+		if err != nil || d == 0 {
+			log.Logger.Warn("Ignoring invalid cache TTL in config file", "name", name, "ttl", v)
+			continue
+		}
+		ttls[name] = d
+	}
+
+	if d, ok := ttls["default"]; ok {
+		cache.DefaultTTL = d
+		fetchCache.SetTTL(d)
+		htmlCache.SetTTL(d)
+	}
+	if d, ok := ttls["fetch"]; ok {
+		fetchCache.SetTTL(d)
+	}
+	if d, ok := ttls["html"]; ok {
+		htmlCache.SetTTL(d)
+	}
+
+	sourceTTLs := sync.OnceValue(func() map[string]time.Duration {
+		m := map[string]time.Duration{}
+		for name, d := range ttls {
+			switch name {
+			case "default", "fetch", "html":
+				continue
+			}
+			if t, ok := investigator.Resolve(name); ok {
+				name = t.String()
+			}
+			m[name] = d
+		}
+		return m
+	})
+
+	// Fetched data is keyed by "type:path"
+	fetchCache.SetTTLFunc(func(key string) time.Duration {
+		sourceType, _, _ := strings.Cut(key, ":")
+		return sourceTTLs()[sourceType]
+	})
+}
+
+// Schema versions of the cached values, see cache.Cache.SetSchema
+// Bump the version of a kind when the type or the meaning of its values changes.
+const (
+	fetchSchema    = 0
+	htmlSchema     = 1 // Responses with their validators instead of page bodies
+	responseSchema = 0
+	failureSchema  = 0
+)
+
+func init() {
+	fetchCache.SetSchema(fetchSchema)
+	htmlCache.SetSchema(htmlSchema)
+	responseCache.SetSchema(responseSchema)
+	failureCache.SetSchema(failureSchema)
+
+	// Metadata returned by plugins holds decoded JSON values, which gob must know to encode
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// FetchCacheKey returns the key of the data of the package in the fetch cache
+// Packages fetched from a registry configured instead of the public one are keyed with the registry.
+func FetchCacheKey(t source.Type, packagePath string) string {
+	key := fmt.Sprintf("%s:%s", t, packagePath)
+	if scope := registryScope(t, packagePath); scope != "" {
+		key += " from " + scope
+	}
+	return key
+}
+
 // FetchWithCache fetches data from the source with cache support
 // It uses the cache.GetOrSet function to retrieve data from cache or fetch it if not available
 // The cache key is generated from the investigator type and package path
 // The forceUpdate parameter can be used to ignore the cache and fetch fresh data
+// Expired data may be returned in offline and stale-while-revalidate modes or when fetching fails, see Data.Stale.
+// Failed fetches are cached with a backoff, during which the error is returned without fetching unless forceUpdate is set.
 func FetchWithCache(investigator investigator.SourceInvestigator, packagePath string, forceUpdate bool) (source.Data, error) {
 	// Generate cache key
-	cacheKey := fmt.Sprintf("%s:%s", investigator.GetSourceType(), packagePath)
+	cacheKey := FetchCacheKey(investigator.GetSourceType(), packagePath)
 
-	// Create cache instance for source.Data type
-	cache := cache.New[source.Data]("fetch")
+	ConfigureCache()
+	if !forceUpdate {
+		if err, ok := cachedFailure(cacheKey); ok {
+			log.Logger.Debug("Using cached failure", "key", cacheKey, "error", err)
+			return source.Data{}, err
+		}
+	}
 
 	// Get data from cache or fetch it
-	data, err := cache.GetOrSet(cacheKey, func() (source.Data, error) {
-		return investigator.Fetch(packagePath)
+	data, stale, err := fetchCache.GetOrSetStale(cacheKey, func() (source.Data, error) {
+		data, err := investigator.Fetch(packagePath)
+		if err == nil {
+			_ = failureCache.Delete(cacheKey)
+		}
+		return data, err
 	}, forceUpdate)
+	if err != nil {
+		// Failures are not recorded when expired data is returned instead, so that the data keeps being used
+		recordFailure(cacheKey, err)
+		return data, err
+	}
+	if stale {
+		log.Logger.Debug("Using stale cache entry", "key", cacheKey)
+		data.Stale = true
+	}
 
-	return data, err
+	return data, nil
 }
@@ -0,0 +1,323 @@
+package sourceimpl
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrCocoaPodsPodNotFound represents an error when the pod is not found in the trunk
+	ErrCocoaPodsPodNotFound ErrorCode = "CocoaPodsPodNotFound"
+)
+
+// cocoaPodsTrunkInfo represents the pod information from the CocoaPods trunk API
+type cocoaPodsTrunkInfo struct {
+	Versions []struct {
+		Name      string `json:"name"`
+		CreatedAt string `json:"created_at"`
+	} `json:"versions"`
+}
+
+// LatestVersion returns the highest published version
+// Versions are listed in the order they were pushed, which differs from the version order when older lines get patch releases.
+func (t cocoaPodsTrunkInfo) LatestVersion() string {
+	var latest string
+	for _, v := range t.Versions {
+		if latest == "" || compareVersions(v.Name, latest) > 0 {
+			latest = v.Name
+		}
+	}
+	return latest
+}
+
+// cocoaPodsSpec represents a podspec in JSON format from the specs repository
+type cocoaPodsSpec struct {
+	Name             string          `json:"name"`
+	Version          string          `json:"version"`
+	Summary          string          `json:"summary"`
+	Description      string          `json:"description"`
+	Homepage         string          `json:"homepage"`
+	DocumentationURL string          `json:"documentation_url"`
+	License          json.RawMessage `json:"license"`
+	Source           struct {
+		Git  string `json:"git"`
+		Tag  string `json:"tag"`
+		HTTP string `json:"http"`
+	} `json:"source"`
+	Platforms    map[string]string `json:"platforms"`
+	SwiftVersion string            `json:"swift_version"`
+}
+
+// LicenseName returns the license type of the podspec
+// The license field is either a string or an object with a "type" key
+func (s cocoaPodsSpec) LicenseName() string {
+	if len(s.License) == 0 {
+		return ""
+	}
+	var name string
+	if err := json.Unmarshal(s.License, &name); err == nil {
+		return name
+	}
+	var license struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(s.License, &license); err == nil {
+		return license.Type
+	}
+	return ""
+}
+
+// cocoaPodsShardPath returns the sharded directory of a pod in the specs repository
+// The specs repository shards pods by the first three hex characters of the MD5 of the pod name
+// Example: Alamofire -> d/a/2
+func cocoaPodsShardPath(name string) string {
+	sum := md5.Sum([]byte(name))
+	h := hex.EncodeToString(sum[:])
+	return fmt.Sprintf("%c/%c/%c", h[0], h[1], h[2])
+}
+
+// fetchCocoaPods fetches the podspec of the latest version from the CocoaPods specs repository
+// Returns the content, related sources, and any error
+func fetchCocoaPods(pkgPath string) (string, []source.RelatedReference, error) {
+	// Subspecs (e.g. Firebase/Analytics) are documented by the root pod
+	name := strings.Split(pkgPath, "/")[0]
+
+	// Get the version list from the trunk API
+	trunkURL := fmt.Sprintf("https://trunk.cocoapods.org/api/v1/pods/%s", name)
+	resp, err := http.Get(trunkURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrCocoaPodsPodNotFound,
+			failure.Message("Failed to fetch pod information from trunk.cocoapods.org"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var trunk cocoaPodsTrunkInfo
+	if err := json.NewDecoder(resp.Body).Decode(&trunk); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	if len(trunk.Versions) == 0 {
+		return "", nil, failure.New(ErrCocoaPodsPodNotFound,
+			failure.Message("No versions published for the pod"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+		)
+	}
+
+	version := trunk.LatestVersion()
+
+	// Get the podspec from the specs CDN
+	specURL := fmt.Sprintf("https://cdn.cocoapods.org/Specs/%s/%s/%s/%s.podspec.json", cocoaPodsShardPath(name), name, version, name)
+	specResp, err := http.Get(specURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer specResp.Body.Close()
+
+	if specResp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch podspec from cdn.cocoapods.org"),
+			failure.Context{
+				"pkg": pkgPath,
+				"url": specURL,
+			},
+			httpStatus(specResp.StatusCode),
+		)
+	}
+
+	var spec cocoaPodsSpec
+	if err := json.NewDecoder(specResp.Body).Decode(&spec); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	doc := formatCocoaPodsDoc(spec)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add homepage if available
+	if spec.Homepage != "" {
+		detected := source.DetectSourceTypeFromURL(spec.Homepage)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(spec.Homepage, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  spec.Homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Add documentation if available
+	if spec.DocumentationURL != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.TypeDocumentation,
+			URL:  spec.DocumentationURL,
+			From: "api",
+		})
+	}
+
+	// Add repository if available
+	if spec.Source.Git != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.DetectSourceTypeFromURL(spec.Source.Git),
+			URL:  cleanupURL(spec.Source.Git, source.TypeUnknown),
+			From: "api",
+		})
+	}
+
+	// Extract additional sources from documentation
+	docSources := extractRelatedSources(doc, name)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// formatCocoaPodsDoc formats the podspec into a markdown document
+func formatCocoaPodsDoc(spec cocoaPodsSpec) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", spec.Name, spec.Version))
+
+	// Description
+	if spec.Description != "" {
+		sections = append(sections, spec.Description)
+	} else if spec.Summary != "" {
+		sections = append(sections, spec.Summary)
+	}
+
+	// Metadata
+	var metadata []string
+	if license := spec.LicenseName(); license != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", license))
+	}
+	if len(spec.Platforms) > 0 {
+		var platforms []string
+		for _, p := range []string{"ios", "osx", "tvos", "watchos", "visionos"} {
+			if v, ok := spec.Platforms[p]; ok {
+				platforms = append(platforms, fmt.Sprintf("%s %s", p, v))
+			}
+		}
+		if len(platforms) > 0 {
+			metadata = append(metadata, fmt.Sprintf("**Platforms:** %s", strings.Join(platforms, ", ")))
+		}
+	}
+	if spec.SwiftVersion != "" {
+		metadata = append(metadata, fmt.Sprintf("**Swift:** %s", spec.SwiftVersion))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	if spec.Homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", spec.Homepage))
+	}
+	if spec.DocumentationURL != "" {
+		links = append(links, fmt.Sprintf("**Documentation:** %s", spec.DocumentationURL))
+	}
+	if spec.Source.Git != "" {
+		links = append(links, fmt.Sprintf("**Source:** %s", spec.Source.Git))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// cocoaPodsRegistration declares the CocoaPods source
+var cocoaPodsRegistration = investigator.Registration{
+	Type:         source.TypeCocoaPods,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"pod", "cocoapods"},
+	Hosts:        []string{"cocoapods.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?cocoapods\.org/pods/([^/\s)]+)`),
+			Command:     regexp.MustCompile(`pod ['"]([^'"/\s]+)`),
+			Description: "CocoaPods package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &CocoaPodsInvestigator{}
+	},
+}
+
+// Implementation of CocoaPods Investigator
+type CocoaPodsInvestigator struct{}
+
+func (i *CocoaPodsInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from cocoapods.org
+	content, relatedSources, err := fetchCocoaPods(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *CocoaPodsInvestigator) GetURL(packagePath string) string {
+	// Subspecs are shown on the page of the root pod
+	name := strings.Split(packagePath, "/")[0]
+	return fmt.Sprintf("https://cocoapods.org/pods/%s", name)
+}
+
+func (i *CocoaPodsInvestigator) GetSourceType() source.Type {
+	return source.TypeCocoaPods
+}
+
+func (i *CocoaPodsInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from CocoaPods URL
+	// Example: https://cocoapods.org/pods/Alamofire -> Alamofire
+	prefix := "https://cocoapods.org/pods/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := url[len(prefix):]
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid CocoaPods package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
@@ -0,0 +1,107 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCocoaPodsShardPath(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  string
+		want string
+	}{
+		{
+			name: "Alamofire",
+			pod:  "Alamofire",
+			want: "d/a/2",
+		},
+		{
+			name: "Case sensitive pod name",
+			pod:  "alamofire",
+			want: "b/8/3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cocoaPodsShardPath(tt.pod); got != tt.want {
+				t.Errorf("cocoaPodsShardPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCocoaPodsSpecLicenseName(t *testing.T) {
+	tests := []struct {
+		name    string
+		license string
+		want    string
+	}{
+		{
+			name:    "String license",
+			license: `"MIT"`,
+			want:    "MIT",
+		},
+		{
+			name:    "Object license",
+			license: `{"type": "Apache-2.0", "file": "LICENSE"}`,
+			want:    "Apache-2.0",
+		},
+		{
+			name:    "Missing license",
+			license: ``,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := cocoaPodsSpec{License: json.RawMessage(tt.license)}
+			if got := spec.LicenseName(); got != tt.want {
+				t.Errorf("LicenseName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCocoaPodsTrunkInfoLatestVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions string
+		want     string
+	}{
+		{
+			name:     "Pushed in version order",
+			versions: `[{"name": "5.9.0"}, {"name": "5.10.0"}]`,
+			want:     "5.10.0",
+		},
+		{
+			name:     "Patch release of an older line pushed last",
+			versions: `[{"name": "5.10.0"}, {"name": "6.0.0"}, {"name": "5.10.1"}]`,
+			want:     "6.0.0",
+		},
+		{
+			name:     "Pre-release before the final release",
+			versions: `[{"name": "2.0.0"}, {"name": "2.0.0-beta.1"}]`,
+			want:     "2.0.0",
+		},
+		{
+			name:     "No versions",
+			versions: `[]`,
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trunk cocoaPodsTrunkInfo
+			if err := json.Unmarshal([]byte(`{"versions": `+tt.versions+`}`), &trunk); err != nil {
+				t.Fatal(err)
+			}
+			if got := trunk.LatestVersion(); got != tt.want {
+				t.Errorf("LatestVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
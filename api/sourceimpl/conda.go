@@ -0,0 +1,278 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrCondaPackageNotFound represents an error when the package is not found in the channel
+	ErrCondaPackageNotFound ErrorCode = "CondaPackageNotFound"
+)
+
+// condaPackage represents the anaconda.org API response for a package of a channel
+// The about fields come from the recipe and the attributes of each file are its repodata record.
+type condaPackage struct {
+	Name          string   `json:"name"`
+	Summary       string   `json:"summary"`
+	Description   string   `json:"description"`
+	Home          string   `json:"home"`
+	DevURL        string   `json:"dev_url"`
+	DocURL        string   `json:"doc_url"`
+	License       string   `json:"license"`
+	LatestVersion string   `json:"latest_version"`
+	Versions      []string `json:"versions"`
+	Files         []struct {
+		Version string   `json:"version"`
+		Labels  []string `json:"labels"`
+		Attrs   struct {
+			Subdir  string   `json:"subdir"`
+			Depends []string `json:"depends"`
+		} `json:"attrs"`
+	} `json:"files"`
+}
+
+// RunRequirements returns the run requirements of the latest version
+// The requirements of noarch builds are preferred, as they are common to all platforms.
+func (p condaPackage) RunRequirements() []string {
+	var depends []string
+	found := false
+	for _, f := range p.Files {
+		if f.Version != p.LatestVersion || (len(f.Labels) > 0 && !slices.Contains(f.Labels, "main")) {
+			continue
+		}
+		if f.Attrs.Subdir == "noarch" {
+			return f.Attrs.Depends
+		}
+		if !found {
+			depends, found = f.Attrs.Depends, true
+		}
+	}
+	return depends
+}
+
+// splitCondaPackagePath splits a package path into the channel and package name
+// The channel defaults to conda-forge.
+// Example: numpy -> conda-forge, numpy
+// Example: bioconda/samtools -> bioconda, samtools
+func splitCondaPackagePath(pkgPath string) (string, string) {
+	if channel, name, ok := strings.Cut(pkgPath, "/"); ok {
+		return channel, strings.ToLower(name)
+	}
+	return "conda-forge", strings.ToLower(pkgPath)
+}
+
+// condaPackageSources extracts the related sources from the package metadata
+func condaPackageSources(pkg condaPackage) []source.RelatedReference {
+	var sources []source.RelatedReference
+	seen := map[string]bool{}
+	add := func(ref source.RelatedReference) {
+		key := string(ref.Type) + ref.URL + ref.Path
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		sources = append(sources, ref)
+	}
+
+	// Add homepage if available
+	if pkg.Home != "" {
+		detected := source.DetectSourceTypeFromURL(pkg.Home)
+		if detected != source.TypeUnknown {
+			// Add as repository or registry if the URL is from GitHub/GitLab or PyPI
+			add(source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(pkg.Home, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			add(source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  pkg.Home,
+				From: "api",
+			})
+		}
+	}
+
+	// Add development URL as repository
+	if pkg.DevURL != "" {
+		detected := source.DetectSourceTypeFromURL(pkg.DevURL)
+		if detected.IsRepository() {
+			add(source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(pkg.DevURL, detected),
+				From: "api",
+			})
+		}
+	}
+
+	// Add documentation URL
+	if pkg.DocURL != "" {
+		add(source.RelatedReference{
+			Type: source.TypeDocumentation,
+			URL:  pkg.DocURL,
+			From: "api",
+		})
+	}
+
+	return sources
+}
+
+// fetchCondaForge fetches the package metadata of the channel from anaconda.org
+// Returns the content, related sources, and any error
+func fetchCondaForge(pkgPath string) (string, []source.RelatedReference, error) {
+	channel, name := splitCondaPackagePath(pkgPath)
+
+	apiURL := fmt.Sprintf("https://api.anaconda.org/package/%s/%s", channel, name)
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrCondaPackageNotFound,
+			failure.Message("Failed to fetch package from anaconda.org"),
+			failure.Context{
+				"pkg":     pkgPath,
+				"channel": channel,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var pkg condaPackage
+	if err := json.NewDecoder(resp.Body).Decode(&pkg); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	if pkg.Name == "" {
+		pkg.Name = name
+	}
+
+	return formatCondaPackageDoc(pkg, channel), condaPackageSources(pkg), nil
+}
+
+// formatCondaPackageDoc formats the package metadata into a markdown document
+func formatCondaPackageDoc(pkg condaPackage, channel string) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", pkg.Name, pkg.LatestVersion))
+
+	// Summary
+	if pkg.Summary != "" {
+		sections = append(sections, strings.TrimSpace(pkg.Summary))
+	}
+
+	// Metadata
+	var metadata []string
+	if pkg.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", pkg.License))
+	}
+	metadata = append(metadata, fmt.Sprintf("**Channel:** %s", channel))
+	sections = append(sections, strings.Join(metadata, " • "))
+
+	// Links
+	var links []string
+	if pkg.Home != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", pkg.Home))
+	}
+	if pkg.DocURL != "" {
+		links = append(links, fmt.Sprintf("**Documentation:** %s", pkg.DocURL))
+	}
+	if pkg.DevURL != "" {
+		links = append(links, fmt.Sprintf("**Repository:** %s", pkg.DevURL))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Dependencies
+	if reqs := pkg.RunRequirements(); len(reqs) > 0 {
+		sections = append(sections, fmt.Sprintf("**Dependencies:** %s", strings.Join(reqs, ", ")))
+	}
+
+	// Description
+	if pkg.Description != "" {
+		sections = append(sections, strings.TrimSpace(pkg.Description))
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// condaForgeRegistration declares the conda-forge source
+var condaForgeRegistration = investigator.Registration{
+	Type:         source.TypeCondaForge,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"conda", "conda-forge", "mamba"},
+	Hosts:        []string{"anaconda.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://anaconda\.org/conda-forge/([^/\s)]+)`),
+			Command:     regexp.MustCompile(`(?:conda|mamba) install (?:-c conda-forge )?([^-\s][^\s=<>]*)`),
+			Description: "conda-forge package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &CondaForgeInvestigator{}
+	},
+}
+
+// Implementation of conda-forge Investigator
+type CondaForgeInvestigator struct{}
+
+func (i *CondaForgeInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from anaconda.org
+	content, relatedSources, err := fetchCondaForge(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *CondaForgeInvestigator) GetURL(packagePath string) string {
+	channel, name := splitCondaPackagePath(packagePath)
+	return fmt.Sprintf("https://anaconda.org/%s/%s", channel, name)
+}
+
+func (i *CondaForgeInvestigator) GetSourceType() source.Type {
+	return source.TypeCondaForge
+}
+
+func (i *CondaForgeInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from anaconda.org URL
+	// Example: https://anaconda.org/conda-forge/numpy -> numpy
+	prefix := "https://anaconda.org/conda-forge/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := strings.Split(url[len(prefix):], "/")[0]
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid conda-forge package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
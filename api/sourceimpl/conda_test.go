@@ -0,0 +1,96 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+const condaRequestsPackage = `{
+  "name": "requests",
+  "summary": "Requests is an elegant and simple HTTP library for Python, built with ♥.",
+  "description": "Requests allows you to send HTTP/1.1 requests extremely easily.",
+  "home": "https://requests.readthedocs.io",
+  "dev_url": "https://github.com/psf/requests",
+  "doc_url": "https://requests.readthedocs.io",
+  "license": "Apache-2.0",
+  "latest_version": "2.32.3",
+  "versions": ["2.32.2", "2.32.3"],
+  "files": [
+    {"version": "2.32.2", "labels": ["main"], "attrs": {"subdir": "noarch", "depends": ["python >=3.8"]}},
+    {"version": "2.32.3", "labels": ["broken"], "attrs": {"subdir": "noarch", "depends": ["python >=3.7"]}},
+    {"version": "2.32.3", "labels": ["main"], "attrs": {"subdir": "linux-64", "depends": ["python >=3.8,<3.9.0a0", "certifi >=2017.4.17"]}},
+    {"version": "2.32.3", "labels": ["main"], "attrs": {"subdir": "noarch", "depends": ["python >=3.8", "certifi >=2017.4.17", "charset-normalizer >=2,<4"]}}
+  ]
+}`
+
+func TestFormatCondaPackageDoc(t *testing.T) {
+	var pkg condaPackage
+	if err := json.Unmarshal([]byte(condaRequestsPackage), &pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# requests v2.32.3\n\n" +
+		"Requests is an elegant and simple HTTP library for Python, built with ♥.\n\n" +
+		"**License:** Apache-2.0 • **Channel:** conda-forge\n\n" +
+		"**Homepage:** https://requests.readthedocs.io\n" +
+		"**Documentation:** https://requests.readthedocs.io\n" +
+		"**Repository:** https://github.com/psf/requests\n\n" +
+		"**Dependencies:** python >=3.8, certifi >=2017.4.17, charset-normalizer >=2,<4\n\n" +
+		"Requests allows you to send HTTP/1.1 requests extremely easily."
+	if diff := cmp.Diff(want, formatCondaPackageDoc(pkg, "conda-forge")); diff != "" {
+		t.Errorf("formatCondaPackageDoc() mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeHomepage, URL: "https://requests.readthedocs.io", From: "api"},
+		{Type: source.TypeGitHub, URL: "https://github.com/psf/requests", From: "api"},
+		{Type: source.TypeDocumentation, URL: "https://requests.readthedocs.io", From: "api"},
+	}
+	if diff := cmp.Diff(wantSources, condaPackageSources(pkg)); diff != "" {
+		t.Errorf("condaPackageSources() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCondaPackageRunRequirements(t *testing.T) {
+	var pkg condaPackage
+	if err := json.Unmarshal([]byte(`{
+  "latest_version": "2.0.0",
+  "files": [
+    {"version": "1.26.4", "attrs": {"subdir": "linux-64", "depends": ["python >=3.9"]}},
+    {"version": "2.0.0", "attrs": {"subdir": "linux-64", "depends": ["libblas >=3.9.0", "python >=3.10"]}},
+    {"version": "2.0.0", "attrs": {"subdir": "osx-arm64", "depends": ["libcxx >=16", "python >=3.10"]}}
+  ]
+}`), &pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without noarch builds, the first build of the latest version is used
+	want := []string{"libblas >=3.9.0", "python >=3.10"}
+	if diff := cmp.Diff(want, pkg.RunRequirements()); diff != "" {
+		t.Errorf("RunRequirements() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitCondaPackagePath(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantChannel string
+		wantName    string
+	}{
+		{path: "numpy", wantChannel: "conda-forge", wantName: "numpy"},
+		{path: "conda-forge/PyYAML", wantChannel: "conda-forge", wantName: "pyyaml"},
+		{path: "bioconda/samtools", wantChannel: "bioconda", wantName: "samtools"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			channel, name := splitCondaPackagePath(tt.path)
+			if channel != tt.wantChannel || name != tt.wantName {
+				t.Errorf("splitCondaPackagePath() = %v, %v, want %v, %v", channel, name, tt.wantChannel, tt.wantName)
+			}
+		})
+	}
+}
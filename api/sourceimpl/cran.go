@@ -0,0 +1,546 @@
+package sourceimpl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+	"golang.org/x/net/html"
+)
+
+const (
+	// ErrRPackageNotFound represents an error when the R package is not found in the repository
+	ErrRPackageNotFound ErrorCode = "RPackageNotFound"
+)
+
+// rPackage represents an R package description read from a DESCRIPTION file
+type rPackage struct {
+	Name        string
+	Title       string
+	Version     string
+	Description string
+	License     string
+	Depends     []string
+	Imports     []string
+	URLs        []string
+	BugReports  string
+
+	// ReferenceManual is the URL of the PDF reference manual
+	ReferenceManual string
+	// Vignettes are the URLs of the rendered vignettes
+	Vignettes []string
+}
+
+// newRPackage creates an rPackage from the fields of a DESCRIPTION file
+func newRPackage(fields map[string]string) rPackage {
+	return rPackage{
+		Name:        fields["Package"],
+		Title:       strings.Join(strings.Fields(fields["Title"]), " "),
+		Version:     fields["Version"],
+		Description: strings.Join(strings.Fields(fields["Description"]), " "),
+		License:     fields["License"],
+		Depends:     splitDCFList(fields["Depends"]),
+		Imports:     splitDCFList(fields["Imports"]),
+		// URL entries may be separated by commas or whitespace
+		URLs:       strings.Fields(strings.ReplaceAll(fields["URL"], ",", " ")),
+		BugReports: strings.TrimSpace(fields["BugReports"]),
+	}
+}
+
+// rPackageSources extracts related sources from an R package description
+// GitHub/GitLab URLs in the URL and BugReports fields are mapped to repositories,
+// the reference manual and vignettes to documentation.
+func rPackageSources(pkg rPackage) []source.RelatedReference {
+	var sources []source.RelatedReference
+
+	hasHomepage := false
+	for _, u := range pkg.URLs {
+		detected := source.DetectSourceTypeFromURL(u)
+		if detected.IsRepository() {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(u, detected),
+				From: "api",
+			})
+		} else if !hasHomepage {
+			// The first other URL is the homepage (usually a pkgdown site)
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  u,
+				From: "api",
+			})
+			hasHomepage = true
+		}
+	}
+
+	// BugReports usually points to the issue tracker of the repository
+	if pkg.BugReports != "" {
+		detected := source.DetectSourceTypeFromURL(pkg.BugReports)
+		if detected.IsRepository() {
+			repoURL := strings.TrimSuffix(strings.TrimSuffix(pkg.BugReports, "/"), "/issues")
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(repoURL, detected),
+				From: "api",
+			})
+		}
+	}
+
+	// Add reference manual and vignettes as documentation
+	if pkg.ReferenceManual != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.TypeDocumentation,
+			URL:  pkg.ReferenceManual,
+			From: "api",
+		})
+	}
+	for _, v := range pkg.Vignettes {
+		sources = append(sources, source.RelatedReference{
+			Type: source.TypeDocumentation,
+			URL:  v,
+			From: "api",
+		})
+	}
+
+	// Remove duplicates
+	seen := make(map[string]bool)
+	var uniqueSources []source.RelatedReference
+	for _, s := range sources {
+		if !seen[s.URL] {
+			uniqueSources = append(uniqueSources, s)
+			seen[s.URL] = true
+		}
+	}
+
+	return uniqueSources
+}
+
+// formatRPackageDoc formats the R package information into a markdown document
+func formatRPackageDoc(pkg rPackage, readme string) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", pkg.Name, pkg.Version))
+
+	// Description
+	if pkg.Title != "" {
+		sections = append(sections, fmt.Sprintf("**%s**", pkg.Title))
+	}
+	if pkg.Description != "" {
+		sections = append(sections, pkg.Description)
+	}
+
+	// Metadata
+	var metadata []string
+	if pkg.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", pkg.License))
+	}
+	if len(pkg.Depends) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Depends:** %s", strings.Join(pkg.Depends, ", ")))
+	}
+	if len(pkg.Imports) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Imports:** %s", strings.Join(pkg.Imports, ", ")))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	for _, u := range pkg.URLs {
+		links = append(links, fmt.Sprintf("**URL:** %s", u))
+	}
+	if pkg.BugReports != "" {
+		links = append(links, fmt.Sprintf("**Bug Reports:** %s", pkg.BugReports))
+	}
+	if pkg.ReferenceManual != "" {
+		links = append(links, fmt.Sprintf("**Reference Manual:** %s", pkg.ReferenceManual))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Vignettes
+	if len(pkg.Vignettes) > 0 {
+		var vignettes []string
+		for _, v := range pkg.Vignettes {
+			name := v[strings.LastIndex(v, "/")+1:]
+			vignettes = append(vignettes, fmt.Sprintf("- [%s](%s)", name, v))
+		}
+		sections = append(sections, "## Vignettes\n\n"+strings.Join(vignettes, "\n"))
+	}
+
+	// README content
+	if readme != "" {
+		sections = append(sections, readme)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// fetchCRAN fetches the package description, vignettes and README from CRAN
+// Returns the content, related sources, and any error
+func fetchCRAN(pkgPath string) (string, []source.RelatedReference, error) {
+	// Get the DESCRIPTION file of the latest version
+	descURL := fmt.Sprintf("https://cran.r-project.org/web/packages/%s/DESCRIPTION", pkgPath)
+	resp, err := http.Get(descURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRPackageNotFound,
+			failure.Message("Failed to fetch package description from cran.r-project.org"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	paragraphs, err := parseDCF(resp.Body)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	if len(paragraphs) == 0 {
+		return "", nil, failure.New(ErrRPackageNotFound,
+			failure.Message("Empty DESCRIPTION file"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+		)
+	}
+
+	pkg := newRPackage(paragraphs[0])
+	pkg.ReferenceManual = fmt.Sprintf("https://cran.r-project.org/web/packages/%s/%s.pdf", pkgPath, pkgPath)
+
+	// The package page links the vignettes and the README
+	indexURL, _ := url.Parse(fmt.Sprintf("https://cran.r-project.org/web/packages/%s/index.html", pkgPath))
+	var readme string
+	// Pages are revalidated, as the data is fetched only when it expired or a reload was requested
+	if page, err := fetchHTML(indexURL, true); err == nil {
+		var readmeURL *url.URL
+		for _, link := range extractHTMLLinks(indexURL, page) {
+			switch {
+			case strings.Contains(link.Path, "/vignettes/") &&
+				(strings.HasSuffix(link.Path, ".html") || strings.HasSuffix(link.Path, ".pdf")):
+				// Skip the vignette sources (.Rmd) and extracted code (.R)
+				pkg.Vignettes = append(pkg.Vignettes, link.String())
+			case strings.Contains(link.Path, "/readme/"):
+				readmeURL = link
+			}
+		}
+		if readmeURL != nil {
+			readme, _ = FetchHTML(readmeURL, true)
+		}
+	}
+
+	doc := formatRPackageDoc(pkg, readme)
+
+	// Extract related sources
+	sources := rPackageSources(pkg)
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(readme, pkgPath)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// extractHTMLLinks returns the absolute URLs of all anchors in an HTML document
+func extractHTMLLinks(base *url.URL, body string) []*url.URL {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	var links []*url.URL
+	seen := make(map[string]bool)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				u, err := base.Parse(attr.Val)
+				if err != nil || seen[u.String()] {
+					continue
+				}
+				seen[u.String()] = true
+				links = append(links, u)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return links
+}
+
+// cranRegistration declares the CRAN source
+var cranRegistration = investigator.Registration{
+	Type:         source.TypeCRAN,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"r", "cran"},
+	Hosts:        []string{"cran.r-project.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://cran\.r-project\.org/(?:package=|web/packages/)([^/\s)]+)`),
+			Command:     regexp.MustCompile(`install\.packages\(\s*["']([^"']+)["']`),
+			Description: "R package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &CRANInvestigator{}
+	},
+}
+
+// Implementation of CRAN Investigator
+type CRANInvestigator struct{}
+
+func (i *CRANInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from cran.r-project.org
+	content, relatedSources, err := fetchCRAN(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *CRANInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://cran.r-project.org/package=%s", packagePath)
+}
+
+func (i *CRANInvestigator) GetSourceType() source.Type {
+	return source.TypeCRAN
+}
+
+func (i *CRANInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from CRAN URL
+	// Example: https://cran.r-project.org/package=dplyr -> dplyr
+	// Example: https://cran.r-project.org/web/packages/dplyr/index.html -> dplyr
+	for _, prefix := range []string{"https://cran.r-project.org/package=", "https://cran.r-project.org/web/packages/"} {
+		if strings.HasPrefix(url, prefix) {
+			packagePath := strings.Split(url[len(prefix):], "/")[0]
+			if packagePath == "" {
+				return "", failure.New(ErrInvalidPackagePath,
+					failure.Message("Invalid CRAN package path"),
+					failure.Context{"url": url},
+				)
+			}
+			return packagePath, nil
+		}
+	}
+	return url, nil
+}
+
+// fetchBioconductor fetches the package description from the Bioconductor package page
+// Returns the content, related sources, and any error
+func fetchBioconductor(pkgPath string) (string, []source.RelatedReference, error) {
+	pageURL, _ := url.Parse(fmt.Sprintf("https://bioconductor.org/packages/release/bioc/html/%s.html", pkgPath))
+	resp, err := http.Get(pageURL.String())
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRPackageNotFound,
+			failure.Message("Failed to fetch package page from bioconductor.org"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	pkg := parseBioconductorPage(pageURL, string(page))
+	if pkg.Name == "" {
+		pkg.Name = pkgPath
+	}
+
+	doc := formatRPackageDoc(pkg, "")
+
+	// Extract related sources
+	sources := rPackageSources(pkg)
+
+	return doc, sources, nil
+}
+
+// parseBioconductorPage reads the package description from a Bioconductor package page
+// The page shows the name and title as headings, the description after the Bioconductor version,
+// the DESCRIPTION fields in a table of details, and links to the vignettes and the reference manual.
+func parseBioconductorPage(base *url.URL, page string) rPackage {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return rPackage{}
+	}
+
+	fields := make(map[string]string)
+	var afterVersion bool
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "h1":
+				if fields["Package"] == "" {
+					fields["Package"] = htmlNodeText(n)
+				}
+				return
+			case "h2":
+				if fields["Title"] == "" {
+					fields["Title"] = htmlNodeText(n)
+				}
+				return
+			case "p":
+				text := htmlNodeText(n)
+				if afterVersion && fields["Description"] == "" {
+					fields["Description"] = text
+				}
+				if strings.HasPrefix(text, "Bioconductor version:") {
+					afterVersion = true
+				}
+				return
+			case "tr":
+				var cells []string
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.ElementNode && (c.Data == "td" || c.Data == "th") {
+						cells = append(cells, htmlNodeText(c))
+					}
+				}
+				if len(cells) == 2 && cells[0] != "" {
+					if _, ok := fields[cells[0]]; !ok {
+						fields[cells[0]] = cells[1]
+					}
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	pkg := newRPackage(fields)
+	for _, link := range extractHTMLLinks(base, page) {
+		switch {
+		case strings.Contains(link.Path, "/vignettes/") &&
+			(strings.HasSuffix(link.Path, ".html") || strings.HasSuffix(link.Path, ".pdf")):
+			pkg.Vignettes = append(pkg.Vignettes, link.String())
+		case strings.Contains(link.Path, "/manuals/") && strings.HasSuffix(link.Path, ".pdf"):
+			pkg.ReferenceManual = link.String()
+		}
+	}
+	return pkg
+}
+
+// htmlNodeText returns the text of an HTML node with whitespace collapsed
+func htmlNodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// bioconductorRegistration declares the Bioconductor source
+var bioconductorRegistration = investigator.Registration{
+	Type:         source.TypeBioconductor,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"bioc", "bioconductor"},
+	Hosts:        []string{"bioconductor.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?bioconductor\.org/packages/(?:release/bioc/html/)?([^/\s).]+)(?:\.html)?`),
+			Command:     regexp.MustCompile(`BiocManager::install\(\s*["']([^"']+)["']`),
+			Description: "Bioconductor package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &BioconductorInvestigator{}
+	},
+}
+
+// Implementation of Bioconductor Investigator
+type BioconductorInvestigator struct{}
+
+func (i *BioconductorInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from bioconductor.org
+	content, relatedSources, err := fetchBioconductor(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *BioconductorInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://bioconductor.org/packages/%s", packagePath)
+}
+
+func (i *BioconductorInvestigator) GetSourceType() source.Type {
+	return source.TypeBioconductor
+}
+
+func (i *BioconductorInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Bioconductor URL
+	// Example: https://bioconductor.org/packages/DESeq2 -> DESeq2
+	prefix := "https://bioconductor.org/packages/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := url[len(prefix):]
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Bioconductor package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
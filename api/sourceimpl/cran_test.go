@@ -0,0 +1,81 @@
+package sourceimpl
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestNewRPackage(t *testing.T) {
+	paragraphs, err := parseDCF(strings.NewReader(readTestFile(t, "cran_description.dcf")))
+	if err != nil {
+		t.Fatalf("parseDCF() error = %v", err)
+	}
+	if len(paragraphs) != 1 {
+		t.Fatalf("parseDCF() returned %d paragraphs, want 1", len(paragraphs))
+	}
+
+	want := rPackage{
+		Name:        "dplyr",
+		Title:       "A Grammar of Data Manipulation",
+		Version:     "1.1.4",
+		Description: "A fast, consistent tool for working with data frame like objects, both in memory and out of memory.",
+		License:     "MIT + file LICENSE",
+		Depends:     []string{"R (>= 3.5.0)"},
+		Imports:     []string{"cli (>= 3.4.0)", "generics", "glue (>= 1.3.2)", "lifecycle (>= 1.0.3)", "magrittr (>= 1.5)"},
+		URLs:        []string{"https://dplyr.tidyverse.org", "https://github.com/tidyverse/dplyr"},
+		BugReports:  "https://github.com/tidyverse/dplyr/issues",
+	}
+
+	got := newRPackage(paragraphs[0])
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("newRPackage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRPackageSources(t *testing.T) {
+	pkg := rPackage{
+		Name:            "dplyr",
+		URLs:            []string{"https://dplyr.tidyverse.org", "https://github.com/tidyverse/dplyr"},
+		BugReports:      "https://github.com/tidyverse/dplyr/issues",
+		ReferenceManual: "https://cran.r-project.org/web/packages/dplyr/dplyr.pdf",
+		Vignettes:       []string{"https://cran.r-project.org/web/packages/dplyr/vignettes/dplyr.html"},
+	}
+
+	want := []source.RelatedReference{
+		{Type: source.TypeHomepage, URL: "https://dplyr.tidyverse.org", From: "api"},
+		{Type: source.TypeGitHub, URL: "https://github.com/tidyverse/dplyr", From: "api"},
+		{Type: source.TypeDocumentation, URL: "https://cran.r-project.org/web/packages/dplyr/dplyr.pdf", From: "api"},
+		{Type: source.TypeDocumentation, URL: "https://cran.r-project.org/web/packages/dplyr/vignettes/dplyr.html", From: "api"},
+	}
+
+	got := rPackageSources(pkg)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("rPackageSources() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseBioconductorPage(t *testing.T) {
+	base, _ := url.Parse("https://bioconductor.org/packages/release/bioc/html/DESeq2.html")
+
+	want := rPackage{
+		Name:            "DESeq2",
+		Title:           "Differential gene expression analysis based on the negative binomial distribution",
+		Version:         "1.46.0",
+		Description:     "Estimate variance-mean dependence in count data from high-throughput sequencing assays and test for differential expression based on a model using the negative binomial distribution.",
+		License:         "LGPL (>= 3)",
+		Depends:         []string{"S4Vectors (>= 0.23.18)", "IRanges", "GenomicRanges"},
+		Imports:         []string{"BiocGenerics (>= 0.7.5)", "Biobase", "BiocParallel"},
+		URLs:            []string{"https://github.com/thelovelab/DESeq2"},
+		ReferenceManual: "https://bioconductor.org/packages/release/bioc/manuals/DESeq2/man/DESeq2.pdf",
+		Vignettes:       []string{"https://bioconductor.org/packages/release/bioc/vignettes/DESeq2/inst/doc/DESeq2.html"},
+	}
+
+	got := parseBioconductorPage(base, readTestFile(t, "bioconductor_deseq2.html"))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseBioconductorPage() mismatch (-want +got):\n%s", diff)
+	}
+}
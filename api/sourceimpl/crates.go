@@ -6,10 +6,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -42,9 +44,13 @@ type cratesVersionInfo struct {
 // fetchCratesIO fetches the README content from crates.io
 // Returns the content, related sources, and any error
 func fetchCratesIO(pkgPath string) (string, []source.RelatedReference, error) {
-	// Get package information from crates.io API
-	url := fmt.Sprintf("https://crates.io/api/v1/crates/%s?include=default_version", pkgPath)
-	resp, err := http.Get(url)
+	// Get package information from crates.io API, or the registry replacing it in the cargo config
+	base, token, err := cratesRegistry()
+	if err != nil {
+		return "", nil, err
+	}
+	url := fmt.Sprintf("%s/api/v1/crates/%s?include=default_version", base, pkgPath)
+	resp, err := registryGet(url, token)
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -56,6 +62,7 @@ func fetchCratesIO(pkgPath string) (string, []source.RelatedReference, error) {
 			failure.Context{
 				"pkg": pkgPath,
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -88,8 +95,8 @@ func fetchCratesIO(pkgPath string) (string, []source.RelatedReference, error) {
 		)
 	}
 
-	readmeURL := fmt.Sprintf("https://crates.io%s", defaultVersion.ReadmePath)
-	readmeResp, err := http.Get(readmeURL)
+	readmeURL := base + defaultVersion.ReadmePath
+	readmeResp, err := registryGet(readmeURL, token)
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -100,8 +107,9 @@ func fetchCratesIO(pkgPath string) (string, []source.RelatedReference, error) {
 			failure.Message("README not found"),
 			failure.Context{
 				"pkg": pkgPath,
-				"url": readmeURL,
+				"url": redactURL(readmeURL),
 			},
+			httpStatus(readmeResp.StatusCode),
 		)
 	}
 
@@ -215,6 +223,24 @@ func fetchCratesIO(pkgPath string) (string, []source.RelatedReference, error) {
 	return doc, sources, nil
 }
 
+// cratesIORegistration declares the crates.io source
+var cratesIORegistration = investigator.Registration{
+	Type:         source.TypeCratesIO,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"rust", "rs", "crates"},
+	Hosts:        []string{"crates.io"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?crates\.io/crates/([^/\s]+)`),
+			Command:     regexp.MustCompile(`cargo add ([^@\s]+)`),
+			Description: "Cargo package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &CratesIOInvestigator{}
+	},
+}
+
 // Implementation of CratesIO Investigator
 type CratesIOInvestigator struct{}
 
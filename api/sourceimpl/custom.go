@@ -0,0 +1,425 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/config"
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/log"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrCustomSourceNotFound represents an error when the package is not found in a custom source
+	ErrCustomSourceNotFound ErrorCode = "CustomSourceNotFound"
+	// ErrInvalidCustomSource represents an error when a custom source in the config file is invalid
+	ErrInvalidCustomSource ErrorCode = "InvalidCustomSource"
+	// ErrInvalidSelector represents an error when a JSONPath-style selector cannot be parsed
+	ErrInvalidSelector ErrorCode = "InvalidSelector"
+)
+
+// Implementation of an investigator for a custom source declared in the config file
+type CustomInvestigator struct {
+	Name         string
+	Type         source.Type
+	Capabilities source.Capability
+	Aliases      []string
+	APIURL       string
+	Headers      map[string]string
+	BrowserURL   string
+	URLPattern   *regexp.Regexp
+	Selectors    config.Selectors
+}
+
+// loadCustomSources registers the custom sources declared in the config file
+// Invalid sources are skipped with a warning.
+func loadCustomSources() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Logger.Warn("Failed to load config file", "path", config.Path(), "error", err.Error())
+		return
+	}
+	for _, sc := range cfg.Sources {
+		c, err := newCustomInvestigator(sc)
+		if err == nil {
+			err = investigator.Register(c.registration())
+		}
+		if err != nil {
+			log.Logger.Warn("Skipping custom source", "name", sc.Name, "error", err.Error())
+		}
+	}
+}
+
+// newCustomInvestigator creates the investigator from a source declared in the config file
+func newCustomInvestigator(sc config.Source) (*CustomInvestigator, error) {
+	if sc.Name == "" || sc.APIURL == "" {
+		return nil, failure.New(ErrInvalidCustomSource,
+			failure.Message("Custom source requires name and api_url"),
+			failure.Context{"name": sc.Name},
+		)
+	}
+
+	capabilities, err := capabilitiesFromKind(sc.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CustomInvestigator{
+		Name:         sc.Name,
+		Type:         source.Type(sc.Type),
+		Capabilities: capabilities,
+		Aliases:      sc.Aliases,
+		APIURL:       sc.APIURL,
+		Headers:      sc.Headers,
+		BrowserURL:   sc.BrowserURL,
+		Selectors:    sc.Selectors,
+	}
+	if c.Type == source.TypeUnknown {
+		c.Type = source.Type(sc.Name)
+	}
+	if len(c.Aliases) == 0 {
+		c.Aliases = []string{sc.Name}
+	}
+	if sc.URLPattern != "" {
+		re, err := regexp.Compile(sc.URLPattern)
+		if err != nil {
+			return nil, failure.Wrap(err, failure.Context{"pattern": sc.URLPattern})
+		}
+		c.URLPattern = re
+	}
+
+	// Validate selectors early so that typos are reported when the config is loaded
+	for _, sel := range []string{sc.Selectors.README, sc.Selectors.READMEURL, sc.Selectors.Description,
+		sc.Selectors.Version, sc.Selectors.Homepage, sc.Selectors.Repository} {
+		if _, err := parseSelector(sel); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// parseSelector parses a JSONPath-style selector into keys and array indexes
+// Supported syntax: $.key.nested, $.list[0], $['key with dots']. The leading "$" is optional.
+func parseSelector(sel string) ([]any, error) {
+	invalid := func() error {
+		return failure.New(ErrInvalidSelector,
+			failure.Message("Invalid selector"),
+			failure.Context{"selector": sel},
+		)
+	}
+
+	var steps []any
+	s := strings.TrimPrefix(strings.TrimSpace(sel), "$")
+	for s != "" {
+		switch {
+		case s[0] == '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end == -1 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, invalid()
+			}
+			steps = append(steps, s[:end])
+			s = s[end:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, invalid()
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, inner[1:len(inner)-1])
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, invalid()
+			}
+			steps = append(steps, n)
+		case len(steps) == 0:
+			// Allow a selector without the leading "$."
+			s = "." + s
+		default:
+			return nil, invalid()
+		}
+	}
+	return steps, nil
+}
+
+// selectJSON returns the string value selected from the decoded JSON document
+// Numbers and booleans are formatted, and missing values or objects result in an empty string.
+func selectJSON(doc any, sel string) string {
+	if sel == "" {
+		return ""
+	}
+	steps, err := parseSelector(sel)
+	if err != nil {
+		return ""
+	}
+
+	v := doc
+	for _, step := range steps {
+		switch step := step.(type) {
+		case string:
+			m, ok := v.(map[string]any)
+			if !ok {
+				return ""
+			}
+			v = m[step]
+		case int:
+			a, ok := v.([]any)
+			if step < 0 {
+				step += len(a)
+			}
+			if !ok || step < 0 || step >= len(a) {
+				return ""
+			}
+			v = a[step]
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// expandTemplate replaces "{package}" in the URL template with the package path
+func expandTemplate(tmpl, packagePath string) string {
+	return strings.ReplaceAll(tmpl, "{package}", packagePath)
+}
+
+// get sends a GET request with the configured headers
+func (i *CustomInvestigator) get(rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	for k, v := range i.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	return resp, nil
+}
+
+// fetchCustom fetches the package information from the API of the custom source
+// Returns the content, related sources, and any error
+func (i *CustomInvestigator) fetchCustom(pkgPath string) (string, []source.RelatedReference, error) {
+	apiURL := expandTemplate(i.APIURL, pkgPath)
+	resp, err := i.get(apiURL)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrCustomSourceNotFound,
+			failure.Message(fmt.Sprintf("Failed to fetch package from %s", i.Name)),
+			failure.Context{
+				"pkg":    pkgPath,
+				"url":    apiURL,
+				"status": resp.Status,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var doc any
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	readme := selectJSON(doc, i.Selectors.README)
+	if readme == "" {
+		if readmeURL := selectJSON(doc, i.Selectors.READMEURL); readmeURL != "" {
+			readme, err = i.fetchReadme(apiURL, readmeURL)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	description := selectJSON(doc, i.Selectors.Description)
+	version := selectJSON(doc, i.Selectors.Version)
+	homepage := selectJSON(doc, i.Selectors.Homepage)
+	repository := selectJSON(doc, i.Selectors.Repository)
+
+	// Format the document
+	var sections []string
+	title := "# " + pkgPath
+	if version != "" {
+		title += " v" + version
+	}
+	sections = append(sections, title)
+	if description != "" {
+		sections = append(sections, description)
+	}
+	var links []string
+	if homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", homepage))
+	}
+	if repository != "" {
+		links = append(links, fmt.Sprintf("**Repository:** %s", repository))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+	if readme != "" {
+		sections = append(sections, readme)
+	}
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add repository if available
+	if repository != "" {
+		detected := source.DetectSourceTypeFromURL(repository)
+		if detected.IsRepository() {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(repository, detected),
+				From: "api",
+			})
+		}
+	}
+
+	// Add homepage if available and different from the repository
+	if homepage != "" && homepage != repository {
+		detected := source.DetectSourceTypeFromURL(homepage)
+		if detected != source.TypeUnknown {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(homepage, detected),
+				From: "api",
+			})
+		} else {
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Extract additional sources from README content
+	sources = append(sources, extractRelatedSources(readme, pkgPath)...)
+
+	return strings.Join(sections, "\n\n"), sources, nil
+}
+
+// fetchReadme fetches the README from the URL selected from the API response
+// Relative URLs are resolved against the API URL.
+func (i *CustomInvestigator) fetchReadme(apiURL, readmeURL string) (string, error) {
+	base, err := url.Parse(apiURL)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	ref, err := base.Parse(readmeURL)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+
+	resp, err := i.get(ref.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", failure.New(ErrCustomSourceNotFound,
+			failure.Message(fmt.Sprintf("Failed to fetch README from %s", i.Name)),
+			failure.Context{
+				"url":    ref.String(),
+				"status": resp.Status,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	return string(b), nil
+}
+
+// registration declares the custom source
+func (i *CustomInvestigator) registration() investigator.Registration {
+	r := investigator.Registration{
+		Type:         i.Type,
+		Capabilities: i.Capabilities,
+		Aliases:      i.Aliases,
+		New: func() investigator.SourceInvestigator {
+			return i
+		},
+	}
+	if i.URLPattern != nil {
+		r.Patterns = []investigator.Pattern{{
+			URL:         i.URLPattern,
+			Description: fmt.Sprintf("%s package reference", i.Name),
+		}}
+	}
+	return r
+}
+
+func (i *CustomInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the custom source API
+	content, relatedSources, err := i.fetchCustom(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *CustomInvestigator) GetURL(packagePath string) string {
+	if i.BrowserURL == "" {
+		return ""
+	}
+	return expandTemplate(i.BrowserURL, packagePath)
+}
+
+func (i *CustomInvestigator) GetSourceType() source.Type {
+	return i.Type
+}
+
+func (i *CustomInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path with the URL pattern declared in the config file
+	if i.URLPattern != nil {
+		if matches := i.URLPattern.FindStringSubmatch(url); len(matches) > 1 {
+			return matches[1], nil
+		}
+	}
+	return "", failure.New(ErrInvalidPackagePath,
+		failure.Message(fmt.Sprintf("URL does not match the url_pattern of %s", i.Name)),
+		failure.Context{"url": url},
+	)
+}
@@ -0,0 +1,193 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/config"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+func TestCustomInvestigator(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/packages/widget", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{
+			"package": {"summary": "A widget", "links": {"homepage": "https://widget.example.com", "source": "https://github.com/example/widget"}},
+			"versions": [{"number": "1.2.0", "readme": "/files/widget/README.md"}]
+		}`)
+	})
+	mux.HandleFunc("/files/widget/README.md", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Install with\n\n    pip install widget\n")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("INTERNAL_TOKEN", "secret")
+
+	cfg, err := config.Parse([]byte(fmt.Sprintf(`
+sources:
+  - name: internal
+    type: registry.internal.example.com
+    aliases: [int]
+    api_url: %s/api/packages/{package}
+    headers:
+      Authorization: Bearer ${INTERNAL_TOKEN}
+    browser_url: https://registry.internal.example.com/packages/{package}
+    url_pattern: https?://registry\.internal\.example\.com/packages/([^/\s]+)
+    selectors:
+      readme_url: $.versions[0].readme
+      description: $.package.summary
+      version: $.versions[0].number
+      homepage: $.package.links.homepage
+      repository: $['package'].links.source
+`, server.URL)))
+	if err != nil {
+		t.Fatalf("config.Parse() error = %v", err)
+	}
+	if len(cfg.Sources) != 1 {
+		t.Fatalf("config.Parse() found %d sources, want 1", len(cfg.Sources))
+	}
+
+	c, err := newCustomInvestigator(cfg.Sources[0])
+	if err != nil {
+		t.Fatalf("newCustomInvestigator() error = %v", err)
+	}
+	if got := c.GetSourceType(); got != source.Type("registry.internal.example.com") {
+		t.Errorf("GetSourceType() = %s", got)
+	}
+
+	data, err := c.Fetch("widget")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	wantDoc := "# widget v1.2.0\n\n" +
+		"A widget\n\n" +
+		"**Homepage:** https://widget.example.com\n" +
+		"**Repository:** https://github.com/example/widget\n\n" +
+		"Install with\n\n    pip install widget\n"
+	if diff := cmp.Diff(wantDoc, data.Contents["README.md"]); diff != "" {
+		t.Errorf("Fetch() doc mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeGitHub, URL: "https://github.com/example/widget", From: "api"},
+		{Type: source.TypeHomepage, URL: "https://widget.example.com", From: "api"},
+		{Type: source.TypePyPI, Path: "widget", From: "document"},
+	}
+	if diff := cmp.Diff(wantSources, data.RelatedSources); diff != "" {
+		t.Errorf("Fetch() sources mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := data.BrowserURL.String(); got != "https://registry.internal.example.com/packages/widget" {
+		t.Errorf("Fetch() browser URL = %s", got)
+	}
+
+	pkg, err := c.PackageFromURL("https://registry.internal.example.com/packages/widget")
+	if err != nil || pkg != "widget" {
+		t.Errorf("PackageFromURL() = %q, %v", pkg, err)
+	}
+
+	if _, err := c.PackageFromURL("https://example.com/packages/widget"); !failure.Is(err, ErrInvalidPackagePath) {
+		t.Errorf("PackageFromURL() error = %v, want %s for a URL not matching url_pattern", err, ErrInvalidPackagePath)
+	}
+
+	if _, err := c.Fetch("missing"); err == nil {
+		t.Error("Fetch() expected error for unknown package")
+	}
+}
+
+func TestCustomInvestigatorReadmeError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/packages/widget", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"readme": "/files/widget/README.md"}`)
+	})
+	mux.HandleFunc("/files/widget/README.md", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := newCustomInvestigator(config.Source{
+		Name:      "internal",
+		APIURL:    server.URL + "/api/packages/{package}",
+		Selectors: config.Selectors{READMEURL: "$.readme"},
+	})
+	if err != nil {
+		t.Fatalf("newCustomInvestigator() error = %v", err)
+	}
+
+	// The fetch fails rather than caching the page without the README
+	_, err = c.Fetch("widget")
+	if !failure.Is(err, ErrCustomSourceNotFound) {
+		t.Fatalf("Fetch() error = %v, want %s", err, ErrCustomSourceNotFound)
+	}
+	if got, ok := httpStatusOf(err); !ok || got != http.StatusForbidden {
+		t.Errorf("httpStatusOf() = %d, %v, want %d", got, ok, http.StatusForbidden)
+	}
+}
+
+func TestNewCustomInvestigatorInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		src  config.Source
+	}{
+		{"missing api_url", config.Source{Name: "internal"}},
+		{"invalid url_pattern", config.Source{Name: "internal", APIURL: "https://example.com/{package}", URLPattern: "("}},
+		{"invalid selector", config.Source{Name: "internal", APIURL: "https://example.com/{package}", Selectors: config.Selectors{README: "$.versions[latest]"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newCustomInvestigator(tt.src); err == nil {
+				t.Error("newCustomInvestigator() expected error")
+			}
+		})
+	}
+}
+
+func TestSelectJSON(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"name": "widget",
+		"info": {"version": "1.2.0", "downloads": 1500, "deprecated": false, "urls": {"docs.site": "https://docs.example.com"}},
+		"versions": [{"number": "1.0.0"}, {"number": "1.2.0"}]
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"$.name", "widget"},
+		{"name", "widget"},
+		{"info.version", "1.2.0"},
+		{"$.info.downloads", "1500"},
+		{"$.info.deprecated", "false"},
+		{"$.info.urls['docs.site']", "https://docs.example.com"},
+		{`$["info"]["version"]`, "1.2.0"},
+		{"$.versions[0].number", "1.0.0"},
+		{"$.versions[-1].number", "1.2.0"},
+		{"$.versions[5].number", ""},
+		{"$.info", ""},
+		{"$.missing.key", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			if got := selectJSON(doc, tt.selector); got != tt.want {
+				t.Errorf("selectJSON(%q) = %q, want %q", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
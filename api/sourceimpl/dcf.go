@@ -0,0 +1,95 @@
+package sourceimpl
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// parseDCF parses a Debian Control File (DCF) formatted document into paragraphs
+// DCF is used by R DESCRIPTION files and Debian package indexes.
+// Each paragraph maps field names to values; continuation lines are joined with newlines.
+func parseDCF(r io.Reader) ([]map[string]string, error) {
+	var paragraphs []map[string]string
+	err := scanDCF(r, func(paragraph map[string]string) bool {
+		paragraphs = append(paragraphs, paragraph)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paragraphs, nil
+}
+
+// scanDCF calls fn for each paragraph of a DCF formatted document until fn returns false
+// Unlike parseDCF it does not keep the paragraphs, so large package indexes can be searched.
+func scanDCF(r io.Reader, fn func(map[string]string) bool) error {
+	current := map[string]string{}
+	var field string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// Blank lines separate paragraphs
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				if !fn(current) {
+					return nil
+				}
+				current = map[string]string{}
+			}
+			field = ""
+			continue
+		}
+
+		// Continuation lines start with whitespace
+		if line[0] == ' ' || line[0] == '\t' {
+			if field == "" {
+				continue
+			}
+			value := strings.TrimSpace(line)
+			// A lone "." represents an empty line in Debian descriptions
+			if value == "." {
+				value = ""
+			}
+			if current[field] == "" {
+				current[field] = value
+			} else {
+				current[field] += "\n" + value
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.TrimSpace(name)
+		current[field] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(current) > 0 {
+		fn(current)
+	}
+
+	return nil
+}
+
+// splitDCFList splits a comma separated DCF field such as "Imports" or "URL"
+// Version constraints in parentheses are kept with their entries.
+func splitDCFList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		// Entries may be wrapped onto continuation lines
+		item = strings.Join(strings.Fields(item), " ")
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,201 @@
+package sourceimpl
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// debianReleases are the suites searched by default, oldest first
+var debianReleases = []string{"oldstable", "stable", "testing", "unstable"}
+
+// debianComponents are the archive components searched in each suite
+var debianComponents = []string{"main", "contrib", "non-free"}
+
+// findDebianSource finds the source package building the binary package in a Debian Sources index
+// The Sources index lists the packages of all architectures. A source package of the name is
+// returned when no source package builds a binary package of the name.
+func findDebianSource(r io.Reader, name string) (map[string]string, error) {
+	var found map[string]string
+	err := scanDCF(r, func(fields map[string]string) bool {
+		if slices.Contains(splitDCFList(fields["Binary"]), name) {
+			found = fields
+			return false
+		}
+		if found == nil && fields["Package"] == name {
+			found = fields
+		}
+		return true
+	})
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	return found, nil
+}
+
+// findDebianDescription finds the description of the binary package in a Debian Translation-en index
+func findDebianDescription(r io.Reader, name string) (string, error) {
+	var description string
+	err := scanDCF(r, func(fields map[string]string) bool {
+		if fields["Package"] == name {
+			description = fields["Description-en"]
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	return description, nil
+}
+
+// newDebianPackage creates a distroPackage of the binary package from the fields of a Sources index entry
+// The upstream URL is the homepage, or the repository of the Debian packaging if the homepage is unknown.
+func newDebianPackage(name string, fields map[string]string, release, component string) distroPackage {
+	pkg := distroPackage{
+		Name:       name,
+		Release:    release,
+		Repository: component,
+		Version:    fields["Version"],
+		URL:        fields["Homepage"],
+	}
+	if pkg.URL == "" {
+		pkg.URL = fields["Vcs-Browser"]
+	}
+	return pkg
+}
+
+// fetchDebian fetches the package information from the Sources index of each Debian suite
+// The description of the newest suite is read from its English translations.
+// Returns the content, related sources, and any error
+func fetchDebian(pkgPath string) (string, []source.RelatedReference, error) {
+	mirror := distroMirror("MIRU_DEBIAN_MIRROR", "https://deb.debian.org/debian")
+
+	var pkgs []distroPackage
+	for _, release := range distroReleases("MIRU_DEBIAN_RELEASES", debianReleases) {
+		for _, component := range debianComponents {
+			f, err := openMirrorIndex(mirror, fmt.Sprintf("dists/%s/%s/source/Sources", release, component))
+			if err != nil {
+				return "", nil, err
+			}
+			if f == nil {
+				continue
+			}
+			fields, err := findDebianSource(f, pkgPath)
+			f.Close()
+			if err != nil {
+				return "", nil, err
+			}
+			if fields == nil {
+				continue
+			}
+
+			pkgs = append(pkgs, newDebianPackage(pkgPath, fields, release, component))
+			break
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return "", nil, failure.New(ErrDistroPackageNotFound,
+			failure.Message("Package not found in any Debian suite"),
+			failure.Context{
+				"pkg":    pkgPath,
+				"mirror": mirror,
+			},
+		)
+	}
+
+	// Only the description of the newest suite is shown
+	latest := &pkgs[len(pkgs)-1]
+	f, err := openMirrorIndex(mirror, fmt.Sprintf("dists/%s/%s/i18n/Translation-en", latest.Release, latest.Repository))
+	if err != nil {
+		return "", nil, err
+	}
+	if f != nil {
+		description, err := findDebianDescription(f, pkgPath)
+		f.Close()
+		if err != nil {
+			return "", nil, err
+		}
+		// The first line is the synopsis and the rest is the extended description
+		latest.Description, latest.Details, _ = strings.Cut(description, "\n")
+	}
+
+	return formatDistroPackageDoc(pkgs), distroSources(pkgs), nil
+}
+
+// debianRegistration declares the Debian source
+var debianRegistration = investigator.Registration{
+	Type:         source.TypeDebian,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"deb", "debian", "apt"},
+	Hosts:        []string{"packages.debian.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://packages\.debian\.org/[a-z-]+/([^/\s)?]+)`),
+			Command:     regexp.MustCompile(`apt(?:-get)? install (?:-y )?([^-\s][^\s]*)`),
+			Description: "Debian package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &DebianInvestigator{}
+	},
+}
+
+// Implementation of Debian Investigator
+type DebianInvestigator struct{}
+
+func (i *DebianInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the Debian mirror
+	content, relatedSources, err := fetchDebian(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *DebianInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://packages.debian.org/search?exact=1&searchon=names&keywords=%s", url.QueryEscape(packagePath))
+}
+
+func (i *DebianInvestigator) GetSourceType() source.Type {
+	return source.TypeDebian
+}
+
+func (i *DebianInvestigator) PackageFromURL(rawURL string) (string, error) {
+	// Extract package path from Debian packages URL
+	// Example: https://packages.debian.org/stable/curl -> curl
+	// Example: https://packages.debian.org/search?keywords=curl -> curl
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "packages.debian.org" {
+		return rawURL, nil
+	}
+	if name := u.Query().Get("keywords"); name != "" {
+		return name, nil
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if name := parts[len(parts)-1]; len(parts) >= 2 && name != "" {
+		return name, nil
+	}
+	return "", failure.New(ErrInvalidPackagePath,
+		failure.Message("Invalid Debian package path"),
+		failure.Context{"url": rawURL},
+	)
+}
@@ -0,0 +1,229 @@
+package sourceimpl
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrDistroPackageNotFound represents an error when the package is not found in any release of the distribution
+	ErrDistroPackageNotFound ErrorCode = "DistroPackageNotFound"
+)
+
+// distroPackage represents a package of a Linux distribution in one release
+type distroPackage struct {
+	Name        string
+	Release     string
+	Repository  string
+	Version     string
+	Description string
+	Details     string
+	URL         string
+	License     string
+	Depends     []string
+}
+
+// distroMirror returns the mirror base URL from the environment variable, or the default
+// The mirror may be an http(s) URL or a local directory with the same layout as the mirror.
+func distroMirror(env, defaultURL string) string {
+	if v := os.Getenv(env); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return defaultURL
+}
+
+// distroReleases returns the comma separated releases from the environment variable, or the defaults
+func distroReleases(env string, defaults []string) []string {
+	v := os.Getenv(env)
+	if v == "" {
+		return defaults
+	}
+	var releases []string
+	for _, r := range strings.Split(v, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			releases = append(releases, r)
+		}
+	}
+	return releases
+}
+
+// openMirrorFile opens a file relative to the mirror base
+// Returns nil without error if the file does not exist on the mirror.
+func openMirrorFile(base, name string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(base, "https://") && !strings.HasPrefix(base, "http://") {
+		f, err := os.Open(filepath.Join(strings.TrimPrefix(base, "file://"), filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		return f, nil
+	}
+
+	fileURL := base + "/" + name
+	resp, err := http.Get(fileURL)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch file from the mirror"),
+			failure.Context{
+				"url":    fileURL,
+				"status": resp.Status,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+	return resp.Body, nil
+}
+
+// decompressReadCloser closes both the decompressing reader and the underlying file
+type decompressReadCloser struct {
+	io.Reader
+	file io.Closer
+}
+
+func (d decompressReadCloser) Close() error {
+	if c, ok := d.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return d.file.Close()
+}
+
+// openMirrorIndex opens a package index on the mirror, preferring the compressed variants
+// Debian publishes package indexes gzip compressed and translations bzip2 compressed, and
+// local mirrors often keep the uncompressed index only, so "<name>.gz", "<name>.bz2" and "<name>" are tried in order.
+// Returns nil without error if none exists.
+func openMirrorIndex(base, name string) (io.ReadCloser, error) {
+	f, err := openMirrorFile(base, name+".gz")
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, failure.Wrap(err)
+		}
+		return decompressReadCloser{Reader: gz, file: f}, nil
+	}
+
+	f, err = openMirrorFile(base, name+".bz2")
+	if err != nil {
+		return nil, err
+	}
+	if f != nil {
+		return decompressReadCloser{Reader: bzip2.NewReader(f), file: f}, nil
+	}
+
+	return openMirrorFile(base, name)
+}
+
+// distroSources extracts the upstream URL of the packages as related sources
+func distroSources(pkgs []distroPackage) []source.RelatedReference {
+	var sources []source.RelatedReference
+	seen := map[string]bool{}
+	for _, pkg := range pkgs {
+		if pkg.URL == "" || seen[pkg.URL] {
+			continue
+		}
+		seen[pkg.URL] = true
+
+		detected := source.DetectSourceTypeFromURL(pkg.URL)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(pkg.URL, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  pkg.URL,
+				From: "api",
+			})
+		}
+	}
+	return sources
+}
+
+// formatDistroPackageDoc formats the package found in each release into a markdown document
+// Releases are listed oldest first, so the last package is used for the description and other details.
+func formatDistroPackageDoc(pkgs []distroPackage) string {
+	var sections []string
+	pkg := pkgs[len(pkgs)-1]
+
+	// Title
+	sections = append(sections, fmt.Sprintf("# %s", pkg.Name))
+
+	// Description
+	if pkg.Description != "" {
+		sections = append(sections, pkg.Description)
+	}
+
+	// Metadata
+	var metadata []string
+	if pkg.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", pkg.License))
+	}
+	if pkg.URL != "" {
+		metadata = append(metadata, fmt.Sprintf("**Upstream:** %s", pkg.URL))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Versions across releases
+	// Rolling releases have no separate repositories, so the column is omitted
+	withRepository := slices.ContainsFunc(pkgs, func(p distroPackage) bool {
+		return p.Repository != ""
+	})
+	rows := []string{"## Versions", ""}
+	if withRepository {
+		rows = append(rows, "| Release | Repository | Version |", "|---------|------------|---------|")
+	} else {
+		rows = append(rows, "| Release | Version |", "|---------|---------|")
+	}
+	for _, p := range pkgs {
+		if withRepository {
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s |", p.Release, p.Repository, p.Version))
+		} else {
+			rows = append(rows, fmt.Sprintf("| %s | %s |", p.Release, p.Version))
+		}
+	}
+	sections = append(sections, strings.Join(rows, "\n"))
+
+	// Dependencies
+	if len(pkg.Depends) > 0 {
+		sections = append(sections, fmt.Sprintf("**Dependencies:** %s", strings.Join(pkg.Depends, ", ")))
+	}
+
+	// Long description
+	if pkg.Details != "" {
+		sections = append(sections, pkg.Details)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,241 @@
+package sourceimpl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+// writeMirrorFile writes a file into the local mirror directory
+func writeMirrorFile(t *testing.T, dir, name string, content []byte) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tarGz creates a gzip compressed tar archive of the files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestFetchAlpineLocalMirror(t *testing.T) {
+	dir := t.TempDir()
+	writeMirrorFile(t, dir, "v3.20/main/x86_64/APKINDEX.tar.gz", tarGz(t, map[string]string{
+		"DESCRIPTION": "v3.20.0\n",
+		"APKINDEX": "P:musl\nV:1.2.5-r0\nT:the musl c library\n\n" +
+			"P:openssl\nV:3.3.1-r0\nT:Toolkit for Transport Layer Security (TLS)\nU:https://www.openssl.org/\nL:Apache-2.0\nD:so:libc.musl-x86_64.so.1 so:libcrypto.so.3\n\n",
+	}))
+	writeMirrorFile(t, dir, "edge/main/x86_64/APKINDEX.tar.gz", tarGz(t, map[string]string{
+		"APKINDEX": "P:openssl\nV:3.3.2-r1\nT:Toolkit for Transport Layer Security (TLS)\nU:https://www.openssl.org/\nL:Apache-2.0\n",
+	}))
+	t.Setenv("MIRU_ALPINE_MIRROR", dir)
+	t.Setenv("MIRU_ALPINE_RELEASES", "v3.20,edge")
+
+	doc, sources, err := fetchAlpine("openssl")
+	if err != nil {
+		t.Fatalf("fetchAlpine() error = %v", err)
+	}
+
+	wantDoc := "# openssl\n\n" +
+		"Toolkit for Transport Layer Security (TLS)\n\n" +
+		"**License:** Apache-2.0 • **Upstream:** https://www.openssl.org/\n\n" +
+		"## Versions\n\n" +
+		"| Release | Repository | Version |\n" +
+		"|---------|------------|---------|\n" +
+		"| v3.20 | main | 3.3.1-r0 |\n" +
+		"| edge | main | 3.3.2-r1 |"
+	if diff := cmp.Diff(wantDoc, doc); diff != "" {
+		t.Errorf("fetchAlpine() doc mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeHomepage, URL: "https://www.openssl.org/", From: "api"},
+	}
+	if diff := cmp.Diff(wantSources, sources); diff != "" {
+		t.Errorf("fetchAlpine() sources mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := fetchAlpine("not-a-package"); err == nil {
+		t.Error("fetchAlpine() expected error for unknown package")
+	}
+}
+
+func TestFetchDebianLocalMirror(t *testing.T) {
+	dir := t.TempDir()
+	writeMirrorFile(t, dir, "dists/bookworm/main/source/Sources", []byte(
+		"Package: curl\nBinary: curl, libcurl4, libcurl4-openssl-dev\nVersion: 7.88.1-10+deb12u7\nHomepage: https://curl.se/\n"))
+	var sourcesGz bytes.Buffer
+	gz := gzip.NewWriter(&sourcesGz)
+	gz.Write([]byte("Package: curl-helper\nBinary: curl-helper\nVersion: 1.0-1\n\n" +
+		"Package: curl\nBinary: curl,\n libcurl4t64\nVersion: 8.10.1-2\nHomepage: https://curl.se/\nVcs-Browser: https://salsa.debian.org/debian/curl\n"))
+	gz.Close()
+	writeMirrorFile(t, dir, "dists/sid/main/source/Sources.gz", sourcesGz.Bytes())
+	writeMirrorFile(t, dir, "dists/sid/main/i18n/Translation-en", []byte(
+		"Package: curl\nDescription-md5: 0123456789abcdef\n"+
+			"Description-en: command line tool for transferring data with URL syntax\n curl is a command line tool.\n .\n It supports many protocols.\n"))
+	t.Setenv("MIRU_DEBIAN_MIRROR", "file://"+dir)
+	t.Setenv("MIRU_DEBIAN_RELEASES", "bookworm,trixie,sid")
+
+	tests := []struct {
+		name    string
+		pkg     string
+		wantDoc string
+	}{
+		{
+			name: "Binary package",
+			pkg:  "curl",
+			wantDoc: "# curl\n\n" +
+				"command line tool for transferring data with URL syntax\n\n" +
+				"**Upstream:** https://curl.se/\n\n" +
+				"## Versions\n\n" +
+				"| Release | Repository | Version |\n" +
+				"|---------|------------|---------|\n" +
+				"| bookworm | main | 7.88.1-10+deb12u7 |\n" +
+				"| sid | main | 8.10.1-2 |\n\n" +
+				"curl is a command line tool.\n\nIt supports many protocols.",
+		},
+		{
+			name: "Binary package built by another source package",
+			pkg:  "libcurl4t64",
+			wantDoc: "# libcurl4t64\n\n" +
+				"**Upstream:** https://curl.se/\n\n" +
+				"## Versions\n\n" +
+				"| Release | Repository | Version |\n" +
+				"|---------|------------|---------|\n" +
+				"| sid | main | 8.10.1-2 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, sources, err := fetchDebian(tt.pkg)
+			if err != nil {
+				t.Fatalf("fetchDebian() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantDoc, doc); diff != "" {
+				t.Errorf("fetchDebian() doc mismatch (-want +got):\n%s", diff)
+			}
+
+			wantSources := []source.RelatedReference{
+				{Type: source.TypeHomepage, URL: "https://curl.se/", From: "api"},
+			}
+			if diff := cmp.Diff(wantSources, sources); diff != "" {
+				t.Errorf("fetchDebian() sources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, _, err := fetchDebian("wget"); err == nil {
+		t.Error("fetchDebian() error = nil for a missing package")
+	}
+}
+
+func TestNewDebianPackage(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   distroPackage
+	}{
+		{
+			name: "Homepage",
+			fields: map[string]string{
+				"Package":     "curl",
+				"Binary":      "curl, libcurl4",
+				"Version":     "7.88.1-10+deb12u7",
+				"Homepage":    "https://curl.se/",
+				"Vcs-Browser": "https://salsa.debian.org/debian/curl",
+			},
+			want: distroPackage{
+				Name:       "libcurl4",
+				Release:    "bookworm",
+				Repository: "main",
+				Version:    "7.88.1-10+deb12u7",
+				URL:        "https://curl.se/",
+			},
+		},
+		{
+			name: "Packaging repository without homepage",
+			fields: map[string]string{
+				"Package":     "curl",
+				"Version":     "7.88.1-10+deb12u7",
+				"Vcs-Browser": "https://salsa.debian.org/debian/curl",
+			},
+			want: distroPackage{
+				Name:       "libcurl4",
+				Release:    "bookworm",
+				Repository: "main",
+				Version:    "7.88.1-10+deb12u7",
+				URL:        "https://salsa.debian.org/debian/curl",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, newDebianPackage("libcurl4", tt.fields, "bookworm", "main")); diff != "" {
+				t.Errorf("newDebianPackage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchArchLocalMirror(t *testing.T) {
+	dir := t.TempDir()
+	writeMirrorFile(t, dir, "extra/os/x86_64/extra.db", tarGz(t, map[string]string{
+		"ripgrep-all-0.10.6-1/desc": "%NAME%\nripgrep-all\n\n%VERSION%\n0.10.6-1\n",
+		"ripgrep-14.1.1-1/desc": "%FILENAME%\nripgrep-14.1.1-1-x86_64.pkg.tar.zst\n\n%NAME%\nripgrep\n\n%VERSION%\n14.1.1-1\n\n" +
+			"%DESC%\nA search tool that combines the usability of ag with the raw speed of grep\n\n" +
+			"%URL%\nhttps://github.com/BurntSushi/ripgrep\n\n%LICENSE%\nMIT\nUnlicense\n\n%DEPENDS%\ngcc-libs\nglibc\npcre2\n",
+	}))
+	t.Setenv("MIRU_ARCH_MIRROR", dir)
+	t.Setenv("MIRU_ARCH_REPOS", "core,extra")
+
+	doc, sources, err := fetchArch("ripgrep")
+	if err != nil {
+		t.Fatalf("fetchArch() error = %v", err)
+	}
+
+	wantDoc := "# ripgrep\n\n" +
+		"A search tool that combines the usability of ag with the raw speed of grep\n\n" +
+		"**License:** MIT, Unlicense • **Upstream:** https://github.com/BurntSushi/ripgrep\n\n" +
+		"## Versions\n\n" +
+		"| Release | Version |\n" +
+		"|---------|---------|\n" +
+		"| extra | 14.1.1-1 |\n\n" +
+		"**Dependencies:** gcc-libs, glibc, pcre2"
+	if diff := cmp.Diff(wantDoc, doc); diff != "" {
+		t.Errorf("fetchArch() doc mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeGitHub, URL: "https://github.com/BurntSushi/ripgrep", From: "api"},
+	}
+	if diff := cmp.Diff(wantSources, sources); diff != "" {
+		t.Errorf("fetchArch() sources mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,221 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrDockerImageNotFound represents an error when the image repository is not found in the registry
+	ErrDockerImageNotFound ErrorCode = "DockerImageNotFound"
+)
+
+// dockerHubRepository represents the Docker Hub API response for an image repository
+type dockerHubRepository struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Description     string `json:"description"`
+	FullDescription string `json:"full_description"`
+	StarCount       int    `json:"star_count"`
+	PullCount       int    `json:"pull_count"`
+	LastUpdated     string `json:"last_updated"`
+}
+
+// dockerHubTags represents the Docker Hub API response for the tags of an image repository
+type dockerHubTags struct {
+	Results []struct {
+		Name        string `json:"name"`
+		LastUpdated string `json:"last_updated"`
+	} `json:"results"`
+}
+
+// splitDockerImage splits an image reference into the registry host, namespace and repository name
+// The registry is empty for Docker Hub, where official images live in the "library" namespace.
+// Like Docker, the first component is taken as the registry when it contains "." or ":" or is "localhost".
+// Example: nginx:1.25 -> "", library, nginx
+// Example: docker.io/bitnami/redis@sha256:... -> "", bitnami, redis
+// Example: ghcr.io/owner/image:latest -> ghcr.io, owner, image
+func splitDockerImage(image string) (string, string, string) {
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx != -1 && !strings.Contains(image[idx:], "/") {
+		image = image[:idx]
+	}
+
+	var registry string
+	if first, rest, ok := strings.Cut(image, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, image = first, rest
+	}
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		registry = ""
+	}
+
+	namespace, name, ok := strings.Cut(image, "/")
+	if !ok {
+		if registry != "" {
+			return registry, "", image
+		}
+		return "", "library", image
+	}
+	return registry, namespace, name
+}
+
+// fetchDockerHub fetches the repository description and recent tags from Docker Hub
+// Returns the content, related sources, and any error
+func fetchDockerHub(pkgPath string) (string, []source.RelatedReference, error) {
+	registry, namespace, name := splitDockerImage(pkgPath)
+	if registry != "" {
+		return "", nil, failure.New(ErrDockerImageNotFound,
+			failure.Message("The image is hosted on a registry other than Docker Hub"),
+			failure.Context{
+				"pkg":      pkgPath,
+				"registry": registry,
+			},
+		)
+	}
+
+	// Get repository information from Docker Hub API
+	repoURL := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/%s/", namespace, name)
+	resp, err := http.Get(repoURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrDockerImageNotFound,
+			failure.Message("Failed to fetch image repository from hub.docker.com"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var repo dockerHubRepository
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	// Get the most recently pushed tags
+	tagsURL := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/%s/tags?page_size=25&ordering=last_updated", namespace, name)
+	// Tags are supplementary, so the document is returned without them when they can't be fetched
+	var tags dockerHubTags
+	if tagsResp, err := http.Get(tagsURL); err == nil {
+		defer tagsResp.Body.Close()
+		if tagsResp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(tagsResp.Body).Decode(&tags); err != nil {
+				tags = dockerHubTags{}
+			}
+		}
+	}
+
+	// Format the documentation text
+	var sections []string
+	sections = append(sections, fmt.Sprintf("# %s/%s", repo.Namespace, repo.Name))
+	if repo.Description != "" {
+		sections = append(sections, repo.Description)
+	}
+	sections = append(sections, fmt.Sprintf("**Stars:** %d • **Pulls:** %d • **Last Updated:** %s", repo.StarCount, repo.PullCount, repo.LastUpdated))
+	if len(tags.Results) > 0 {
+		var names []string
+		for _, t := range tags.Results {
+			names = append(names, fmt.Sprintf("`%s`", t.Name))
+		}
+		sections = append(sections, fmt.Sprintf("**Tags:** %s", strings.Join(names, ", ")))
+	}
+	if repo.FullDescription != "" {
+		sections = append(sections, repo.FullDescription)
+	}
+	doc := strings.Join(sections, "\n\n")
+
+	// Extract related sources from the full description
+	sources := extractRelatedSources(repo.FullDescription, name)
+
+	return doc, sources, nil
+}
+
+// dockerHubRegistration declares the Docker Hub source
+var dockerHubRegistration = investigator.Registration{
+	Type:         source.TypeDockerHub,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"docker", "image"},
+	Hosts:        []string{"hub.docker.com"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://hub\.docker\.com/(?:_/|r/)([^/\s)]+(?:/[^/\s)]+)?)`),
+			Command:     regexp.MustCompile(`docker pull ([^-\s][^\s]*)`),
+			Description: "Container image reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &DockerHubInvestigator{}
+	},
+}
+
+// Implementation of Docker Hub Investigator
+type DockerHubInvestigator struct{}
+
+func (i *DockerHubInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from hub.docker.com
+	content, relatedSources, err := fetchDockerHub(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *DockerHubInvestigator) GetURL(packagePath string) string {
+	registry, namespace, name := splitDockerImage(packagePath)
+	if registry != "" {
+		return fmt.Sprintf("https://%s/%s", registry, path.Join(namespace, name))
+	}
+	if namespace == "library" {
+		return fmt.Sprintf("https://hub.docker.com/_/%s", name)
+	}
+	return fmt.Sprintf("https://hub.docker.com/r/%s/%s", namespace, name)
+}
+
+func (i *DockerHubInvestigator) GetSourceType() source.Type {
+	return source.TypeDockerHub
+}
+
+func (i *DockerHubInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Docker Hub URL
+	// Example: https://hub.docker.com/_/nginx -> nginx
+	// Example: https://hub.docker.com/r/bitnami/redis -> bitnami/redis
+	for _, prefix := range []string{"https://hub.docker.com/_/", "https://hub.docker.com/r/"} {
+		if strings.HasPrefix(url, prefix) {
+			packagePath := strings.TrimSuffix(url[len(prefix):], "/")
+			if packagePath == "" {
+				return "", failure.New(ErrInvalidPackagePath,
+					failure.Message("Invalid Docker image path"),
+					failure.Context{"url": url},
+				)
+			}
+			return packagePath, nil
+		}
+	}
+	return url, nil
+}
@@ -0,0 +1,67 @@
+package sourceimpl
+
+import "testing"
+
+func TestSplitDockerImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		image         string
+		wantRegistry  string
+		wantNamespace string
+		wantName      string
+	}{
+		{
+			name:          "Official image",
+			image:         "nginx",
+			wantNamespace: "library",
+			wantName:      "nginx",
+		},
+		{
+			name:          "Official image with tag",
+			image:         "nginx:1.25-alpine",
+			wantNamespace: "library",
+			wantName:      "nginx",
+		},
+		{
+			name:          "Namespaced image",
+			image:         "bitnami/redis",
+			wantNamespace: "bitnami",
+			wantName:      "redis",
+		},
+		{
+			name:          "Fully qualified image with digest",
+			image:         "docker.io/bitnami/redis@sha256:0123456789abcdef",
+			wantNamespace: "bitnami",
+			wantName:      "redis",
+		},
+		{
+			name:          "GitHub Container Registry image",
+			image:         "ghcr.io/owner/image:latest",
+			wantRegistry:  "ghcr.io",
+			wantNamespace: "owner",
+			wantName:      "image",
+		},
+		{
+			name:          "Nested repository on another registry",
+			image:         "registry.gitlab.com/group/project/image",
+			wantRegistry:  "registry.gitlab.com",
+			wantNamespace: "group",
+			wantName:      "project/image",
+		},
+		{
+			name:         "Local registry with port",
+			image:        "localhost:5000/image:dev",
+			wantRegistry: "localhost:5000",
+			wantName:     "image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, namespace, name := splitDockerImage(tt.image)
+			if registry != tt.wantRegistry || namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("splitDockerImage() = %v, %v, %v, want %v, %v, %v", registry, namespace, name, tt.wantRegistry, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+}
@@ -1,5 +1,9 @@
 package sourceimpl
 
+import (
+	"github.com/morikuni/failure/v2"
+)
+
 type ErrorCode string
 
 const (
@@ -7,4 +11,28 @@ const (
 
 	// ErrRepositoryNotFound represents errors when repository information cannot be found
 	ErrRepositoryNotFound ErrorCode = "RepositoryNotFound"
+
+	// ErrFetchFailed represents cached failures of fetches which failed without an error code
+	ErrFetchFailed ErrorCode = "FetchFailed"
+)
+
+// errorKey is the key of error fields defined by this package
+type errorKey int
+
+const (
+	// keyHTTPStatus is the key of httpStatus
+	keyHTTPStatus errorKey = iota + 1
 )
+
+// httpStatus is an error field recording the status code of the response a request failed with
+type httpStatus int
+
+func (s httpStatus) SetErrorField(setter failure.FieldSetter) {
+	setter.Set(keyHTTPStatus, s)
+}
+
+// httpStatusOf returns the status code of the response the request failed with, if any
+func httpStatusOf(err error) (int, bool) {
+	s, ok := failure.ValueAs[httpStatus](err, keyHTTPStatus)
+	return int(s), ok
+}
@@ -4,86 +4,32 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/samber/lo"
 )
 
-// sourcePattern defines patterns for detecting package references in content
-type sourcePattern struct {
-	Type           source.Type    // Source type identifier
-	URLPattern     *regexp.Regexp // Pattern for matching URLs
-	CommandPattern *regexp.Regexp // Pattern for matching installation commands
-	Description    string         // Description of the source type
-}
-
-// Known patterns for package references in documentation
-var sourcePatterns = []sourcePattern{
-	{
-		Type:           source.TypeJSR,
-		URLPattern:     regexp.MustCompile(`https?://jsr\.io/(@[^/]+/([^/\s]+))`),
-		CommandPattern: regexp.MustCompile(`jsr add (@[^\s]+)`),
-		Description:    "JSR package reference",
-	},
-	{
-		Type:           source.TypeJSR,
-		CommandPattern: regexp.MustCompile(`deno add jsr:(@[^\s]+)`),
-		Description:    "JSR package reference for Deno",
-	},
-	{
-		Type:           source.TypeNPM,
-		URLPattern:     regexp.MustCompile(`https?://(?:www\.)?npmjs\.com/package/([^/\s]+)`),
-		CommandPattern: regexp.MustCompile(`(?:npm|yarn|pnpm) (?:add|install|create) ([^@\s]+)`),
-		Description:    "NPM package reference",
-	},
-	{
-		Type:           source.TypeGoPkgDev,
-		URLPattern:     regexp.MustCompile(`https?://pkg\.go\.dev/(?:badge/)?([^\s\.]+)(?:\.svg)?`),
-		CommandPattern: regexp.MustCompile(`go (?:get|install|test)(?:\s-u)? ([^@\s]+)`),
-		Description:    "Go package reference",
-	},
-	{
-		Type:           source.TypeCratesIO,
-		URLPattern:     regexp.MustCompile(`https?://(?:www\.)?crates\.io/crates/([^/\s]+)`),
-		CommandPattern: regexp.MustCompile(`cargo add ([^@\s]+)`),
-		Description:    "Cargo package reference",
-	},
-	{
-		Type:           source.TypeRubyGems,
-		URLPattern:     regexp.MustCompile(`https?://(?:www\.)?rubygems\.org/gems/([^/\s]+)`),
-		CommandPattern: regexp.MustCompile(`gem install ([^@\s]+)`),
-		Description:    "RubyGems package reference",
-	},
-	{
-		Type:           source.TypePyPI,
-		URLPattern:     regexp.MustCompile(`https?://(?:www\.)?pypi\.org/project/([^/\s]+)`),
-		CommandPattern: regexp.MustCompile(`pip install ([^@=\s]+)`),
-		Description:    "Python package reference",
-	},
-	{
-		Type:           source.TypePackagist,
-		URLPattern:     regexp.MustCompile(`https?://(?:www\.)?packagist\.org/packages/([^/\s]+/[^/\s]+)`),
-		CommandPattern: regexp.MustCompile(`composer (?:require|install) ([^@\s]+)`),
-		Description:    "PHP package reference",
-	},
-}
-
 // extractSourcesFromURLs extracts source.RelatedSource entries from URLs.
 func extractSourcesFromURLs(urls []string) []source.RelatedReference {
 	var sources []source.RelatedReference
 
+	registrations := investigator.Registrations()
 	for _, url := range urls {
-		for _, pattern := range sourcePatterns {
-			if pattern.URLPattern == nil {
-				continue
-			}
-			if matches := pattern.URLPattern.FindStringSubmatch(url); len(matches) > 1 {
-				pkgName := matches[1]
-				sources = append(sources, source.RelatedReference{
-					Type: pattern.Type,
-					Path: pkgName,
-					From: "document",
-				})
-				break
+	patterns:
+		for _, r := range registrations {
+			for _, pattern := range r.Patterns {
+				if pattern.URL == nil {
+					continue
+				}
+				if matches := pattern.URL.FindStringSubmatch(url); len(matches) > 1 {
+					pkgName := matches[1]
+					sources = append(sources, source.RelatedReference{
+						Type: r.Type,
+						Path: pkgName,
+						From: "document",
+					})
+					break patterns
+				}
 			}
 		}
 	}
@@ -95,20 +41,22 @@ func extractSourcesFromURLs(urls []string) []source.RelatedReference {
 func extractSourcesFromCommands(content string) []source.RelatedReference {
 	var sources []source.RelatedReference
 
-	for _, pattern := range sourcePatterns {
-		if pattern.CommandPattern == nil {
-			continue
-		}
+	for _, r := range investigator.Registrations() {
+		for _, pattern := range r.Patterns {
+			if pattern.Command == nil {
+				continue
+			}
 
-		matches := pattern.CommandPattern.FindAllStringSubmatch(content, -1)
-		for _, match := range matches {
-			if len(match) > 1 {
-				pkgName := match[1]
-				sources = append(sources, source.RelatedReference{
-					Type: pattern.Type,
-					Path: pkgName,
-					From: "document",
-				})
+			matches := pattern.Command.FindAllStringSubmatch(content, -1)
+			for _, match := range matches {
+				if len(match) > 1 {
+					pkgName := match[1]
+					sources = append(sources, source.RelatedReference{
+						Type: r.Type,
+						Path: pkgName,
+						From: "document",
+					})
+				}
 			}
 		}
 	}
@@ -41,6 +41,24 @@ $ npm install express
 	}
 }
 
+func TestExtractGoPackageReferences(t *testing.T) {
+	content := `# widget
+
+[![Go Reference](https://pkg.go.dev/badge/github.com/example/widget.svg)](https://pkg.go.dev/github.com/example/widget)
+
+The next major version is documented at https://pkg.go.dev/github.com/example/widget/v2.
+`
+	want := []source.RelatedReference{
+		{Type: source.TypeGoPkgDev, Path: "github.com/example/widget", From: "document"},
+		{Type: source.TypeGoPkgDev, Path: "github.com/example/widget/v2", From: "document"},
+	}
+
+	got := extractRelatedSources(content, "widget")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("extractRelatedSources() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestURLExtraction(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -290,6 +308,108 @@ func TestCommandExtraction(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "Docker commands",
+			filename: "command_docker.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeDockerHub,
+					Path: "bitnami/redis:latest",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "Helm commands",
+			filename: "command_helm.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeHelm,
+					Path: "bitnami/redis",
+					From: "document",
+				},
+				{
+					Type: source.TypeHelm,
+					Path: "bitnami/redis",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "Terraform modules",
+			filename: "command_terraform.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeTerraform,
+					Path: "terraform-aws-modules/vpc/aws",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "Homebrew commands",
+			filename: "command_brew.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeHomebrew,
+					Path: "ripgrep",
+					From: "document",
+				},
+				{
+					Type: source.TypeHomebrew,
+					Path: "wezterm",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "Linux distribution commands",
+			filename: "command_distro.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeAlpine,
+					Path: "openssl",
+					From: "document",
+				},
+				{
+					Type: source.TypeDebian,
+					Path: "curl",
+					From: "document",
+				},
+				{
+					Type: source.TypeArch,
+					Path: "ripgrep",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "conda commands",
+			filename: "command_conda.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeCondaForge,
+					Path: "numpy",
+					From: "document",
+				},
+				{
+					Type: source.TypeCondaForge,
+					Path: "pandas",
+					From: "document",
+				},
+			},
+		},
+		{
+			name:     "Editor extension commands",
+			filename: "command_vsix.md",
+			want: []source.RelatedReference{
+				{
+					Type: source.TypeOpenVSX,
+					Path: "golang.go",
+					From: "document",
+				},
+			},
+		},
 		{
 			name:     "Mixed commands",
 			filename: "command_mixed.md",
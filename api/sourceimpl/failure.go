@@ -0,0 +1,134 @@
+package sourceimpl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/cache"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// notFoundBackoff is how long a package that does not exist is not fetched again after the first failure
+	// It doubles with each consecutive failure up to maxNotFoundBackoff.
+	notFoundBackoff    = time.Hour
+	maxNotFoundBackoff = 24 * time.Hour
+
+	// transientBackoff is how long a source that failed temporarily is not fetched again after the first failure
+	// It doubles with each consecutive failure up to maxTransientBackoff.
+	transientBackoff    = time.Minute
+	maxTransientBackoff = time.Hour
+)
+
+// failureCache caches failed fetches, so that unavailable sources are not requested on every run
+// Entries expire after the backoff of the failure, and are removed when the fetch succeeds.
+var failureCache = cache.New[fetchFailure]("failure")
+
+// fetchFailure is a cached failure of a fetch
+type fetchFailure struct {
+	// Code is the error code of the failure
+	Code string
+	// Message is the message of the error
+	Message string
+	// NotFound reports whether the package or its documentation does not exist, rather than failing temporarily
+	NotFound bool
+	// Attempts is the number of consecutive failures
+	Attempts int
+}
+
+func (f fetchFailure) String() string {
+	kind := "transient"
+	if f.NotFound {
+		kind = "not found"
+	}
+	return fmt.Sprintf("%s (%s, %d attempts): %s", f.Code, kind, f.Attempts, f.Message)
+}
+
+// backoff returns how long the source is not fetched again after the failure
+func (f fetchFailure) backoff() time.Duration {
+	base, limit := transientBackoff, maxTransientBackoff
+	if f.NotFound {
+		base, limit = notFoundBackoff, maxNotFoundBackoff
+	}
+	d := base
+	for i := 1; i < f.Attempts && d < limit; i++ {
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+// err returns the error of the cached failure, which has the original error code
+func (f fetchFailure) err(retryAt time.Time) error {
+	return failure.New(ErrorCode(f.Code),
+		failure.Message(f.Message),
+		failure.Context{"retry_at": retryAt.Format(time.RFC3339)},
+	)
+}
+
+// isNotFound reports whether the error means that the package or its documentation does not exist
+// Requests failing with 404 Not Found or 410 Gone are not found, while other responses such as
+// rate limits and server errors, and network errors are transient. Other errors are not found if
+// their error code ends with "NotFound", except those of missing commands, which installing the command fixes.
+func isNotFound(err error) bool {
+	if status, ok := httpStatusOf(err); ok {
+		return status == http.StatusNotFound || status == http.StatusGone
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return false
+	}
+	code, ok := failure.CodeOf(err).(ErrorCode)
+	if !ok {
+		return false
+	}
+	return strings.HasSuffix(string(code), "NotFound") && !strings.HasSuffix(string(code), "CommandNotFound")
+}
+
+// cachedFailure returns the error of the cached failure of the key, if it is backing off
+func cachedFailure(key string) (error, bool) {
+	entry, ok := failureCache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	retryAt := entry.CreatedAt.Add(entry.TTL)
+	if !time.Now().Before(retryAt) {
+		return nil, false
+	}
+	return entry.Value.err(retryAt), true
+}
+
+// recordFailure caches the failure of the fetch of the key, backing off exponentially on consecutive failures
+// Failures older than the maximum backoff are not consecutive.
+func recordFailure(key string, err error) {
+	if errors.Is(err, cache.ErrNotCached) {
+		return
+	}
+
+	f := fetchFailure{
+		Code:     string(ErrFetchFailed),
+		Message:  err.Error(),
+		NotFound: isNotFound(err),
+		Attempts: 1,
+	}
+	if code, ok := failure.CodeOf(err).(ErrorCode); ok {
+		f.Code = string(code)
+	}
+	if msg := failure.MessageOf(err); msg != "" {
+		f.Message = msg.String()
+	}
+
+	limit := maxTransientBackoff
+	if f.NotFound {
+		limit = maxNotFoundBackoff
+	}
+	if prev, ok := failureCache.Get(key); ok && prev.Value.NotFound == f.NotFound &&
+		time.Since(prev.CreatedAt.Add(prev.TTL)) < limit {
+		f.Attempts = prev.Value.Attempts + 1
+	}
+
+	_ = failureCache.SetWithTTL(key, f, f.backoff())
+}
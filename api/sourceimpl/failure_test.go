@@ -0,0 +1,158 @@
+package sourceimpl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ka2n/miru/api/cache"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// flakyInvestigator fails with err until it is cleared
+type flakyInvestigator struct {
+	err     error
+	fetches int
+}
+
+func (f *flakyInvestigator) Fetch(packagePath string) (source.Data, error) {
+	f.fetches++
+	if f.err != nil {
+		return source.Data{}, f.err
+	}
+	return source.Data{Contents: map[string]string{"README": "# " + packagePath}}, nil
+}
+
+func (f *flakyInvestigator) GetURL(packagePath string) string { return "" }
+
+func (f *flakyInvestigator) PackageFromURL(url string) (string, error) { return url, nil }
+
+func (f *flakyInvestigator) GetSourceType() source.Type { return source.Type("test") }
+
+func TestFetchWithCacheFailures(t *testing.T) {
+	fetchCache.SetBackend(cache.NewMemory())
+	failureCache.SetBackend(cache.NewMemory())
+	t.Cleanup(func() {
+		fetchCache.SetBackend(nil)
+		failureCache.SetBackend(nil)
+	})
+
+	inv := &flakyInvestigator{err: failure.New(ErrRepositoryNotFound, failure.Message("Repository not found"))}
+	for range 2 {
+		_, err := FetchWithCache(inv, "widget", false)
+		if !failure.Is(err, ErrRepositoryNotFound) {
+			t.Errorf("FetchWithCache() error = %v, want RepositoryNotFound", err)
+		}
+	}
+	if inv.fetches != 1 {
+		t.Errorf("fetches = %d, want the failure to be cached", inv.fetches)
+	}
+	entry, ok := failureCache.Get("test:widget")
+	if !ok || !entry.Value.NotFound || entry.TTL != notFoundBackoff {
+		t.Errorf("cached failure = %+v, %v", entry, ok)
+	}
+
+	// Reloading bypasses the cached failure, and backs off longer when it fails again
+	if _, err := FetchWithCache(inv, "widget", true); err == nil {
+		t.Error("FetchWithCache() with forceUpdate succeeded")
+	}
+	if entry, _ := failureCache.Get("test:widget"); entry.Value.Attempts != 2 || entry.TTL != 2*notFoundBackoff {
+		t.Errorf("cached failure after retry = %+v", entry)
+	}
+
+	inv.err = nil
+	if _, err := FetchWithCache(inv, "widget", true); err != nil {
+		t.Fatalf("FetchWithCache() error = %v", err)
+	}
+	if _, ok := failureCache.Get("test:widget"); ok {
+		t.Error("failure still cached after a successful fetch")
+	}
+}
+
+func TestFetchWithCacheTransientFailures(t *testing.T) {
+	fetchCache.SetBackend(cache.NewMemory())
+	failureCache.SetBackend(cache.NewMemory())
+	t.Cleanup(func() {
+		fetchCache.SetBackend(nil)
+		failureCache.SetBackend(nil)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// Registries answer rate limits and outages like missing packages
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			resp, err := http.Get(fmt.Sprintf("%s/%d", server.URL, status))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			inv := &flakyInvestigator{err: failure.New(ErrRepositoryNotFound, httpStatus(resp.StatusCode))}
+
+			key := fmt.Sprintf("widget-%d", status)
+			if _, err := FetchWithCache(inv, key, false); err == nil {
+				t.Fatal("FetchWithCache() succeeded")
+			}
+			entry, ok := failureCache.Get("test:" + key)
+			if !ok || entry.Value.NotFound || entry.TTL != transientBackoff {
+				t.Errorf("cached failure = %+v, %v, want a transient failure", entry, ok)
+			}
+		})
+	}
+}
+
+func TestFetchFailureBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure fetchFailure
+		want    time.Duration
+	}{
+		{"transient", fetchFailure{Attempts: 1}, time.Minute},
+		{"transient retried", fetchFailure{Attempts: 3}, 4 * time.Minute},
+		{"transient limit", fetchFailure{Attempts: 10}, time.Hour},
+		{"not found", fetchFailure{NotFound: true, Attempts: 1}, time.Hour},
+		{"not found retried", fetchFailure{NotFound: true, Attempts: 2}, 2 * time.Hour},
+		{"not found limit", fetchFailure{NotFound: true, Attempts: 100}, 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.failure.backoff(); got != tt.want {
+				t.Errorf("backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{failure.New(ErrGoModuleNotFound), true},
+		{failure.New(ErrRepositoryNotFound, httpStatus(http.StatusNotFound)), true},
+		{failure.New(ErrGoModuleNotFound, httpStatus(http.StatusGone)), true},
+		{failure.New(ErrRepositoryNotFound, httpStatus(http.StatusTooManyRequests)), false},
+		{failure.New(ErrRepositoryNotFound, httpStatus(http.StatusServiceUnavailable)), false},
+		{failure.Wrap(&url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("connection refused")}, failure.WithCode(ErrRepositoryNotFound)), false},
+		{failure.Wrap(failure.New(ErrRepositoryNotFound)), true},
+		{failure.New(ErrFetchFailed), false},
+		{failure.New(ErrorCode("GHCommandNotFound")), false},
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := isNotFound(tt.err); got != tt.want {
+			t.Errorf("isNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
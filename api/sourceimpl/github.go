@@ -1,17 +1,26 @@
 package sourceimpl
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/log"
 	"github.com/morikuni/failure/v2"
 	"golang.org/x/sync/errgroup"
 )
@@ -110,11 +119,10 @@ func fetchGitHub(pkgPath string) (string, []source.RelatedReference, error) {
 	// Goroutine to fetch repository information
 	g.Go(func() error {
 		reqpath := fmt.Sprintf("/repos/%s/%s", owner, repo)
-		if err := execCmdJSON(ghCmd, []string{"api", reqpath}, &info); err != nil {
-			return failure.New(ErrGHCommandFailed,
+		if err := ghAPI(ghCmd, reqpath, &info); err != nil {
+			return failure.Wrap(err, failure.WithCode(ErrGHCommandFailed),
 				failure.Message("Failed to fetch repository information"),
 				failure.Context{
-					"error": err.Error(),
 					"owner": owner,
 					"repo":  repo,
 				},
@@ -131,11 +139,10 @@ func fetchGitHub(pkgPath string) (string, []source.RelatedReference, error) {
 		// Step 1: Fetch repository contents
 		reqpath := fmt.Sprintf("/repos/%s/%s/contents", owner, repo)
 		var contents []githubContentsResponse
-		if err := execCmdJSON(ghCmd, []string{"api", reqpath}, &contents); err != nil {
-			return failure.New(ErrGHCommandFailed,
+		if err := ghAPI(ghCmd, reqpath, &contents); err != nil {
+			return failure.Wrap(err, failure.WithCode(ErrGHCommandFailed),
 				failure.Message("Failed to fetch repository contents"),
 				failure.Context{
-					"error": err.Error(),
 					"owner": owner,
 					"repo":  repo,
 				},
@@ -156,11 +163,10 @@ func fetchGitHub(pkgPath string) (string, []source.RelatedReference, error) {
 		if readmePath != "" {
 			reqpath := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, readmePath)
 			var content githubContentResponse
-			if err := execCmdJSON(ghCmd, []string{"api", reqpath}, &content); err != nil {
-				return failure.New(ErrGHCommandFailed,
+			if err := ghAPI(ghCmd, reqpath, &content); err != nil {
+				return failure.Wrap(err, failure.WithCode(ErrGHCommandFailed),
 					failure.Message("Failed to fetch README content"),
 					failure.Context{
-						"error": err.Error(),
 						"owner": owner,
 						"repo":  repo,
 					},
@@ -216,6 +222,98 @@ func fetchGitHub(pkgPath string) (string, []source.RelatedReference, error) {
 	return docContent, sources, nil
 }
 
+// ghAPI requests the path of the GitHub API with the gh command and unmarshals the JSON response into out
+// Responses are cached with their ETag and revalidated, so that unchanged responses are not transferred again
+// and do not count against the rate limit.
+func ghAPI(ghCmd, reqpath string, out interface{}) error {
+	req, err := http.NewRequest("GET", "https://api.github.com"+reqpath, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Transport: ghTransport(ghCmd)}
+	resp, err := getRevalidated(responseCache, client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return failure.New(ErrGHCommandFailed,
+			failure.Message("GitHub API request failed"),
+			failure.Context{"path": reqpath, "status": resp.Status},
+			httpStatus(resp.StatusCode),
+		)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ghTransport sends requests to the GitHub API with the gh command, which authenticates them
+type ghTransport string
+
+// RoundTrip runs "gh api -i" with the path and the headers of the request, and reads the response from its output
+// gh exits with an error on responses such as 304 Not Modified and 404 Not Found, which are returned as responses.
+func (t ghTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	args := []string{"api", "-i", req.URL.RequestURI()}
+	for name, values := range req.Header {
+		for _, v := range values {
+			args = append(args, "-H", name+": "+v)
+		}
+	}
+
+	logger := log.Logger.With("cmd", string(t), "args", args)
+	logger.Debug("Executing command")
+	cmd := exec.CommandContext(req.Context(), string(t), args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	resp, err := readGHResponse(&stdout)
+	if err != nil {
+		if runErr != nil {
+			logger.Error("Command failed", "error", runErr.Error(), "stderr", stderr.String())
+			return nil, failure.Wrap(runErr, failure.Context{"stderr": strings.TrimSpace(stderr.String())})
+		}
+		return nil, err
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+// readGHResponse parses the output of "gh api -i", which is the status line and the headers followed by the body
+func readGHResponse(r io.Reader) (*http.Response, error) {
+	tp := textproto.NewReader(bufio.NewReader(r))
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	proto, status, ok := strings.Cut(line, " ")
+	if !ok || !strings.HasPrefix(proto, "HTTP/") {
+		return nil, fmt.Errorf("malformed gh api response: %q", line)
+	}
+	code, _, _ := strings.Cut(status, " ")
+	statusCode, err := strconv.Atoi(code)
+	if err != nil {
+		return nil, fmt.Errorf("malformed gh api status: %q", line)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body, err := io.ReadAll(tp.R)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        status,
+		StatusCode:    statusCode,
+		Proto:         proto,
+		Header:        http.Header(header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
 func (c githubContentResponse) GetContent() (io.Reader, error) {
 	if c.Encoding != "base64" {
 		return nil, failure.New(ErrGHCommandFailed,
@@ -230,6 +328,16 @@ func (c githubContentResponse) GetContent() (io.Reader, error) {
 	return reader, nil
 }
 
+// gitHubRegistration declares the GitHub source
+var gitHubRegistration = investigator.Registration{
+	Type:         source.TypeGitHub,
+	Capabilities: source.CapabilityRepository | source.CapabilityRepositoryURL,
+	Hosts:        []string{"github.com"},
+	New: func() investigator.SourceInvestigator {
+		return &GitHubInvestigator{}
+	},
+}
+
 // Implementation of GitHub Investigator
 type GitHubInvestigator struct{}
 
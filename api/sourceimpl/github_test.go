@@ -0,0 +1,69 @@
+package sourceimpl
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ka2n/miru/api/cache"
+	"github.com/morikuni/failure/v2"
+)
+
+// exampleGH answers "gh api -i" like the GitHub API, with 304 Not Modified to requests with the ETag
+// Each request is appended to the log file next to it.
+const exampleGH = `#!/bin/sh
+echo "$@" >> "$(dirname "$0")/requests.log"
+case "$*" in
+*'/repos/example/missing'*)
+	printf 'HTTP/2.0 404 Not Found\r\nContent-Type: application/json\r\n\r\n{"message":"Not Found"}'
+	exit 1
+	;;
+*'If-None-Match: "v1"'*)
+	printf 'HTTP/2.0 304 Not Modified\r\nEtag: "v1"\r\n\r\n'
+	exit 1
+	;;
+esac
+printf 'HTTP/2.0 200 OK\r\nContent-Type: application/json\r\nEtag: "v1"\r\n\r\n{"homepage":"https://widget.example.com"}'
+`
+
+func TestGHAPI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on Windows")
+	}
+	responseCache.SetBackend(cache.NewMemory())
+	t.Cleanup(func() { responseCache.SetBackend(nil) })
+
+	dir := t.TempDir()
+	gh := filepath.Join(dir, "gh")
+	if err := os.WriteFile(gh, []byte(exampleGH), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		var info githubRepoResponse
+		if err := ghAPI(gh, "/repos/example/widget", &info); err != nil {
+			t.Fatalf("ghAPI() error = %v", err)
+		}
+		if info.Homepage != "https://widget.example.com" {
+			t.Errorf("ghAPI() homepage = %q", info.Homepage)
+		}
+	}
+	requests, err := os.ReadFile(filepath.Join(dir, "requests.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "api -i /repos/example/widget\napi -i /repos/example/widget -H If-None-Match: \"v1\"\n"
+	if string(requests) != want {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+
+	var info githubRepoResponse
+	err = ghAPI(gh, "/repos/example/missing", &info)
+	if status, ok := httpStatusOf(err); !ok || status != 404 {
+		t.Errorf("ghAPI() of missing repository error = %v, want status 404", err)
+	}
+	if !isNotFound(failure.Wrap(err, failure.WithCode(ErrGHCommandFailed))) {
+		t.Errorf("ghAPI() of missing repository error = %v, want not found", err)
+	}
+}
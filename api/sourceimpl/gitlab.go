@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -130,6 +131,16 @@ func fetchGitlab(pkgPath string) (string, []source.RelatedReference, error) {
 	return docContent, sources, nil
 }
 
+// gitLabRegistration declares the GitLab source
+var gitLabRegistration = investigator.Registration{
+	Type:         source.TypeGitLab,
+	Capabilities: source.CapabilityRepository | source.CapabilityRepositoryURL,
+	Hosts:        []string{"gitlab.com"},
+	New: func() investigator.SourceInvestigator {
+		return &GitLabInvestigator{}
+	},
+}
+
 // Implementation of GitLab Investigator
 type GitLabInvestigator struct{}
 
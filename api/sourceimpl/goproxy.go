@@ -0,0 +1,421 @@
+package sourceimpl
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ka2n/miru/log"
+	"github.com/morikuni/failure/v2"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// ErrGoModuleNotFound represents a module that the proxy does not serve
+	ErrGoModuleNotFound ErrorCode = "GoModuleNotFound"
+	// ErrGoProxyDirect represents a module that must be fetched directly from its repository
+	ErrGoProxyDirect ErrorCode = "GoProxyDirect"
+	// ErrGoProxyOff represents module downloads disabled by GOPROXY=off
+	ErrGoProxyOff ErrorCode = "GoProxyOff"
+	// ErrGoModuleTooLarge represents a module file larger than maxGoProxyFileSize
+	ErrGoModuleTooLarge ErrorCode = "GoModuleTooLarge"
+)
+
+// goProxy is an entry of the GOPROXY list
+type goProxy struct {
+	URL string // Proxy URL, "direct" or "off"
+
+	// FallbackOnError is true if the next entry is tried on any error (separated by "|"),
+	// false if only on not found errors (separated by ",")
+	FallbackOnError bool
+}
+
+// maxGoProxyFileSize is the maximum size of files read from the Go module proxy
+// Module zips are read into memory, and may be up to 500 MB.
+var maxGoProxyFileSize int64 = 64 << 20
+
+// goModuleInfo is the response of the .info and @latest endpoints
+type goModuleInfo struct {
+	Version string
+	Time    time.Time
+	Origin  *struct {
+		VCS  string
+		URL  string
+		Ref  string
+		Hash string
+	}
+}
+
+// defaultGoProxy is the default GOPROXY list
+const defaultGoProxy = "https://proxy.golang.org,direct"
+
+// goEnvFiles caches the settings of "go env -w" files by their path, which are read once per process
+var goEnvFiles sync.Map
+
+// goEnv returns the value of the Go environment variable
+// Values written by "go env -w" are used when the variable is not set in the environment.
+func goEnv(key string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+
+	envFile := os.Getenv("GOENV")
+	if envFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		envFile = filepath.Join(dir, "go", "env")
+	}
+	if envFile == "off" {
+		return ""
+	}
+	if settings, ok := goEnvFiles.Load(envFile); ok {
+		return settings.(map[string]string)[key]
+	}
+
+	settings := map[string]string{}
+	if f, err := os.Open(envFile); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if k, v, ok := strings.Cut(scanner.Text(), "="); ok {
+				settings[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+		f.Close()
+	}
+	goEnvFiles.Store(envFile, settings)
+	return settings[key]
+}
+
+// goProxies returns the proxies to fetch the module from, in the order to try
+// Modules matching GONOPROXY (GOPRIVATE by default) are fetched directly.
+func goProxies(modPath string) []goProxy {
+	noProxy := goEnv("GONOPROXY")
+	if noProxy == "" {
+		noProxy = goEnv("GOPRIVATE")
+	}
+	if module.MatchPrefixPatterns(noProxy, modPath) {
+		return []goProxy{{URL: "direct"}}
+	}
+
+	list := goEnv("GOPROXY")
+	if list == "" {
+		list = defaultGoProxy
+	}
+
+	var proxies []goProxy
+	for list != "" {
+		entry, separator := list, byte(0)
+		if i := strings.IndexAny(list, ",|"); i >= 0 {
+			entry, separator, list = list[:i], list[i], list[i+1:]
+		} else {
+			list = ""
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		proxies = append(proxies, goProxy{
+			URL:             strings.TrimSuffix(entry, "/"),
+			FallbackOnError: separator == '|',
+		})
+	}
+	return proxies
+}
+
+// goProxyGet fetches the file of the module from the proxies in the GOPROXY list
+// It returns ErrGoProxyDirect when the list reaches "direct".
+func goProxyGet(modPath, file string) ([]byte, error) {
+	escaped, err := module.EscapePath(modPath)
+	if err != nil {
+		return nil, failure.Wrap(err, failure.WithCode(ErrInvalidPackagePath),
+			failure.Context{"module": modPath})
+	}
+
+	var lastErr error
+	for _, p := range goProxies(modPath) {
+		switch p.URL {
+		case "direct":
+			return nil, failure.New(ErrGoProxyDirect,
+				failure.Message("Module must be fetched directly from the repository"),
+				failure.Context{"module": modPath},
+			)
+		case "off":
+			return nil, failure.New(ErrGoProxyOff,
+				failure.Message("Module downloads are disabled by GOPROXY=off"),
+				failure.Context{"module": modPath},
+			)
+		}
+
+		b, err := goProxyGetFile(p.URL, escaped+"/"+file)
+		if err == nil {
+			return b, nil
+		}
+		log.Logger.Debug("Failed to fetch from Go module proxy", "proxy", redactURL(p.URL), "file", file, "error", err.Error())
+		lastErr = err
+		if !p.FallbackOnError && !failure.Is(err, ErrGoModuleNotFound) {
+			return nil, err
+		}
+	}
+	if lastErr == nil {
+		lastErr = failure.New(ErrGoModuleNotFound,
+			failure.Message("No Go module proxy is configured"),
+			failure.Context{"module": modPath},
+		)
+	}
+	return nil, lastErr
+}
+
+// goProxyGetFile fetches the file from the proxy
+// Proxies with the file:// scheme are read from the local directory.
+func goProxyGetFile(proxyURL, file string) ([]byte, error) {
+	if strings.HasPrefix(proxyURL, "file://") {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		name := filepath.Join(filepath.FromSlash(u.Path), filepath.FromSlash(file))
+		if info, err := os.Stat(name); err == nil && info.Size() > maxGoProxyFileSize {
+			return nil, goProxyFileTooLarge(redactURL(proxyURL), file)
+		}
+		b, err := os.ReadFile(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, failure.New(ErrGoModuleNotFound,
+				failure.Message("Module file not found in the proxy"),
+				failure.Context{"proxy": proxyURL, "file": file},
+			)
+		}
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		return b, nil
+	}
+
+	resp, err := registryGet(proxyURL+"/"+file, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, failure.New(ErrGoModuleNotFound,
+			failure.Message("Module file not found in the proxy"),
+			failure.Context{"proxy": redactURL(proxyURL), "file": file},
+			httpStatus(resp.StatusCode),
+		)
+	default:
+		return nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch from the Go module proxy"),
+			failure.Context{"proxy": redactURL(proxyURL), "file": file, "status": resp.Status},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	if resp.ContentLength > maxGoProxyFileSize {
+		return nil, goProxyFileTooLarge(redactURL(proxyURL), file)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxGoProxyFileSize+1))
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	if int64(len(b)) > maxGoProxyFileSize {
+		return nil, goProxyFileTooLarge(redactURL(proxyURL), file)
+	}
+	return b, nil
+}
+
+// goProxyFileTooLarge returns the error of a file larger than maxGoProxyFileSize
+func goProxyFileTooLarge(proxyURL, file string) error {
+	return failure.New(ErrGoModuleTooLarge,
+		failure.Message(fmt.Sprintf("Module file is larger than %d MB", maxGoProxyFileSize>>20)),
+		failure.Context{"proxy": proxyURL, "file": file},
+	)
+}
+
+// goModuleLatest returns the info of the latest version of the module
+// The latest release in the version list is preferred, then the latest pre-release,
+// and finally @latest, which also resolves pseudo-versions of modules without tags.
+// Paths without a version list are not modules, so that they take a single request.
+func goModuleLatest(modPath string) (goModuleInfo, error) {
+	list, err := goProxyGet(modPath, "@v/list")
+	if err != nil {
+		return goModuleInfo{}, err
+	}
+
+	var release, prerelease string
+	for _, v := range strings.Fields(string(list)) {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			if release == "" || semver.Compare(v, release) > 0 {
+				release = v
+			}
+		} else if prerelease == "" || semver.Compare(v, prerelease) > 0 {
+			prerelease = v
+		}
+	}
+
+	file := "@latest"
+	if release != "" {
+		file = "@v/" + release + ".info"
+	} else if prerelease != "" {
+		file = "@v/" + prerelease + ".info"
+	}
+
+	b, err := goProxyGet(modPath, file)
+	if err != nil {
+		return goModuleInfo{}, err
+	}
+	var info goModuleInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		return goModuleInfo{}, failure.Wrap(err, failure.Context{"module": modPath})
+	}
+	return info, nil
+}
+
+// resolveGoModule returns the path and the latest version of the module containing the package
+// The longest prefix of the package path served by the proxy is the module root,
+// e.g. golang.org/x/sync/errgroup -> golang.org/x/sync
+func resolveGoModule(pkgPath string) (string, goModuleInfo, error) {
+	for candidate := pkgPath; strings.Contains(candidate, "/"); candidate = path.Dir(candidate) {
+		if module.CheckPath(candidate) != nil {
+			continue
+		}
+		info, err := goModuleLatest(candidate)
+		if err == nil {
+			return candidate, info, nil
+		}
+		if !failure.Is(err, ErrGoModuleNotFound) {
+			return "", goModuleInfo{}, err
+		}
+	}
+	return "", goModuleInfo{}, failure.New(ErrGoModuleNotFound,
+		failure.Message("No module provides the package"),
+		failure.Context{"pkg": pkgPath},
+	)
+}
+
+// goModuleFile returns the go.mod file of the module version
+func goModuleFile(modPath, version string) (*modfile.File, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, failure.Wrap(err, failure.WithCode(ErrInvalidPackagePath))
+	}
+	b, err := goProxyGet(modPath, "@v/"+escaped+".mod")
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.ParseLax("go.mod", b, nil)
+	if err != nil {
+		return nil, failure.Wrap(err, failure.Context{"module": modPath, "version": version})
+	}
+	return f, nil
+}
+
+// goModuleZip returns the zip archive of the module version
+func goModuleZip(modPath, version string) (*zip.Reader, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, failure.Wrap(err, failure.WithCode(ErrInvalidPackagePath))
+	}
+	b, err := goProxyGet(modPath, "@v/"+escaped+".zip")
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, failure.Wrap(err, failure.Context{"module": modPath, "version": version})
+	}
+	return zr, nil
+}
+
+// readZipReadme returns the README in the directory of the module zip
+// Files in module zips are prefixed with "module@version/".
+func readZipReadme(zr *zip.Reader, modPath, version, dir string) (string, bool) {
+	prefix := modPath + "@" + version + "/"
+	for _, f := range zr.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || path.Dir(name) != path.Clean(dir) {
+			continue
+		}
+		base := strings.ToLower(path.Base(name))
+		if !strings.HasPrefix(base, "readme.") && base != "readme" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		return string(b), true
+	}
+	return "", false
+}
+
+// readZipPackageDoc returns the package documentation in the directory of the module zip as Markdown
+// doc.go is preferred, as it is the conventional place of the package documentation.
+func readZipPackageDoc(zr *zip.Reader, modPath, version, dir string) (string, bool) {
+	prefix := modPath + "@" + version + "/"
+	var files []*zip.File
+	for _, f := range zr.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || path.Dir(name) != path.Clean(dir) || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if path.Base(name) == "doc.go" {
+			files = append([]*zip.File{f}, files...)
+		} else {
+			files = append(files, f)
+		}
+	}
+
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), f.Name, b, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || file.Doc == nil {
+			continue
+		}
+		var p comment.Parser
+		pr := comment.Printer{
+			// Headings are nested under the title of the package
+			HeadingLevel: 2,
+			HeadingID:    func(*comment.Heading) string { return "" },
+		}
+		return string(pr.Markdown(p.Parse(file.Doc.Text()))), true
+	}
+	return "", false
+}
@@ -0,0 +1,348 @@
+package sourceimpl
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+	"golang.org/x/mod/module"
+)
+
+// writeGoProxy writes a module version in the layout of a file:// GOPROXY
+func writeGoProxy(t *testing.T, dir, modPath, version, gomod string, files map[string]string) {
+	t.Helper()
+
+	escaped, err := module.EscapePath(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdir := filepath.Join(dir, filepath.FromSlash(escaped), "@v")
+	if err := os.MkdirAll(vdir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	list, _ := os.ReadFile(filepath.Join(vdir, "list"))
+	list = append(list, version+"\n"...)
+	info := `{"Version": "` + version + `", "Time": "2025-01-02T03:04:05Z", "Origin": {"VCS": "git", "URL": "https://gitlab.com/example/widget"}}`
+	writes := map[string]string{
+		"list":            string(list),
+		version + ".info": info,
+		version + ".mod":  gomod,
+	}
+	for name, content := range writes {
+		if err := os.WriteFile(filepath.Join(vdir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Create(filepath.Join(vdir, version+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(modPath + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoProxies(t *testing.T) {
+	tests := []struct {
+		name      string
+		goproxy   string
+		goprivate string
+		modPath   string
+		want      []goProxy
+	}{
+		{
+			name:    "default",
+			modPath: "golang.org/x/sync",
+			want:    []goProxy{{URL: "https://proxy.golang.org"}, {URL: "direct"}},
+		},
+		{
+			name:    "fallback on any error",
+			goproxy: "https://goproxy.example.com/|file:///var/goproxy,off",
+			modPath: "golang.org/x/sync",
+			want: []goProxy{
+				{URL: "https://goproxy.example.com", FallbackOnError: true},
+				{URL: "file:///var/goproxy"},
+				{URL: "off"},
+			},
+		},
+		{
+			name:      "private module",
+			goproxy:   "https://goproxy.example.com",
+			goprivate: "*.corp.example.com,go.example.com/private",
+			modPath:   "go.example.com/private/widget",
+			want:      []goProxy{{URL: "direct"}},
+		},
+		{
+			name:      "public module",
+			goproxy:   "https://goproxy.example.com",
+			goprivate: "*.corp.example.com,go.example.com/private",
+			modPath:   "go.example.com/public",
+			want:      []goProxy{{URL: "https://goproxy.example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOENV", "off")
+			t.Setenv("GOPROXY", tt.goproxy)
+			t.Setenv("GONOPROXY", "")
+			t.Setenv("GOPRIVATE", tt.goprivate)
+			if diff := cmp.Diff(tt.want, goProxies(tt.modPath)); diff != "" {
+				t.Errorf("goProxies() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFetchGoModule(t *testing.T) {
+	dir := t.TempDir()
+	modPath := "go.widget.invalid/Widget"
+	writeGoProxy(t, dir, modPath, "v1.0.0", "module go.widget.invalid/Widget\n", map[string]string{
+		"README.md": "# widget v1",
+	})
+	writeGoProxy(t, dir, modPath, "v1.1.0-rc.1", "module go.widget.invalid/Widget\n", map[string]string{
+		"README.md": "# widget rc",
+	})
+	writeGoProxy(t, dir, modPath, "v1.2.0", "// Deprecated: use go.widget.invalid/v2\nmodule go.widget.invalid/Widget\n", map[string]string{
+		"README.md":     "# widget\n\nSee https://pkg.go.dev/go.widget.invalid/Widget/sub",
+		"sub/README.md": "# sub",
+		"widget.go":     "package widget",
+	})
+
+	// The first proxy does not serve the module, so the file:// proxy is used
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	t.Setenv("GOENV", "off")
+	t.Setenv("GOPROXY", notFound.URL+",file://"+filepath.ToSlash(dir))
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	content, sources, err := fetchGoModule(modPath)
+	if err != nil {
+		t.Fatalf("fetchGoModule() error = %v", err)
+	}
+	want := "> **Deprecated:** use go.widget.invalid/v2\n\n# widget\n\nSee https://pkg.go.dev/go.widget.invalid/Widget/sub"
+	if content != want {
+		t.Errorf("fetchGoModule() content = %q, want %q", content, want)
+	}
+	if len(sources) == 0 || sources[0].Type != source.TypeGitLab || sources[0].URL != "https://gitlab.com/example/widget" {
+		t.Errorf("fetchGoModule() sources = %v", sources)
+	}
+
+	t.Setenv("GOPRIVATE", "go.widget.invalid")
+	if _, _, err := fetchGoModule(modPath); !failure.Is(err, ErrGoProxyDirect) {
+		t.Errorf("fetchGoModule() for a private module error = %v, want %s", err, ErrGoProxyDirect)
+	}
+
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GOPROXY", "off")
+	if _, _, err := fetchGoModule(modPath); !failure.Is(err, ErrGoProxyOff) {
+		t.Errorf("fetchGoModule() with GOPROXY=off error = %v, want %s", err, ErrGoProxyOff)
+	}
+}
+
+func TestGoProxyStopsOnError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	dir := t.TempDir()
+	writeGoProxy(t, dir, "go.widget.invalid/widget", "v1.0.0", "module go.widget.invalid/widget\n", map[string]string{
+		"README.md": "# widget",
+	})
+	t.Setenv("GOENV", "off")
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	// "," falls through only when the module is not found
+	t.Setenv("GOPROXY", failing.URL+",file://"+filepath.ToSlash(dir))
+	if _, err := goProxyGet("go.widget.invalid/widget", "@v/list"); err == nil {
+		t.Error("goProxyGet() expected error from the failing proxy")
+	}
+
+	// "|" falls through on any error
+	t.Setenv("GOPROXY", failing.URL+"|file://"+filepath.ToSlash(dir))
+	b, err := goProxyGet("go.widget.invalid/widget", "@v/list")
+	if err != nil {
+		t.Fatalf("goProxyGet() error = %v", err)
+	}
+	if strings.TrimSpace(string(b)) != "v1.0.0" {
+		t.Errorf("goProxyGet() = %q", b)
+	}
+}
+
+func TestResolveGoModuleRequests(t *testing.T) {
+	var requests []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/go.widget.invalid/kit/@v/list":
+			w.Write([]byte("v0.1.0\nv0.2.0\n"))
+		case "/go.widget.invalid/kit/@v/v0.2.0.info":
+			w.Write([]byte(`{"Version": "v0.2.0"}`))
+		case "/go.widget.invalid/untagged/@v/list":
+		case "/go.widget.invalid/untagged/@latest":
+			w.Write([]byte(`{"Version": "v0.0.0-20250102030405-abcdefabcdef"}`))
+		case "/go.widget.invalid/huge/@v/v1.0.0.zip":
+			w.Write(make([]byte, 2048))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer proxy.Close()
+	t.Setenv("GOENV", "off")
+	t.Setenv("GOPROXY", proxy.URL)
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	tests := []struct {
+		pkgPath      string
+		wantModule   string
+		wantVersion  string
+		wantRequests []string
+	}{
+		{
+			// Paths that are not modules take a single request
+			pkgPath:     "go.widget.invalid/kit/sub/deep",
+			wantModule:  "go.widget.invalid/kit",
+			wantVersion: "v0.2.0",
+			wantRequests: []string{
+				"/go.widget.invalid/kit/sub/deep/@v/list",
+				"/go.widget.invalid/kit/sub/@v/list",
+				"/go.widget.invalid/kit/@v/list",
+				"/go.widget.invalid/kit/@v/v0.2.0.info",
+			},
+		},
+		{
+			// @latest is requested only for modules without versions
+			pkgPath:     "go.widget.invalid/untagged",
+			wantModule:  "go.widget.invalid/untagged",
+			wantVersion: "v0.0.0-20250102030405-abcdefabcdef",
+			wantRequests: []string{
+				"/go.widget.invalid/untagged/@v/list",
+				"/go.widget.invalid/untagged/@latest",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkgPath, func(t *testing.T) {
+			requests = nil
+			modPath, info, err := resolveGoModule(tt.pkgPath)
+			if err != nil {
+				t.Fatalf("resolveGoModule() error = %v", err)
+			}
+			if modPath != tt.wantModule || info.Version != tt.wantVersion {
+				t.Errorf("resolveGoModule() = %s, %s, want %s, %s", modPath, info.Version, tt.wantModule, tt.wantVersion)
+			}
+			if diff := cmp.Diff(tt.wantRequests, requests); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	size := maxGoProxyFileSize
+	maxGoProxyFileSize = 1024
+	t.Cleanup(func() { maxGoProxyFileSize = size })
+	if _, err := goModuleZip("go.widget.invalid/huge", "v1.0.0"); !failure.Is(err, ErrGoModuleTooLarge) {
+		t.Errorf("goModuleZip() of a huge module error = %v, want %s", err, ErrGoModuleTooLarge)
+	}
+}
+
+func TestFetchGoPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeGoProxy(t, dir, "go.widget.invalid/kit", "v0.3.0", "module go.widget.invalid/kit\n", map[string]string{
+		"README.md":        "# kit",
+		"sub/README.md":    "# sub",
+		"sub/sub.go":       "// Package sub is ignored in favor of the README.\npackage sub",
+		"docpkg/docpkg.go": "package docpkg",
+		"docpkg/doc.go":    "// Package docpkg provides [Thing].\n//\n// # Usage\n//\n//\tdocpkg.Do()\npackage docpkg",
+		"docpkg/x_test.go": "// Package docpkg_test is not the package documentation.\npackage docpkg_test",
+		"nodoc/nodoc.go":   "package nodoc",
+	})
+	writeGoProxy(t, dir, "go.widget.invalid/kit/nested", "v1.0.0", "module go.widget.invalid/kit/nested\n", map[string]string{
+		"README.md":      "# nested",
+		"inner/inner.go": "// Package inner is in the nested module.\npackage inner",
+	})
+	t.Setenv("GOENV", "off")
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(dir))
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	tests := []struct {
+		pkgPath    string
+		want       string
+		wantModule string
+	}{
+		{"go.widget.invalid/kit", "# kit", ""},
+		{"go.widget.invalid/kit/sub", "# sub", "go.widget.invalid/kit"},
+		{"go.widget.invalid/kit/docpkg", "# go.widget.invalid/kit/docpkg\n\nPackage docpkg provides \\[Thing].\n\n## Usage\n\n\tdocpkg.Do()\n", "go.widget.invalid/kit"},
+		{"go.widget.invalid/kit/nodoc", "# kit", "go.widget.invalid/kit"},
+		{"go.widget.invalid/kit/nested/inner", "# go.widget.invalid/kit/nested/inner\n\nPackage inner is in the nested module.\n", "go.widget.invalid/kit/nested"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkgPath, func(t *testing.T) {
+			content, sources, err := fetchGoModule(tt.pkgPath)
+			if err != nil {
+				t.Fatalf("fetchGoModule() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, content); diff != "" {
+				t.Errorf("fetchGoModule() content mismatch (-want +got):\n%s", diff)
+			}
+
+			var module string
+			for _, s := range sources {
+				if s.Type == source.TypeGoPkgDev && s.From == "api" {
+					module = s.Path
+				}
+			}
+			if module != tt.wantModule {
+				t.Errorf("fetchGoModule() module reference = %q, want %q", module, tt.wantModule)
+			}
+		})
+	}
+
+	if _, _, err := fetchGoModule("go.unknown.invalid/kit"); !failure.Is(err, ErrGoModuleNotFound) {
+		t.Errorf("fetchGoModule() for an unknown module error = %v, want %s", err, ErrGoModuleNotFound)
+	}
+}
+
+func TestFetchPkgGoDevRepositoryRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeGoProxy(t, dir, "github.com/example/widget", "v1.0.0", "module github.com/example/widget\n", map[string]string{
+		"README.md": "# widget from the proxy",
+	})
+	t.Setenv("GOENV", "off")
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(dir))
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+
+	// Repository roots are resolved through the configured proxy like other module paths
+	content, _, err := fetchPkgGoDev("github.com/example/widget")
+	if err != nil {
+		t.Fatalf("fetchPkgGoDev() error = %v", err)
+	}
+	if content != "# widget from the proxy" {
+		t.Errorf("fetchPkgGoDev() content = %q", content)
+	}
+}
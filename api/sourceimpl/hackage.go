@@ -0,0 +1,328 @@
+package sourceimpl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrHackagePackageNotFound represents an error when the package is not found on Hackage
+	ErrHackagePackageNotFound ErrorCode = "HackagePackageNotFound"
+)
+
+// cabalPackage represents the package description read from a .cabal file
+type cabalPackage struct {
+	Name          string
+	Version       string
+	Synopsis      string
+	Description   string
+	License       string
+	Category      string
+	Homepage      string
+	BugReports    string
+	SourceRepoURL string
+}
+
+// parseCabalFile parses the top-level fields and the source-repository section of a .cabal file
+func parseCabalFile(content string) cabalPackage {
+	var pkg cabalPackage
+
+	// Fields are "name: value", continued by lines indented deeper than the field name
+	var section, field string
+	var fieldIndent int
+	values := map[string][]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			if field != "" && trimmed == "" {
+				values[field] = append(values[field], "")
+			}
+			continue
+		}
+
+		// Continuation of the current field
+		if field != "" && indent > fieldIndent {
+			values[field] = append(values[field], trimmed)
+			continue
+		}
+		field = ""
+
+		// Section headers such as "library" or "source-repository head"
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok || strings.ContainsAny(name, " \t") {
+			if indent == 0 {
+				section = strings.ToLower(trimmed)
+			}
+			continue
+		}
+
+		key := strings.ToLower(name)
+		if indent > 0 {
+			// Only the source repository is relevant among the section fields
+			if !strings.HasPrefix(section, "source-repository") {
+				continue
+			}
+			key = section + "." + key
+		}
+		field = key
+		fieldIndent = indent
+		values[key] = []string{strings.TrimSpace(value)}
+	}
+
+	get := func(key string) string {
+		lines := values[key]
+		// Old-style descriptions use a lone "." as a paragraph separator
+		for i, l := range lines {
+			if l == "." {
+				lines[i] = ""
+			}
+		}
+		return strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+
+	pkg.Name = get("name")
+	pkg.Version = get("version")
+	pkg.Synopsis = get("synopsis")
+	pkg.Description = get("description")
+	pkg.License = get("license")
+	pkg.Category = get("category")
+	pkg.Homepage = get("homepage")
+	pkg.BugReports = get("bug-reports")
+	pkg.SourceRepoURL = get("source-repository head.location")
+	if pkg.SourceRepoURL == "" {
+		pkg.SourceRepoURL = get("source-repository this.location")
+	}
+
+	return pkg
+}
+
+// fetchHackage fetches the package description and README from Hackage
+// Returns the content, related sources, and any error
+func fetchHackage(pkgPath string) (string, []source.RelatedReference, error) {
+	// Get the .cabal file of the latest version
+	cabalURL := fmt.Sprintf("https://hackage.haskell.org/package/%s/%s.cabal", pkgPath, pkgPath)
+	resp, err := http.Get(cabalURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrHackagePackageNotFound,
+			failure.Message("Failed to fetch package description from hackage.haskell.org"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	pkg := parseCabalFile(string(body))
+	if pkg.Name == "" {
+		pkg.Name = pkgPath
+	}
+
+	// Get README content if the package ships one
+	var readme string
+	readmeURL := fmt.Sprintf("https://hackage.haskell.org/package/%s/readme.txt", pkgPath)
+	readmeResp, err := http.Get(readmeURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer readmeResp.Body.Close()
+
+	if readmeResp.StatusCode == http.StatusOK {
+		content, err := io.ReadAll(readmeResp.Body)
+		if err != nil {
+			return "", nil, failure.Wrap(err)
+		}
+		readme = string(content)
+	}
+
+	doc := formatHackageDoc(pkg, readme)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add homepage if available
+	if pkg.Homepage != "" {
+		detected := source.DetectSourceTypeFromURL(pkg.Homepage)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(pkg.Homepage, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  pkg.Homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Add Haddock documentation
+	sources = append(sources, source.RelatedReference{
+		Type: source.TypeDocumentation,
+		URL:  fmt.Sprintf("https://hackage.haskell.org/package/%s/docs/index.html", pkgPath),
+		From: "api",
+	})
+
+	// Add repository if available
+	if pkg.SourceRepoURL != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.DetectSourceTypeFromURL(pkg.SourceRepoURL),
+			URL:  cleanupURL(pkg.SourceRepoURL, source.TypeUnknown),
+			From: "api",
+		})
+	}
+
+	// Extract additional sources from documentation
+	docSources := extractRelatedSources(doc, pkgPath)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// formatHackageDoc formats the cabal package description into a markdown document
+func formatHackageDoc(pkg cabalPackage, readme string) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", pkg.Name, pkg.Version))
+
+	// Description
+	if pkg.Synopsis != "" {
+		sections = append(sections, pkg.Synopsis)
+	}
+
+	// Metadata
+	var metadata []string
+	if pkg.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", pkg.License))
+	}
+	if pkg.Category != "" {
+		metadata = append(metadata, fmt.Sprintf("**Category:** %s", pkg.Category))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	if pkg.Homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", pkg.Homepage))
+	}
+	links = append(links, fmt.Sprintf("**Documentation:** https://hackage.haskell.org/package/%s/docs/index.html", pkg.Name))
+	if pkg.SourceRepoURL != "" {
+		links = append(links, fmt.Sprintf("**Repository:** %s", pkg.SourceRepoURL))
+	}
+	if pkg.BugReports != "" {
+		links = append(links, fmt.Sprintf("**Bug Reports:** %s", pkg.BugReports))
+	}
+	sections = append(sections, strings.Join(links, "\n"))
+
+	// README content, or the long description if the package has no README
+	if readme != "" {
+		sections = append(sections, readme)
+	} else if pkg.Description != "" {
+		sections = append(sections, pkg.Description)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// hackageVersionSuffix matches the version of versioned package URLs such as /package/aeson-2.2.3.0
+var hackageVersionSuffix = regexp.MustCompile(`-[0-9]+(\.[0-9]+)*$`)
+
+// hackageRegistration declares the Hackage source
+var hackageRegistration = investigator.Registration{
+	Type:         source.TypeHackage,
+	Capabilities: source.CapabilityRegistry | source.CapabilityDocumentation,
+	Aliases:      []string{"haskell", "hs", "hackage", "cabal"},
+	Hosts:        []string{"hackage.haskell.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://hackage\.haskell\.org/package/([^/\s)]+)`),
+			Command:     regexp.MustCompile(`cabal install (?:--lib )?([^-\s][^\s]*)`),
+			Description: "Haskell package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &HackageInvestigator{}
+	},
+}
+
+// Implementation of Hackage Investigator
+type HackageInvestigator struct{}
+
+func (i *HackageInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from hackage.haskell.org
+	content, relatedSources, err := fetchHackage(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *HackageInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://hackage.haskell.org/package/%s", packagePath)
+}
+
+func (i *HackageInvestigator) GetSourceType() source.Type {
+	return source.TypeHackage
+}
+
+func (i *HackageInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Hackage URL
+	// Example: https://hackage.haskell.org/package/aeson -> aeson
+	// Example: https://hackage.haskell.org/package/aeson-2.2.3.0/docs/Data-Aeson.html -> aeson
+	prefix := "https://hackage.haskell.org/package/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := url[len(prefix):]
+		if i := strings.IndexAny(packagePath, "/?#"); i >= 0 {
+			packagePath = packagePath[:i]
+		}
+		// Components of package names contain a letter, so a numeric last component is the version
+		packagePath = hackageVersionSuffix.ReplaceAllString(packagePath, "")
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Hackage package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
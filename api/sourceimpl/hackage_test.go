@@ -0,0 +1,175 @@
+package sourceimpl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+func TestParseCabalFile(t *testing.T) {
+	content := readTestFile(t, "hackage_aeson.cabal")
+
+	want := cabalPackage{
+		Name:          "aeson",
+		Version:       "2.2.3.0",
+		Synopsis:      "Fast JSON parsing and encoding",
+		Description:   "A JSON parsing and encoding library optimized for ease of use\nand high performance.\n\nTo get started, see the documentation for the @Data.Aeson@ module\nbelow.",
+		License:       "BSD-3-Clause",
+		Category:      "Text, Web, JSON",
+		Homepage:      "https://github.com/haskell/aeson",
+		BugReports:    "https://github.com/haskell/aeson/issues",
+		SourceRepoURL: "git://github.com/haskell/aeson.git",
+	}
+
+	got := parseCabalFile(content)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseCabalFile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// hostTransport sends the requests for the host to the test server
+type hostTransport struct {
+	host   string
+	server *url.URL
+}
+
+func (h *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == h.host {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = h.server.Scheme
+		req.URL.Host = h.server.Host
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// serveHost serves the requests of the default client for the host with the handler during the test
+func serveHost(t *testing.T, host string, handler http.Handler) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = &hostTransport{host: host, server: serverURL}
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+func TestFetchHackage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/package/aeson/aeson.cabal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, readTestFile(t, "hackage_aeson.cabal"))
+	})
+	mux.HandleFunc("/package/aeson/readme.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "# aeson\n\nSee https://hackage.haskell.org/package/text")
+	})
+	mux.HandleFunc("/package/nodocs/nodocs.cabal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "name: nodocs\nversion: 0.1\nsynopsis: No README\ndescription: The long description.\n")
+	})
+	serveHost(t, "hackage.haskell.org", mux)
+
+	tests := []struct {
+		pkg         string
+		wantDoc     string
+		wantSources []source.RelatedReference
+	}{
+		{
+			pkg: "aeson",
+			wantDoc: "# aeson v2.2.3.0\n\n" +
+				"Fast JSON parsing and encoding\n\n" +
+				"**License:** BSD-3-Clause • **Category:** Text, Web, JSON\n\n" +
+				"**Homepage:** https://github.com/haskell/aeson\n" +
+				"**Documentation:** https://hackage.haskell.org/package/aeson/docs/index.html\n" +
+				"**Repository:** git://github.com/haskell/aeson.git\n" +
+				"**Bug Reports:** https://github.com/haskell/aeson/issues\n\n" +
+				"# aeson\n\nSee https://hackage.haskell.org/package/text",
+			wantSources: []source.RelatedReference{
+				{Type: source.TypeGitHub, URL: "https://github.com/haskell/aeson", From: "api"},
+				{Type: source.TypeDocumentation, URL: "https://hackage.haskell.org/package/aeson/docs/index.html", From: "api"},
+				{Type: source.TypeGitHub, URL: "https://github.com/haskell/aeson", From: "api"},
+			},
+		},
+		{
+			// The long description is shown when the package has no README
+			pkg: "nodocs",
+			wantDoc: "# nodocs v0.1\n\n" +
+				"No README\n\n" +
+				"**Documentation:** https://hackage.haskell.org/package/nodocs/docs/index.html\n\n" +
+				"The long description.",
+			wantSources: []source.RelatedReference{
+				{Type: source.TypeDocumentation, URL: "https://hackage.haskell.org/package/nodocs/docs/index.html", From: "api"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			doc, sources, err := fetchHackage(tt.pkg)
+			if err != nil {
+				t.Fatalf("fetchHackage() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantDoc, doc); diff != "" {
+				t.Errorf("fetchHackage() doc mismatch (-want +got):\n%s", diff)
+			}
+			var apiSources []source.RelatedReference
+			for _, s := range sources {
+				if s.From == "api" {
+					apiSources = append(apiSources, s)
+				}
+			}
+			if diff := cmp.Diff(tt.wantSources, apiSources); diff != "" {
+				t.Errorf("fetchHackage() sources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	_, _, err := fetchHackage("missing")
+	if !failure.Is(err, ErrHackagePackageNotFound) || !isNotFound(err) {
+		t.Errorf("fetchHackage() error = %v, want %s reported as not found", err, ErrHackagePackageNotFound)
+	}
+}
+
+func TestHackagePackageFromURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://hackage.haskell.org/package/aeson", want: "aeson"},
+		{url: "https://hackage.haskell.org/package/aeson/", want: "aeson"},
+		{url: "https://hackage.haskell.org/package/aeson-2.2.3.0", want: "aeson"},
+		{url: "https://hackage.haskell.org/package/aeson-2.2.3.0/docs/Data-Aeson.html", want: "aeson"},
+		{url: "https://hackage.haskell.org/package/base64-bytestring#readme", want: "base64-bytestring"},
+		{url: "https://hackage.haskell.org/package/http-client-tls-0.3.6.4", want: "http-client-tls"},
+		{url: "https://hackage.haskell.org/package/", wantErr: true},
+	}
+
+	i := &HackageInvestigator{}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := i.PackageFromURL(tt.url)
+			if tt.wantErr {
+				if !failure.Is(err, ErrInvalidPackagePath) {
+					t.Errorf("PackageFromURL() error = %v, want %s", err, ErrInvalidPackagePath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PackageFromURL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PackageFromURL() = %q, want %q", got, tt.want)
+			}
+			if u := i.GetURL(got); !strings.HasPrefix(tt.url, u) {
+				t.Errorf("GetURL() = %q, want a prefix of %q", u, tt.url)
+			}
+		})
+	}
+}
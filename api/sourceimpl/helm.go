@@ -0,0 +1,384 @@
+package sourceimpl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ErrHelmChartNotFound represents an error when the chart is not found in the chart repository
+	ErrHelmChartNotFound ErrorCode = "HelmChartNotFound"
+)
+
+// helmChartMetadata represents the metadata of a chart in Chart.yaml and index.yaml
+type helmChartMetadata struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	AppVersion  string   `yaml:"appVersion"`
+	Description string   `yaml:"description"`
+	Home        string   `yaml:"home"`
+	Sources     []string `yaml:"sources"`
+	Keywords    []string `yaml:"keywords"`
+	URLs        []string `yaml:"urls"`
+	Deprecated  bool     `yaml:"deprecated"`
+}
+
+// helmRepositoryIndex represents the index.yaml of a chart repository
+type helmRepositoryIndex struct {
+	Entries map[string][]helmChartMetadata `yaml:"entries"`
+}
+
+// artifactHubRepository represents a repository in the Artifact Hub API
+type artifactHubRepository struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// resolveHelmRepository resolves a chart path into the chart repository URL and chart name
+// Accepts "<repo>/<chart>" (resolved via Artifact Hub) or "<repository URL>/<chart>".
+func resolveHelmRepository(pkgPath string) (string, string, error) {
+	if strings.HasPrefix(pkgPath, "https://") || strings.HasPrefix(pkgPath, "http://") {
+		idx := strings.LastIndex(strings.TrimSuffix(pkgPath, "/"), "/")
+		return pkgPath[:idx], strings.TrimSuffix(pkgPath[idx+1:], "/"), nil
+	}
+
+	repoName, chart, ok := strings.Cut(pkgPath, "/")
+	if !ok || repoName == "" || chart == "" {
+		return "", "", failure.New(ErrInvalidPackagePath,
+			failure.Message("Helm chart path must be formatted as '<repo>/<chart>'"),
+			failure.Context{"path": pkgPath},
+		)
+	}
+
+	// Look up the chart repository URL on Artifact Hub (kind 0 is Helm charts)
+	searchURL := fmt.Sprintf("https://artifacthub.io/api/v1/repositories/search?kind=0&name=%s", url.QueryEscape(repoName))
+	resp, err := http.Get(searchURL)
+	if err != nil {
+		return "", "", failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to search chart repository on artifacthub.io"),
+			failure.Context{
+				"repo": repoName,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var repos []artifactHubRepository
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return "", "", failure.Wrap(err)
+	}
+	for _, r := range repos {
+		if r.Name == repoName {
+			return r.URL, chart, nil
+		}
+	}
+
+	return "", "", failure.New(ErrRepositoryNotFound,
+		failure.Message("Chart repository not found on artifacthub.io"),
+		failure.Context{
+			"repo": repoName,
+		},
+	)
+}
+
+// readHelmChartArchive reads Chart.yaml and README.md from a packaged chart (.tgz)
+func readHelmChartArchive(r io.Reader, chart string) (helmChartMetadata, string, error) {
+	var meta helmChartMetadata
+	var readme string
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return meta, "", failure.Wrap(err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return meta, "", failure.Wrap(err)
+		}
+
+		// Only the top-level files of the chart; subcharts live under charts/
+		switch path.Clean(hdr.Name) {
+		case chart + "/Chart.yaml":
+			if err := yaml.NewDecoder(tr).Decode(&meta); err != nil {
+				return meta, "", failure.Wrap(err)
+			}
+		case chart + "/README.md":
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				return meta, "", failure.Wrap(err)
+			}
+			readme = string(b)
+		}
+	}
+
+	return meta, readme, nil
+}
+
+// fetchHelm fetches the chart metadata and README from a Helm chart repository
+// Returns the content, related sources, and any error
+func fetchHelm(pkgPath string) (string, []source.RelatedReference, error) {
+	repoURL, chart, err := resolveHelmRepository(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.HasPrefix(repoURL, "oci://") {
+		return "", nil, failure.New(ErrHelmChartNotFound,
+			failure.Message("OCI chart repositories are not supported"),
+			failure.Context{
+				"pkg":  pkgPath,
+				"repo": repoURL,
+			},
+		)
+	}
+
+	// Get the repository index
+	indexURL := strings.TrimSuffix(repoURL, "/") + "/index.yaml"
+	resp, err := http.Get(indexURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch chart repository index"),
+			failure.Context{
+				"pkg": pkgPath,
+				"url": indexURL,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var index helmRepositoryIndex
+	if err := yaml.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	versions := index.Entries[chart]
+	if len(versions) == 0 {
+		return "", nil, failure.New(ErrHelmChartNotFound,
+			failure.Message("Chart not found in the chart repository"),
+			failure.Context{
+				"pkg":  pkgPath,
+				"repo": repoURL,
+			},
+		)
+	}
+
+	// Pick the latest version
+	latest := versions[0]
+	for _, v := range versions[1:] {
+		if compareVersions(v.Version, latest.Version) > 0 {
+			latest = v
+		}
+	}
+
+	// Get Chart.yaml and README.md from the packaged chart
+	meta := latest
+	var readme string
+	if len(latest.URLs) > 0 {
+		base, _ := url.Parse(indexURL)
+		if chartURL, err := base.Parse(latest.URLs[0]); err == nil {
+			chartResp, err := http.Get(chartURL.String())
+			if err != nil {
+				return "", nil, failure.Wrap(err)
+			}
+			defer chartResp.Body.Close()
+
+			if chartResp.StatusCode == http.StatusOK {
+				archived, content, err := readHelmChartArchive(chartResp.Body, chart)
+				if err != nil {
+					return "", nil, err
+				}
+				if archived.Name != "" {
+					meta = archived
+				}
+				readme = content
+			}
+		}
+	}
+
+	doc := formatHelmChartDoc(meta, readme)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add homepage if available
+	if meta.Home != "" {
+		detected := source.DetectSourceTypeFromURL(meta.Home)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(meta.Home, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  meta.Home,
+				From: "api",
+			})
+		}
+	}
+
+	// Add chart sources as repositories
+	for _, s := range meta.Sources {
+		detected := source.DetectSourceTypeFromURL(s)
+		if detected.IsRepository() {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(s, detected),
+				From: "api",
+			})
+		}
+	}
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(readme, chart)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// formatHelmChartDoc formats the chart metadata into a markdown document
+func formatHelmChartDoc(meta helmChartMetadata, readme string) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", meta.Name, meta.Version))
+
+	// Description
+	if meta.Description != "" {
+		sections = append(sections, meta.Description)
+	}
+
+	// Metadata
+	var metadata []string
+	if meta.AppVersion != "" {
+		metadata = append(metadata, fmt.Sprintf("**App Version:** %s", meta.AppVersion))
+	}
+	if len(meta.Keywords) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Keywords:** %s", strings.Join(meta.Keywords, ", ")))
+	}
+	if meta.Deprecated {
+		metadata = append(metadata, "**Deprecated**")
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	if meta.Home != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", meta.Home))
+	}
+	for _, s := range meta.Sources {
+		links = append(links, fmt.Sprintf("**Source:** %s", s))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// README content
+	if readme != "" {
+		sections = append(sections, readme)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// helmRegistration declares the Helm source
+var helmRegistration = investigator.Registration{
+	Type:         source.TypeHelm,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"helm"},
+	Hosts:        []string{"artifacthub.io"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://artifacthub\.io/packages/helm/([^/\s]+/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`helm (?:install|upgrade --install) (?:[^-/\s][^/\s]* )?([^-/\s][^/\s]*/[^/\s]+)`),
+			Description: "Helm chart reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &HelmInvestigator{}
+	},
+}
+
+// Implementation of Helm Investigator
+type HelmInvestigator struct{}
+
+func (i *HelmInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the chart repository
+	content, relatedSources, err := fetchHelm(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *HelmInvestigator) GetURL(packagePath string) string {
+	if strings.HasPrefix(packagePath, "https://") || strings.HasPrefix(packagePath, "http://") {
+		return packagePath
+	}
+	return fmt.Sprintf("https://artifacthub.io/packages/helm/%s", packagePath)
+}
+
+func (i *HelmInvestigator) GetSourceType() source.Type {
+	return source.TypeHelm
+}
+
+func (i *HelmInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Artifact Hub URL
+	// Example: https://artifacthub.io/packages/helm/bitnami/redis -> bitnami/redis
+	prefix := "https://artifacthub.io/packages/helm/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := url[len(prefix):]
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Helm chart path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
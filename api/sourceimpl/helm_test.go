@@ -0,0 +1,66 @@
+package sourceimpl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadHelmChartArchive(t *testing.T) {
+	files := map[string]string{
+		"redis/Chart.yaml": `apiVersion: v2
+name: redis
+version: 19.0.1
+appVersion: 7.2.4
+description: Redis is an in-memory data structure store.
+home: https://bitnami.com
+sources:
+  - https://github.com/bitnami/charts/tree/main/bitnami/redis
+keywords:
+  - redis
+  - database
+`,
+		"redis/README.md":                 "# Redis packaged by Bitnami\n",
+		"redis/charts/common/Chart.yaml":  "name: common\nversion: 2.0.0\n",
+		"redis/charts/common/README.md":   "# Common\n",
+		"redis/templates/deployment.yaml": "kind: Deployment\n",
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	meta, readme, err := readHelmChartArchive(&buf, "redis")
+	if err != nil {
+		t.Fatalf("readHelmChartArchive() error = %v", err)
+	}
+
+	wantMeta := helmChartMetadata{
+		Name:        "redis",
+		Version:     "19.0.1",
+		AppVersion:  "7.2.4",
+		Description: "Redis is an in-memory data structure store.",
+		Home:        "https://bitnami.com",
+		Sources:     []string{"https://github.com/bitnami/charts/tree/main/bitnami/redis"},
+		Keywords:    []string{"redis", "database"},
+	}
+	if diff := cmp.Diff(wantMeta, meta); diff != "" {
+		t.Errorf("readHelmChartArchive() metadata mismatch (-want +got):\n%s", diff)
+	}
+	if readme != "# Redis packaged by Bitnami\n" {
+		t.Errorf("readHelmChartArchive() readme = %q", readme)
+	}
+}
@@ -0,0 +1,433 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrHomebrewNotFound represents an error when the formula or cask is not found
+	ErrHomebrewNotFound ErrorCode = "HomebrewNotFound"
+)
+
+// homebrewFormula represents the formulae.brew.sh API response for a formula
+type homebrewFormula struct {
+	Name     string `json:"name"`
+	Tap      string `json:"tap"`
+	Desc     string `json:"desc"`
+	License  string `json:"license"`
+	Homepage string `json:"homepage"`
+	Versions struct {
+		Stable string `json:"stable"`
+		Head   string `json:"head"`
+		Bottle bool   `json:"bottle"`
+	} `json:"versions"`
+	URLs struct {
+		Stable struct {
+			URL string `json:"url"`
+		} `json:"stable"`
+		Head struct {
+			URL string `json:"url"`
+		} `json:"head"`
+	} `json:"urls"`
+	VersionedFormulae    []string `json:"versioned_formulae"`
+	Dependencies         []string `json:"dependencies"`
+	BuildDependencies    []string `json:"build_dependencies"`
+	OptionalDependencies []string `json:"optional_dependencies"`
+	ConflictsWith        []string `json:"conflicts_with"`
+	Caveats              string   `json:"caveats"`
+	Deprecated           bool     `json:"deprecated"`
+	Disabled             bool     `json:"disabled"`
+}
+
+// homebrewCask represents the formulae.brew.sh API response for a cask
+type homebrewCask struct {
+	Token     string   `json:"token"`
+	Name      []string `json:"name"`
+	Tap       string   `json:"tap"`
+	Desc      string   `json:"desc"`
+	Homepage  string   `json:"homepage"`
+	URL       string   `json:"url"`
+	Version   string   `json:"version"`
+	DependsOn struct {
+		Formula []string `json:"formula"`
+	} `json:"depends_on"`
+	Caveats    string `json:"caveats"`
+	Deprecated bool   `json:"deprecated"`
+	Disabled   bool   `json:"disabled"`
+}
+
+// splitHomebrewPath splits a package path into the kind ("formula" or "cask") and name
+// An empty kind means the formula is looked up first, then the cask.
+// Example: cask/firefox -> cask, firefox
+func splitHomebrewPath(pkgPath string) (string, string) {
+	pkgPath = strings.TrimPrefix(pkgPath, "homebrew/")
+	if kind, name, ok := strings.Cut(pkgPath, "/"); ok {
+		switch kind {
+		case "formula", "core":
+			return "formula", name
+		case "cask":
+			return "cask", name
+		}
+	}
+	return "", pkgPath
+}
+
+// homebrewRepositoryURL returns the repository URL for a GitHub/GitLab download URL
+// Example: https://github.com/BurntSushi/ripgrep/archive/refs/tags/14.1.0.tar.gz -> https://github.com/BurntSushi/ripgrep
+func homebrewRepositoryURL(downloadURL string) (source.Type, string) {
+	detected := source.DetectSourceTypeFromURL(downloadURL)
+	if !detected.IsRepository() {
+		return source.TypeUnknown, ""
+	}
+
+	u, err := url.Parse(strings.TrimPrefix(downloadURL, "git+"))
+	if err != nil {
+		return source.TypeUnknown, ""
+	}
+
+	// GitLab marks the end of the project path with "/-/", GitHub repositories are always owner/repo
+	repoPath, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/-/")
+	parts := strings.Split(strings.TrimSuffix(repoPath, ".git"), "/")
+	if len(parts) < 2 {
+		return source.TypeUnknown, ""
+	}
+	if detected == source.TypeGitHub {
+		parts = parts[:2]
+	}
+	return detected, fmt.Sprintf("https://%s/%s", u.Host, strings.Join(parts, "/"))
+}
+
+// fetchHomebrew fetches the formula or cask information from formulae.brew.sh
+// Returns the content, related sources, and any error
+func fetchHomebrew(pkgPath string) (string, []source.RelatedReference, error) {
+	kind, name := splitHomebrewPath(pkgPath)
+
+	if kind != "cask" {
+		var formula homebrewFormula
+		found, err := fetchHomebrewJSON("formula", name, &formula)
+		if err != nil {
+			return "", nil, err
+		}
+		if found {
+			return formatHomebrewFormulaDoc(formula), homebrewSources(formula.Homepage, formula.URLs.Stable.URL, formula.URLs.Head.URL), nil
+		}
+		if kind == "formula" {
+			return "", nil, failure.New(ErrHomebrewNotFound,
+				failure.Message("Formula not found on formulae.brew.sh"),
+				failure.Context{
+					"pkg": pkgPath,
+				},
+			)
+		}
+	}
+
+	var cask homebrewCask
+	found, err := fetchHomebrewJSON("cask", name, &cask)
+	if err != nil {
+		return "", nil, err
+	}
+	if !found {
+		return "", nil, failure.New(ErrHomebrewNotFound,
+			failure.Message("Formula or cask not found on formulae.brew.sh"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+		)
+	}
+	return formatHomebrewCaskDoc(cask), homebrewSources(cask.Homepage, cask.URL), nil
+}
+
+// fetchHomebrewJSON fetches a formula or cask from the formulae.brew.sh API
+// Returns false if the formula or cask does not exist.
+func fetchHomebrewJSON(kind, name string, out any) (bool, error) {
+	apiURL := fmt.Sprintf("https://formulae.brew.sh/api/%s/%s.json", kind, url.PathEscape(name))
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return false, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, failure.New(ErrHomebrewNotFound,
+			failure.Message("Failed to fetch from formulae.brew.sh"),
+			failure.Context{
+				"kind":   kind,
+				"name":   name,
+				"status": resp.Status,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, failure.Wrap(err)
+	}
+	return true, nil
+}
+
+// homebrewSources extracts the related sources from the homepage and download URLs
+func homebrewSources(homepage string, downloadURLs ...string) []source.RelatedReference {
+	var sources []source.RelatedReference
+	seen := map[string]bool{}
+
+	// Add homepage if available
+	if homepage != "" {
+		detected := source.DetectSourceTypeFromURL(homepage)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			u := cleanupURL(homepage, detected)
+			seen[u] = true
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  u,
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Add the upstream repository the source archive is downloaded from
+	for _, u := range downloadURLs {
+		detected, repoURL := homebrewRepositoryURL(u)
+		if repoURL == "" || seen[repoURL] {
+			continue
+		}
+		seen[repoURL] = true
+		sources = append(sources, source.RelatedReference{
+			Type: detected,
+			URL:  repoURL,
+			From: "api",
+		})
+	}
+
+	return sources
+}
+
+// formatHomebrewFormulaDoc formats the formula information into a markdown document
+func formatHomebrewFormulaDoc(formula homebrewFormula) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s %s", formula.Name, formula.Versions.Stable))
+
+	// Description
+	if formula.Desc != "" {
+		sections = append(sections, formula.Desc)
+	}
+
+	// Metadata
+	var metadata []string
+	if formula.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", formula.License))
+	}
+	if formula.Tap != "" {
+		metadata = append(metadata, fmt.Sprintf("**Tap:** %s", formula.Tap))
+	}
+	if formula.Versions.Head != "" {
+		metadata = append(metadata, fmt.Sprintf("**Head:** %s", formula.Versions.Head))
+	}
+	if formula.Versions.Bottle {
+		metadata = append(metadata, "**Bottle:** yes")
+	}
+	if formula.Deprecated {
+		metadata = append(metadata, "**Deprecated**")
+	}
+	if formula.Disabled {
+		metadata = append(metadata, "**Disabled**")
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	if formula.Homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", formula.Homepage))
+	}
+	if formula.URLs.Stable.URL != "" {
+		links = append(links, fmt.Sprintf("**Source:** %s", formula.URLs.Stable.URL))
+	}
+	if formula.URLs.Head.URL != "" {
+		links = append(links, fmt.Sprintf("**Head Source:** %s", formula.URLs.Head.URL))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Dependencies and other versions
+	var deps []string
+	if len(formula.Dependencies) > 0 {
+		deps = append(deps, fmt.Sprintf("**Dependencies:** %s", strings.Join(formula.Dependencies, ", ")))
+	}
+	if len(formula.BuildDependencies) > 0 {
+		deps = append(deps, fmt.Sprintf("**Build Dependencies:** %s", strings.Join(formula.BuildDependencies, ", ")))
+	}
+	if len(formula.OptionalDependencies) > 0 {
+		deps = append(deps, fmt.Sprintf("**Optional Dependencies:** %s", strings.Join(formula.OptionalDependencies, ", ")))
+	}
+	if len(formula.ConflictsWith) > 0 {
+		deps = append(deps, fmt.Sprintf("**Conflicts With:** %s", strings.Join(formula.ConflictsWith, ", ")))
+	}
+	if len(formula.VersionedFormulae) > 0 {
+		deps = append(deps, fmt.Sprintf("**Versioned Formulae:** %s", strings.Join(formula.VersionedFormulae, ", ")))
+	}
+	if len(deps) > 0 {
+		sections = append(sections, strings.Join(deps, "\n"))
+	}
+
+	// Caveats
+	if formula.Caveats != "" {
+		sections = append(sections, "## Caveats\n\n"+formula.Caveats)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// formatHomebrewCaskDoc formats the cask information into a markdown document
+func formatHomebrewCaskDoc(cask homebrewCask) string {
+	var sections []string
+
+	// Title and version
+	title := cask.Token
+	if len(cask.Name) > 0 {
+		title = cask.Name[0]
+	}
+	sections = append(sections, fmt.Sprintf("# %s %s", title, cask.Version))
+
+	// Description
+	if cask.Desc != "" {
+		sections = append(sections, cask.Desc)
+	}
+
+	// Metadata
+	var metadata []string
+	metadata = append(metadata, fmt.Sprintf("**Cask:** %s", cask.Token))
+	if cask.Tap != "" {
+		metadata = append(metadata, fmt.Sprintf("**Tap:** %s", cask.Tap))
+	}
+	if cask.Deprecated {
+		metadata = append(metadata, "**Deprecated**")
+	}
+	if cask.Disabled {
+		metadata = append(metadata, "**Disabled**")
+	}
+	sections = append(sections, strings.Join(metadata, " • "))
+
+	// Links
+	var links []string
+	if cask.Homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", cask.Homepage))
+	}
+	if cask.URL != "" {
+		links = append(links, fmt.Sprintf("**Download:** %s", cask.URL))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Dependencies
+	if len(cask.DependsOn.Formula) > 0 {
+		sections = append(sections, fmt.Sprintf("**Dependencies:** %s", strings.Join(cask.DependsOn.Formula, ", ")))
+	}
+
+	// Caveats
+	if cask.Caveats != "" {
+		sections = append(sections, "## Caveats\n\n"+cask.Caveats)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// homebrewRegistration declares the Homebrew source
+var homebrewRegistration = investigator.Registration{
+	Type:         source.TypeHomebrew,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"brew", "homebrew"},
+	Hosts:        []string{"formulae.brew.sh"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://formulae\.brew\.sh/((?:formula|cask)/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`brew install (?:--(?:cask|formula) )?([^-\s][^\s]*)`),
+			Description: "Homebrew formula or cask reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &HomebrewInvestigator{}
+	},
+}
+
+// Implementation of Homebrew Investigator
+type HomebrewInvestigator struct{}
+
+func (i *HomebrewInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from formulae.brew.sh
+	content, relatedSources, err := fetchHomebrew(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *HomebrewInvestigator) GetURL(packagePath string) string {
+	kind, name := splitHomebrewPath(packagePath)
+	if kind == "" {
+		kind = "formula"
+	}
+	return fmt.Sprintf("https://formulae.brew.sh/%s/%s", kind, name)
+}
+
+func (i *HomebrewInvestigator) GetSourceType() source.Type {
+	return source.TypeHomebrew
+}
+
+func (i *HomebrewInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from formulae.brew.sh URL
+	// Example: https://formulae.brew.sh/formula/ripgrep -> ripgrep
+	// Example: https://formulae.brew.sh/cask/firefox -> cask/firefox
+	for prefix, kind := range map[string]string{
+		"https://formulae.brew.sh/formula/": "",
+		"https://formulae.brew.sh/cask/":    "cask/",
+	} {
+		if strings.HasPrefix(url, prefix) {
+			name := strings.TrimSuffix(url[len(prefix):], "/")
+			if name == "" {
+				return "", failure.New(ErrInvalidPackagePath,
+					failure.Message("Invalid Homebrew package path"),
+					failure.Context{"url": url},
+				)
+			}
+			return kind + name, nil
+		}
+	}
+	return url, nil
+}
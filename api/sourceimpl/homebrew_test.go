@@ -0,0 +1,74 @@
+package sourceimpl
+
+import (
+	"testing"
+
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestSplitHomebrewPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantKind string
+		wantName string
+	}{
+		{path: "ripgrep", wantKind: "", wantName: "ripgrep"},
+		{path: "formula/ripgrep", wantKind: "formula", wantName: "ripgrep"},
+		{path: "homebrew/core/ripgrep", wantKind: "formula", wantName: "ripgrep"},
+		{path: "cask/firefox", wantKind: "cask", wantName: "firefox"},
+		{path: "homebrew/cask/firefox", wantKind: "cask", wantName: "firefox"},
+		{path: "python@3.12", wantKind: "", wantName: "python@3.12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			kind, name := splitHomebrewPath(tt.path)
+			if kind != tt.wantKind || name != tt.wantName {
+				t.Errorf("splitHomebrewPath() = %q, %q, want %q, %q", kind, name, tt.wantKind, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestHomebrewRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		wantType source.Type
+		wantURL  string
+	}{
+		{
+			name:     "GitHub archive",
+			url:      "https://github.com/BurntSushi/ripgrep/archive/refs/tags/14.1.0.tar.gz",
+			wantType: source.TypeGitHub,
+			wantURL:  "https://github.com/BurntSushi/ripgrep",
+		},
+		{
+			name:     "GitHub head",
+			url:      "https://github.com/BurntSushi/ripgrep.git",
+			wantType: source.TypeGitHub,
+			wantURL:  "https://github.com/BurntSushi/ripgrep",
+		},
+		{
+			name:     "GitLab subgroup archive",
+			url:      "https://gitlab.com/group/subgroup/project/-/archive/v1.0/project-v1.0.tar.gz",
+			wantType: source.TypeGitLab,
+			wantURL:  "https://gitlab.com/group/subgroup/project",
+		},
+		{
+			name:     "Other download site",
+			url:      "https://ftp.gnu.org/gnu/wget/wget-1.24.5.tar.gz",
+			wantType: source.TypeUnknown,
+			wantURL:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotURL := homebrewRepositoryURL(tt.url)
+			if gotType != tt.wantType || gotURL != tt.wantURL {
+				t.Errorf("homebrewRepositoryURL() = %v, %q, want %v, %q", gotType, gotURL, tt.wantType, tt.wantURL)
+			}
+		})
+	}
+}
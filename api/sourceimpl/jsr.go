@@ -3,12 +3,36 @@ package sourceimpl
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 )
 
+// jsrRegistration declares the JSR source
+var jsrRegistration = investigator.Registration{
+	Type:         source.TypeJSR,
+	Capabilities: source.CapabilityRegistry | source.CapabilityDocumentation,
+	Aliases:      []string{"jsr"},
+	Hosts:        []string{"jsr.io"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://jsr\.io/(@[^/]+/([^/\s]+))`),
+			Command:     regexp.MustCompile(`jsr add (@[^\s]+)`),
+			Description: "JSR package reference",
+		},
+		{
+			Command:     regexp.MustCompile(`deno add jsr:(@[^\s]+)`),
+			Description: "JSR package reference for Deno",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &JSRInvestigator{}
+	},
+}
+
 // Implementation of JSR Investigator
 type JSRInvestigator struct{}
 
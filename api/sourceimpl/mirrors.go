@@ -0,0 +1,471 @@
+package sourceimpl
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// registryGet sends a GET request to a package registry
+// authorization is sent as the Authorization header if not empty.
+// Otherwise, credentials in the URL are sent as basic auth, or the .netrc entry of the host is used.
+// Responses are revalidated with the validators of the previous response, see getRevalidated.
+func registryGet(rawURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+
+	switch {
+	case authorization != "":
+		req.Header.Set("Authorization", authorization)
+	case req.URL.User != nil:
+		// net/http sends the credentials in the URL as basic auth
+	default:
+		if login, password, ok := netrcCredentials(req.URL.Hostname()); ok {
+			req.SetBasicAuth(login, password)
+		}
+	}
+
+	ConfigureCache()
+	resp, err := getRevalidated(responseCache, http.DefaultClient, req)
+	if err != nil {
+		return nil, failure.Wrap(err)
+	}
+	return resp, nil
+}
+
+// redactURL returns the URL with the password replaced, for error messages
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// registryBaseURL returns the base URL of a registry from the environment variable or the default
+func registryBaseURL(env, defaultURL string) string {
+	if v := os.Getenv(env); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return defaultURL
+}
+
+const (
+	// defaultNpmRegistry is the public npm registry
+	defaultNpmRegistry = "https://registry.npmjs.org"
+	// defaultPyPIURL is the base URL of the public PyPI JSON API
+	defaultPyPIURL = "https://pypi.org"
+	// defaultRubyGemsURL is the base URL of the public RubyGems API
+	defaultRubyGemsURL = "https://rubygems.org"
+	// defaultPackagistURL is the base URL of the public Packagist API
+	defaultPackagistURL = "https://packagist.org"
+)
+
+// npmConfig holds the settings read from .npmrc files
+type npmConfig map[string]string
+
+// loadNpmConfig returns the settings of the .npmrc files, which are read once per process
+var loadNpmConfig = sync.OnceValue(readNpmConfig)
+
+// readNpmConfig reads the user and project .npmrc files
+// Settings of the project .npmrc in the current directory override the user ones,
+// and npm_config_registry overrides both, as in npm.
+func readNpmConfig() npmConfig {
+	cfg := npmConfig{}
+
+	userConfig := os.Getenv("NPM_CONFIG_USERCONFIG")
+	if userConfig == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			userConfig = filepath.Join(home, ".npmrc")
+		}
+	}
+	for _, path := range []string{userConfig, ".npmrc"} {
+		if path == "" {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for k, v := range parseNpmrc(string(b)) {
+			cfg[k] = v
+		}
+	}
+
+	for _, env := range []string{"npm_config_registry", "NPM_CONFIG_REGISTRY"} {
+		if v := os.Getenv(env); v != "" {
+			cfg["registry"] = v
+		}
+	}
+	return cfg
+}
+
+// parseNpmrc parses the content of a .npmrc file
+// Environment variables written as ${NAME} are expanded.
+func parseNpmrc(content string) map[string]string {
+	settings := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		settings[strings.TrimSpace(key)] = os.ExpandEnv(value)
+	}
+	return settings
+}
+
+// registry returns the registry URL of the package
+// MIRU_NPM_REGISTRY takes precedence over the scope registry and the default registry of .npmrc.
+func (c npmConfig) registry(pkgPath string) string {
+	if v := os.Getenv("MIRU_NPM_REGISTRY"); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	if scope, _, ok := strings.Cut(pkgPath, "/"); ok && strings.HasPrefix(scope, "@") {
+		if v := c[scope+":registry"]; v != "" {
+			return strings.TrimSuffix(v, "/")
+		}
+	}
+	if v := c["registry"]; v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return defaultNpmRegistry
+}
+
+// authorization returns the Authorization header for the registry
+// Credentials are keyed by the registry URL without the scheme, such as "//npm.example.com/:_authToken".
+// The longest key matching the registry URL is used.
+func (c npmConfig) authorization(registryURL string) string {
+	u, err := url.Parse(registryURL)
+	if err != nil {
+		return ""
+	}
+	nerfed := "//" + u.Host + strings.TrimSuffix(u.Path, "/") + "/"
+
+	var prefix string
+	for key := range c {
+		p, _, ok := strings.Cut(key, ":_")
+		if !ok || !strings.HasPrefix(p, "//") {
+			p, _, ok = strings.Cut(key, ":username")
+		}
+		if !ok || !strings.HasPrefix(p, "//") {
+			continue
+		}
+		if strings.HasPrefix(nerfed, strings.TrimSuffix(p, "/")+"/") && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return ""
+	}
+
+	if token := c[prefix+":_authToken"]; token != "" {
+		return "Bearer " + token
+	}
+	if auth := c[prefix+":_auth"]; auth != "" {
+		return "Basic " + auth
+	}
+	if username, password := c[prefix+":username"], c[prefix+":_password"]; username != "" && password != "" {
+		// _password is base64 encoded in .npmrc
+		decoded, err := base64.StdEncoding.DecodeString(password)
+		if err != nil {
+			return ""
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+string(decoded)))
+	}
+	return ""
+}
+
+// pipConfigPaths returns the pip configuration files in the order pip loads them
+// Later files override earlier ones.
+func pipConfigPaths() []string {
+	paths := []string{"/etc/pip.conf"}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".pip", "pip.conf"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "pip", "pip.conf"), filepath.Join(dir, "pip", "pip.ini"))
+	}
+	if p := os.Getenv("PIP_CONFIG_FILE"); p != "" {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// parsePipConfig returns the index-url setting of a pip configuration file
+// The [global] section takes precedence over [install].
+func parsePipConfig(content string) string {
+	values := map[string]string{}
+	var section string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				key, value, ok = strings.Cut(line, ":")
+			}
+			if ok && strings.TrimSpace(key) == "index-url" {
+				values[section] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if v := values["global"]; v != "" {
+		return v
+	}
+	return values["install"]
+}
+
+// pypiBaseURL returns the base URL of the PyPI JSON API
+// The index URL is read from MIRU_PYPI_URL, PIP_INDEX_URL or pip.conf in this order,
+// and the trailing "/simple" of the index URL is removed to get the JSON API base,
+// e.g. https://artifactory.example.com/api/pypi/pypi/simple -> https://artifactory.example.com/api/pypi/pypi
+func pypiBaseURL() string {
+	index := os.Getenv("MIRU_PYPI_URL")
+	if index == "" {
+		index = os.Getenv("PIP_INDEX_URL")
+	}
+	if index == "" {
+		for _, path := range pipConfigPaths() {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if v := parsePipConfig(string(b)); v != "" {
+				index = v
+			}
+		}
+	}
+	if index == "" {
+		return defaultPyPIURL
+	}
+
+	index = strings.TrimSuffix(index, "/")
+	index = strings.TrimSuffix(index, "/+simple")
+	index = strings.TrimSuffix(index, "/simple")
+	return index
+}
+
+// cargoConfig represents the settings of cargo config.toml and credentials.toml
+type cargoConfig struct {
+	Source     map[string]cargoSource   `toml:"source"`
+	Registries map[string]cargoRegistry `toml:"registries"`
+}
+
+// cargoSource represents a [source.NAME] table of the cargo config
+type cargoSource struct {
+	ReplaceWith string `toml:"replace-with"`
+	Registry    string `toml:"registry"`
+}
+
+// cargoRegistry represents a [registries.NAME] table of the cargo config or credentials
+type cargoRegistry struct {
+	Index string `toml:"index"`
+	Token string `toml:"token"`
+}
+
+// cargoHome returns the cargo home directory
+func cargoHome() string {
+	if v := os.Getenv("CARGO_HOME"); v != "" {
+		return v
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cargo")
+}
+
+// loadCargoConfig reads the config and credentials of cargo
+// Both the .toml files and the legacy file names without the extension are read.
+// Each file is decoded separately so that tokens in credentials.toml do not replace the index of the registry.
+func loadCargoConfig(home string) (cargoConfig, error) {
+	cfg := cargoConfig{
+		Source:     map[string]cargoSource{},
+		Registries: map[string]cargoRegistry{},
+	}
+	for _, name := range []string{"config", "config.toml", "credentials", "credentials.toml"} {
+		b, err := os.ReadFile(filepath.Join(home, name))
+		if err != nil {
+			continue
+		}
+		var file cargoConfig
+		if _, err := toml.Decode(string(b), &file); err != nil {
+			return cargoConfig{}, failure.Wrap(err, failure.Context{"file": name})
+		}
+		for k, v := range file.Source {
+			cfg.Source[k] = v
+		}
+		for k, v := range file.Registries {
+			r := cfg.Registries[k]
+			if v.Index != "" {
+				r.Index = v.Index
+			}
+			if v.Token != "" {
+				r.Token = v.Token
+			}
+			cfg.Registries[k] = r
+		}
+	}
+	return cfg, nil
+}
+
+// cratesAPI is the API base URL of the crates.io registry and its token
+type cratesAPI struct {
+	base, token string
+}
+
+// cratesRegistries caches the registries read from the cargo config by the sparse index URL
+// The config.json of the index is fetched once per process.
+var cratesRegistries sync.Map
+
+// cratesIndex returns the name and the index URL of the registry replacing crates.io in the cargo config
+// The index is empty if crates.io is not replaced.
+func cratesIndex(cfg cargoConfig) (string, string) {
+	// Follow the chain of source replacements
+	name := "crates-io"
+	var index string
+	for range len(cfg.Source) + 1 {
+		replacement := cfg.Source[name].ReplaceWith
+		if replacement == "" {
+			break
+		}
+		name = replacement
+		if r, ok := cfg.Registries[name]; ok {
+			index = r.Index
+			break
+		}
+		index = cfg.Source[name].Registry
+	}
+	return name, index
+}
+
+// cratesRegistry returns the API base URL of the crates.io registry and its token
+// MIRU_CRATES_URL takes precedence. Otherwise, the sparse registry replacing crates.io
+// in the cargo config is used, whose API URL is read from config.json of the index.
+func cratesRegistry() (string, string, error) {
+	if v := os.Getenv("MIRU_CRATES_URL"); v != "" {
+		return strings.TrimSuffix(v, "/"), os.Getenv("MIRU_CRATES_TOKEN"), nil
+	}
+
+	const defaultAPI = "https://crates.io"
+	cfg, err := loadCargoConfig(cargoHome())
+	if err != nil {
+		return "", "", err
+	}
+	name, index := cratesIndex(cfg)
+	if !strings.HasPrefix(index, "sparse+") {
+		// Only sparse indexes tell the API URL without cloning the index
+		return defaultAPI, "", nil
+	}
+
+	token := os.Getenv("CARGO_REGISTRIES_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_TOKEN")
+	if token == "" {
+		token = cfg.Registries[name].Token
+	}
+
+	if api, ok := cratesRegistries.Load(index + "\x00" + token); ok {
+		return api.(cratesAPI).base, api.(cratesAPI).token, nil
+	}
+	api, err := fetchCratesAPI(name, index, token)
+	if err != nil {
+		return "", "", err
+	}
+	cratesRegistries.Store(index+"\x00"+token, api)
+	return api.base, api.token, nil
+}
+
+// fetchCratesAPI reads the API base URL of the sparse registry from config.json of the index
+func fetchCratesAPI(name, index, token string) (cratesAPI, error) {
+	const defaultAPI = "https://crates.io"
+	configURL := strings.TrimSuffix(strings.TrimPrefix(index, "sparse+"), "/") + "/config.json"
+	resp, err := registryGet(configURL, token)
+	if err != nil {
+		return cratesAPI{}, err
+	}
+	defer resp.Body.Close()
+
+	var indexConfig struct {
+		API string `json:"api"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		return cratesAPI{}, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch the config of the cargo registry"),
+			failure.Context{
+				"registry": name,
+				"url":      redactURL(configURL),
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&indexConfig); err != nil {
+		return cratesAPI{}, failure.Wrap(err)
+	}
+	if indexConfig.API == "" {
+		return cratesAPI{base: defaultAPI}, nil
+	}
+	return cratesAPI{base: strings.TrimSuffix(indexConfig.API, "/"), token: token}, nil
+}
+
+// registryScope returns the registry configured for the package of the source type instead of the public one
+// It is empty for the public registry. The registry is part of the cache key of the fetched data,
+// so that switching to a mirror or a private registry does not serve the data fetched from another one.
+func registryScope(t source.Type, packagePath string) string {
+	switch t {
+	case source.TypeNPM:
+		if r := loadNpmConfig().registry(packagePath); r != defaultNpmRegistry {
+			return redactURL(r)
+		}
+	case source.TypePyPI:
+		if base := pypiBaseURL(); base != defaultPyPIURL {
+			return redactURL(base)
+		}
+	case source.TypeRubyGems:
+		if base := registryBaseURL("MIRU_RUBYGEMS_URL", defaultRubyGemsURL); base != defaultRubyGemsURL {
+			return redactURL(base)
+		}
+	case source.TypePackagist:
+		if base := registryBaseURL("MIRU_PACKAGIST_URL", defaultPackagistURL); base != defaultPackagistURL {
+			return redactURL(base)
+		}
+	case source.TypeCratesIO:
+		if v := os.Getenv("MIRU_CRATES_URL"); v != "" {
+			return redactURL(strings.TrimSuffix(v, "/"))
+		}
+		if cfg, err := loadCargoConfig(cargoHome()); err == nil {
+			if _, index := cratesIndex(cfg); strings.HasPrefix(index, "sparse+") {
+				return redactURL(strings.TrimPrefix(index, "sparse+"))
+			}
+		}
+	case source.TypeGoPkgDev:
+		if list := goEnv("GOPROXY"); list != "" && list != defaultGoProxy {
+			var proxies []string
+			for _, p := range goProxies(packagePath) {
+				proxies = append(proxies, redactURL(p.URL))
+			}
+			return strings.Join(proxies, ",")
+		}
+	}
+	return ""
+}
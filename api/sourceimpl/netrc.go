@@ -0,0 +1,105 @@
+package sourceimpl
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcEntry represents a machine entry of a .netrc file
+type netrcEntry struct {
+	Machine  string // Empty for the "default" entry
+	Login    string
+	Password string
+}
+
+// netrcPath returns the path of the .netrc file
+// The NETRC environment variable takes precedence, as in curl and git.
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// parseNetrc parses the content of a .netrc file
+// Macro definitions (macdef) are skipped until the following blank line.
+func parseNetrc(content string) []netrcEntry {
+	var entries []netrcEntry
+	var current *netrcEntry
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			if strings.HasPrefix(fields[j], "#") {
+				break
+			}
+			value := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+			switch fields[j] {
+			case "machine":
+				entries = append(entries, netrcEntry{Machine: value()})
+				current = &entries[len(entries)-1]
+			case "default":
+				entries = append(entries, netrcEntry{})
+				current = &entries[len(entries)-1]
+			case "login":
+				if current != nil {
+					current.Login = value()
+				}
+			case "password":
+				if current != nil {
+					current.Password = value()
+				}
+			case "macdef":
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	return entries
+}
+
+// netrcCredentials returns the login and password for the host from the .netrc file
+// The "default" entry is used when no machine matches.
+func netrcCredentials(host string) (string, string, bool) {
+	path := netrcPath()
+	if path == "" {
+		return "", "", false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+
+	var fallback *netrcEntry
+	entries := parseNetrc(string(b))
+	for i, e := range entries {
+		if e.Machine == host {
+			return e.Login, e.Password, true
+		}
+		if e.Machine == "" && fallback == nil {
+			fallback = &entries[i]
+		}
+	}
+	if fallback != nil {
+		return fallback.Login, fallback.Password, true
+	}
+	return "", "", false
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -25,9 +27,12 @@ type npmPackageInfo struct {
 // fetchNPM fetches the README content from npm registry
 // Returns the content, related sources, and any error
 func fetchNPM(pkgPath string) (string, []source.RelatedReference, error) {
-	// Get package information from npm registry
-	url := fmt.Sprintf("https://registry.npmjs.org/%s", pkgPath)
-	resp, err := http.Get(url)
+	// Get package information from the npm registry configured for the package
+	// The slash of scoped packages must be encoded, e.g. @scope%2fname
+	cfg := loadNpmConfig()
+	registry := cfg.registry(pkgPath)
+	url := fmt.Sprintf("%s/%s", registry, strings.Replace(pkgPath, "/", "%2f", 1))
+	resp, err := registryGet(url, cfg.authorization(registry))
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -37,8 +42,10 @@ func fetchNPM(pkgPath string) (string, []source.RelatedReference, error) {
 		return "", nil, failure.New(ErrRepositoryNotFound,
 			failure.Message("Failed to fetch package information from npm registry"),
 			failure.Context{
-				"pkg": pkgPath,
+				"pkg":      pkgPath,
+				"registry": redactURL(registry),
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -87,6 +94,24 @@ func fetchNPM(pkgPath string) (string, []source.RelatedReference, error) {
 	return info.Readme, sources, nil
 }
 
+// npmRegistration declares the npm source
+var npmRegistration = investigator.Registration{
+	Type:         source.TypeNPM,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"js", "javascript", "npm", "node", "nodejs", "ts", "tsx", "typescript"},
+	Hosts:        []string{"npmjs.com"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?npmjs\.com/package/([^/\s]+)`),
+			Command:     regexp.MustCompile(`(?:npm|yarn|pnpm) (?:add|install|create) ([^@\s]+)`),
+			Description: "NPM package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &NPMInvestigator{}
+	},
+}
+
 // Implementation of NPM Investigator
 type NPMInvestigator struct{}
 
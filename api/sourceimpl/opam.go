@@ -0,0 +1,408 @@
+package sourceimpl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrOpamPackageNotFound represents an error when the package is not found in the opam repository
+	ErrOpamPackageNotFound ErrorCode = "OpamPackageNotFound"
+)
+
+// parseOpamFile parses an opam file and returns the string values of each top-level field
+// See: https://opam.ocaml.org/doc/Manual.html#Common-file-format
+func parseOpamFile(content string) map[string][]string {
+	fields := map[string][]string{}
+	var field string
+	depth := 0
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '#':
+			// Line comment
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(content[i:], "(*"):
+			// Block comment
+			end := strings.Index(content[i+2:], "*)")
+			if end == -1 {
+				return fields
+			}
+			i += end + 4
+		case strings.HasPrefix(content[i:], `"""`):
+			// Triple-quoted string
+			end := strings.Index(content[i+3:], `"""`)
+			if end == -1 {
+				return fields
+			}
+			if field != "" {
+				fields[field] = append(fields[field], strings.TrimSpace(content[i+3:i+3+end]))
+			}
+			i += end + 6
+		case c == '"':
+			// Quoted string with backslash escapes
+			var b strings.Builder
+			i++
+			for i < len(content) && content[i] != '"' {
+				if content[i] == '\\' && i+1 < len(content) {
+					i++
+				}
+				b.WriteByte(content[i])
+				i++
+			}
+			i++
+			if field != "" {
+				fields[field] = append(fields[field], b.String())
+			}
+		case c == '[' || c == '{' || c == '(':
+			depth++
+			i++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+			i++
+		case depth == 0 && isOpamIdentChar(c):
+			// A field starts with an identifier followed by a colon
+			start := i
+			for i < len(content) && isOpamIdentChar(content[i]) {
+				i++
+			}
+			if i < len(content) && content[i] == ':' {
+				field = content[start:i]
+				i++
+			}
+		default:
+			i++
+		}
+	}
+
+	return fields
+}
+
+func isOpamIdentChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' || c == '_'
+}
+
+// opamVersionsFromPage returns the versions linked from the package page of opam.ocaml.org
+// Version pages are linked as "<name>.<version>/".
+func opamVersionsFromPage(name, page string) []string {
+	pattern := regexp.MustCompile(`href="(?:[^"]*/)?` + regexp.QuoteMeta(name) + `\.([^/"]+)/?"`)
+	var versions []string
+	seen := map[string]bool{}
+	for _, m := range pattern.FindAllStringSubmatch(page, -1) {
+		if v := m[1]; !seen[v] {
+			seen[v] = true
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// compareOpamVersions compares opam package versions in the order of opam, which is the order of Debian versions
+// "~" sorts before anything, even the end of the version, so "1.0~beta" is older than "1.0", while "1.0+dune" and "1.0a" are newer.
+func compareOpamVersions(a, b string) int {
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	for a != "" || b != "" {
+		// Non-digit parts are compared character by character
+		var na, nb string
+		na, a = cutOpamVersionPart(a, func(r rune) bool { return !isDigit(r) })
+		nb, b = cutOpamVersionPart(b, func(r rune) bool { return !isDigit(r) })
+		for i := 0; i < len(na) || i < len(nb); i++ {
+			if oa, ob := opamCharOrder(na, i), opamCharOrder(nb, i); oa != ob {
+				return oa - ob
+			}
+		}
+
+		// Digit parts are compared numerically
+		var da, db string
+		da, a = cutOpamVersionPart(a, isDigit)
+		db, b = cutOpamVersionPart(b, isDigit)
+		da, db = strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+		if len(da) != len(db) {
+			return len(da) - len(db)
+		}
+		if c := strings.Compare(da, db); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// cutOpamVersionPart returns the leading characters of the version satisfying f, and the rest
+func cutOpamVersionPart(v string, f func(rune) bool) (string, string) {
+	i := strings.IndexFunc(v, func(r rune) bool { return !f(r) })
+	if i < 0 {
+		return v, ""
+	}
+	return v[:i], v[i:]
+}
+
+// opamCharOrder returns the order of the character at i of the non-digit part of a version
+// "~" sorts before the end of the part, and letters before other characters.
+func opamCharOrder(part string, i int) int {
+	if i >= len(part) {
+		return 0
+	}
+	c := part[i]
+	switch {
+	case c == '~':
+		return -1
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// fetchOpam fetches the opam file of the latest version from opam.ocaml.org
+// Returns the content, related sources, and any error
+func fetchOpam(pkgPath string) (string, []source.RelatedReference, error) {
+	// List the released versions from the package page
+	pageURL := fmt.Sprintf("https://opam.ocaml.org/packages/%s/", pkgPath)
+	resp, err := http.Get(pageURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrOpamPackageNotFound,
+			failure.Message("Failed to fetch package page from opam.ocaml.org"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	var version string
+	for _, v := range opamVersionsFromPage(pkgPath, string(page)) {
+		if version == "" || compareOpamVersions(v, version) > 0 {
+			version = v
+		}
+	}
+	if version == "" {
+		return "", nil, failure.New(ErrOpamPackageNotFound,
+			failure.Message("No versions published for the package"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+		)
+	}
+
+	// Get the opam file of the latest version, served as a static file of the repository
+	opamURL := fmt.Sprintf("https://opam.ocaml.org/packages/%s/%s.%s/opam", pkgPath, pkgPath, version)
+	opamResp, err := http.Get(opamURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer opamResp.Body.Close()
+
+	if opamResp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch opam file from opam.ocaml.org"),
+			failure.Context{
+				"pkg": pkgPath,
+				"url": opamURL,
+			},
+			httpStatus(opamResp.StatusCode),
+		)
+	}
+
+	body, err := io.ReadAll(opamResp.Body)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	fields := parseOpamFile(string(body))
+
+	first := func(name string) string {
+		if v := fields[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	homepage := first("homepage")
+	docURL := first("doc")
+	devRepo := first("dev-repo")
+
+	doc := formatOpamDoc(pkgPath, version, fields)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add homepage if available
+	if homepage != "" {
+		detected := source.DetectSourceTypeFromURL(homepage)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(homepage, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Add documentation if available
+	if docURL != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.TypeDocumentation,
+			URL:  docURL,
+			From: "api",
+		})
+	}
+
+	// Add repository if available
+	if devRepo != "" {
+		sources = append(sources, source.RelatedReference{
+			Type: source.DetectSourceTypeFromURL(devRepo),
+			URL:  cleanupURL(devRepo, source.TypeUnknown),
+			From: "api",
+		})
+	}
+
+	// Extract additional sources from documentation
+	docSources := extractRelatedSources(doc, pkgPath)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// formatOpamDoc formats the opam file fields into a markdown document
+func formatOpamDoc(name, version string, fields map[string][]string) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s v%s", name, version))
+
+	// Description
+	if v := fields["synopsis"]; len(v) > 0 {
+		sections = append(sections, v[0])
+	}
+	if v := fields["description"]; len(v) > 0 {
+		sections = append(sections, v[0])
+	}
+
+	// Metadata
+	var metadata []string
+	if v := fields["license"]; len(v) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", strings.Join(v, ", ")))
+	}
+	if v := fields["authors"]; len(v) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Authors:** %s", strings.Join(v, ", ")))
+	}
+	if v := fields["maintainer"]; len(v) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Maintainer:** %s", strings.Join(v, ", ")))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	var links []string
+	if v := fields["homepage"]; len(v) > 0 {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", v[0]))
+	}
+	if v := fields["doc"]; len(v) > 0 {
+		links = append(links, fmt.Sprintf("**Documentation:** %s", v[0]))
+	}
+	if v := fields["dev-repo"]; len(v) > 0 {
+		links = append(links, fmt.Sprintf("**Repository:** %s", v[0]))
+	}
+	if v := fields["bug-reports"]; len(v) > 0 {
+		links = append(links, fmt.Sprintf("**Bug Reports:** %s", v[0]))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// opamRegistration declares the opam source
+var opamRegistration = investigator.Registration{
+	Type:         source.TypeOpam,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"ocaml", "ml", "opam"},
+	Hosts:        []string{"opam.ocaml.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://opam\.ocaml\.org/packages/([^/\s)]+)`),
+			Command:     regexp.MustCompile(`opam install ([^-.\s][^\s]*)`),
+			Description: "OCaml package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &OpamInvestigator{}
+	},
+}
+
+// Implementation of opam Investigator
+type OpamInvestigator struct{}
+
+func (i *OpamInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from opam.ocaml.org
+	content, relatedSources, err := fetchOpam(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *OpamInvestigator) GetURL(packagePath string) string {
+	return fmt.Sprintf("https://opam.ocaml.org/packages/%s/", packagePath)
+}
+
+func (i *OpamInvestigator) GetSourceType() source.Type {
+	return source.TypeOpam
+}
+
+func (i *OpamInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from opam URL
+	// Example: https://opam.ocaml.org/packages/lwt/ -> lwt
+	prefix := "https://opam.ocaml.org/packages/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := strings.TrimSuffix(url[len(prefix):], "/")
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid opam package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
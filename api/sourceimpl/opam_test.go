@@ -0,0 +1,88 @@
+package sourceimpl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseOpamFile(t *testing.T) {
+	content := readTestFile(t, "opam_lwt.opam")
+
+	got := parseOpamFile(content)
+
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{field: "synopsis", want: []string{"Promises and event-driven I/O"}},
+		{field: "description", want: []string{"A promise is a value that may become determined in the future.\n\nLwt provides typed, composable promises."}},
+		{field: "maintainer", want: []string{"Raphaël Proust <code@bnwr.net>", "Anton Bachin <antonbachin@yahoo.com>"}},
+		{field: "license", want: []string{"MIT"}},
+		{field: "homepage", want: []string{"https://github.com/ocsigen/lwt"}},
+		{field: "doc", want: []string{"https://ocsigen.org/lwt"}},
+		{field: "dev-repo", want: []string{"git+https://github.com/ocsigen/lwt.git"}},
+		{field: "depends", want: []string{"cppo", "1.1.0", "dune", "1.8.0", "ocaml", "4.08"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, got[tt.field]); diff != "" {
+				t.Errorf("parseOpamFile()[%q] mismatch (-want +got):\n%s", tt.field, diff)
+			}
+		})
+	}
+}
+
+func TestOpamVersionsFromPage(t *testing.T) {
+	page := `<h2>lwt <span class="package-version">5.9.1</span></h2>
+<ul class="dropdown-menu">
+  <li><a href="../lwt/lwt.5.9.1/">5.9.1</a></li>
+  <li><a href="../lwt/lwt.5.10.0~beta/">5.10.0~beta</a></li>
+  <li><a href="/packages/lwt/lwt.4.5.0">4.5.0</a></li>
+  <li><a href="/packages/lwt/lwt.5.9.1/">5.9.1</a></li>
+</ul>
+<a href="/packages/lwt_ppx/lwt_ppx.5.9.1/">lwt_ppx</a>
+<a href="/packages/base/">base</a>
+<a href="/packages/conf-lwt/conf-lwt.1.0/">conf-lwt</a>`
+
+	want := []string{"5.9.1", "5.10.0~beta", "4.5.0"}
+	if diff := cmp.Diff(want, opamVersionsFromPage("lwt", page)); diff != "" {
+		t.Errorf("opamVersionsFromPage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareOpamVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "5.10.0", b: "5.9.1", want: 1},
+		{a: "1.0", b: "1.0", want: 0},
+		{a: "1.01", b: "1.1", want: 0},
+		{a: "1.0", b: "1.0.1", want: -1},
+		{a: "1.0~beta", b: "1.0", want: -1},
+		{a: "1.0~alpha", b: "1.0~beta", want: -1},
+		{a: "1.0~~", b: "1.0~", want: -1},
+		{a: "5.10.0~beta", b: "5.9.1", want: 1},
+		{a: "1.0+dune", b: "1.0", want: 1},
+		{a: "1.0+dune", b: "1.0.1", want: -1},
+		{a: "1.0a", b: "1.0", want: 1},
+		{a: "1.0a", b: "1.0b", want: -1},
+		{a: "1.0a", b: "1.0+a", want: -1},
+		{a: "v0.16.0", b: "v0.15.1", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got := compareOpamVersions(tt.a, tt.b)
+			if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+				t.Errorf("compareOpamVersions(%q, %q) = %v, want sign of %v", tt.a, tt.b, got, tt.want)
+			}
+			// The order is antisymmetric
+			if rev := compareOpamVersions(tt.b, tt.a); (rev > 0) != (got < 0) || (rev < 0) != (got > 0) {
+				t.Errorf("compareOpamVersions(%q, %q) = %v, inconsistent with %v", tt.b, tt.a, rev, got)
+			}
+		})
+	}
+}
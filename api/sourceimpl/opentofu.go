@@ -0,0 +1,327 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+// openTofuDocsAPI is the base URL of the documentation API behind search.opentofu.org
+// registry.opentofu.org only serves the download protocol, so descriptions and READMEs are read from here.
+const openTofuDocsAPI = "https://api.opentofu.org/registry/docs"
+
+// openTofuVersion represents a version listed by the OpenTofu registry
+type openTofuVersion struct {
+	ID        string `json:"id"`
+	Published string `json:"published"`
+}
+
+// openTofuModule represents the OpenTofu registry API response for a module
+type openTofuModule struct {
+	Description string            `json:"description"`
+	Versions    []openTofuVersion `json:"versions"`
+}
+
+// openTofuModuleVersion represents the OpenTofu registry API response for a module version
+type openTofuModuleVersion struct {
+	Readme    bool `json:"readme"`
+	Variables map[string]struct {
+		Type        string          `json:"type"`
+		Default     json.RawMessage `json:"default"`
+		Description string          `json:"description"`
+		Required    bool            `json:"required"`
+	} `json:"variables"`
+	Outputs map[string]struct {
+		Description string `json:"description"`
+	} `json:"outputs"`
+	Providers []struct {
+		FullName          string `json:"full_name"`
+		VersionConstraint string `json:"version_constraint"`
+	} `json:"providers"`
+	Submodules    map[string]json.RawMessage `json:"submodules"`
+	VCSRepository string                     `json:"vcs_repository"`
+}
+
+// openTofuProvider represents the OpenTofu registry API response for a provider
+type openTofuProvider struct {
+	Description string            `json:"description"`
+	Link        string            `json:"link"`
+	Versions    []openTofuVersion `json:"versions"`
+}
+
+// latestOpenTofuVersion returns the highest version and the IDs of all versions without the "v" prefix
+func latestOpenTofuVersion(versions []openTofuVersion) (openTofuVersion, []string) {
+	var latest openTofuVersion
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, strings.TrimPrefix(v.ID, "v"))
+		if latest.ID == "" || compareVersions(v.ID, latest.ID) > 0 {
+			latest = v
+		}
+	}
+	return latest, ids
+}
+
+// getOpenTofuDocs fetches a document of the OpenTofu registry API and decodes it into out if it is not nil
+// Returns the body of the document.
+func getOpenTofuDocs(reqpath, pkg string, out any) (string, error) {
+	resp, err := http.Get(openTofuDocsAPI + reqpath)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", failure.New(ErrTerraformNotFound,
+			failure.Message("Failed to fetch documentation from the OpenTofu registry"),
+			failure.Context{
+				"pkg":  pkg,
+				"path": reqpath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return "", failure.Wrap(err)
+		}
+	}
+	return string(body), nil
+}
+
+// fetchOpenTofu fetches the module or provider documentation from the OpenTofu registry
+// Returns the content, related sources, and any error
+func fetchOpenTofu(pkgPath string) (string, []source.RelatedReference, error) {
+	parts, err := splitTerraformAddress(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(parts) == 2 {
+		return fetchOpenTofuProvider(parts[0], parts[1])
+	}
+	return fetchOpenTofuModule(parts[0], parts[1], parts[2])
+}
+
+// fetchOpenTofuModule fetches the module README, inputs and outputs from the OpenTofu registry
+func fetchOpenTofuModule(namespace, name, target string) (string, []source.RelatedReference, error) {
+	pkg := fmt.Sprintf("%s/%s/%s", namespace, name, target)
+
+	var module openTofuModule
+	if _, err := getOpenTofuDocs(fmt.Sprintf("/modules/%s/index.json", pkg), pkg, &module); err != nil {
+		return "", nil, err
+	}
+	latest, versions := latestOpenTofuVersion(module.Versions)
+	if latest.ID == "" {
+		return "", nil, failure.New(ErrTerraformNotFound,
+			failure.Message("No versions published for the module"),
+			failure.Context{"pkg": pkg},
+		)
+	}
+
+	var version openTofuModuleVersion
+	if _, err := getOpenTofuDocs(fmt.Sprintf("/modules/%s/%s/index.json", pkg, latest.ID), pkg, &version); err != nil {
+		return "", nil, err
+	}
+	var readme string
+	if version.Readme {
+		// The module is still documented by its inputs and outputs without the README
+		readme, _ = getOpenTofuDocs(fmt.Sprintf("/modules/%s/%s/README.md", pkg, latest.ID), pkg, nil)
+	}
+
+	m := newOpenTofuTerraformModule(namespace, name, target, module, latest, version)
+	m.Versions = versions
+	m.Root.Readme = readme
+
+	doc := formatTerraformModuleDoc(m)
+
+	// Extract related sources
+	sources := terraformSourceReferences(m.Source)
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(readme, name)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// newOpenTofuTerraformModule converts a module of the OpenTofu registry to the Terraform Registry representation
+// Inputs, outputs and submodules are sorted by name, as the OpenTofu registry returns them as objects.
+func newOpenTofuTerraformModule(namespace, name, target string, module openTofuModule, latest openTofuVersion, version openTofuModuleVersion) terraformModule {
+	m := terraformModule{
+		Namespace:   namespace,
+		Name:        name,
+		Provider:    target,
+		Version:     strings.TrimPrefix(latest.ID, "v"),
+		Description: module.Description,
+		Source:      version.VCSRepository,
+		PublishedAt: latest.Published,
+	}
+
+	for n, v := range version.Variables {
+		def := string(v.Default)
+		if def == "" {
+			def = "null"
+		}
+		m.Root.Inputs = append(m.Root.Inputs, terraformModuleInput{
+			Name:        n,
+			Type:        v.Type,
+			Description: v.Description,
+			Default:     def,
+			Required:    v.Required,
+		})
+	}
+	sort.Slice(m.Root.Inputs, func(i, j int) bool { return m.Root.Inputs[i].Name < m.Root.Inputs[j].Name })
+
+	for n, o := range version.Outputs {
+		m.Root.Outputs = append(m.Root.Outputs, terraformModuleOutput{Name: n, Description: o.Description})
+	}
+	sort.Slice(m.Root.Outputs, func(i, j int) bool { return m.Root.Outputs[i].Name < m.Root.Outputs[j].Name })
+
+	for _, p := range version.Providers {
+		m.Root.ProviderDependencies = append(m.Root.ProviderDependencies, terraformProviderDependency{
+			Source:  strings.TrimPrefix(p.FullName, "registry.opentofu.org/"),
+			Version: p.VersionConstraint,
+		})
+	}
+
+	var submodules []string
+	for n := range version.Submodules {
+		submodules = append(submodules, n)
+	}
+	sort.Strings(submodules)
+	for _, n := range submodules {
+		m.Submodules = append(m.Submodules, terraformSubmodule{Path: "modules/" + n})
+	}
+
+	return m
+}
+
+// fetchOpenTofuProvider fetches the provider overview from the OpenTofu registry
+func fetchOpenTofuProvider(namespace, name string) (string, []source.RelatedReference, error) {
+	pkg := fmt.Sprintf("%s/%s", namespace, name)
+
+	var provider openTofuProvider
+	if _, err := getOpenTofuDocs(fmt.Sprintf("/providers/%s/index.json", pkg), pkg, &provider); err != nil {
+		return "", nil, err
+	}
+	latest, versions := latestOpenTofuVersion(provider.Versions)
+	if latest.ID == "" {
+		return "", nil, failure.New(ErrTerraformNotFound,
+			failure.Message("No versions published for the provider"),
+			failure.Context{"pkg": pkg},
+		)
+	}
+
+	// The overview is the index page of the provider docs
+	overview, _ := getOpenTofuDocs(fmt.Sprintf("/providers/%s/%s/index.md", pkg, latest.ID), pkg, nil)
+
+	doc := formatTerraformProviderDoc(terraformProvider{
+		Namespace:   namespace,
+		Name:        name,
+		Version:     strings.TrimPrefix(latest.ID, "v"),
+		Description: provider.Description,
+		Source:      provider.Link,
+		PublishedAt: latest.Published,
+		Versions:    versions,
+	}, overview)
+
+	// Extract related sources
+	sources := terraformSourceReferences(provider.Link)
+
+	return doc, sources, nil
+}
+
+// openTofuRegistration declares the OpenTofu source
+var openTofuRegistration = investigator.Registration{
+	Type:         source.TypeOpenTofu,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"opentofu", "tofu"},
+	Hosts:        []string{"registry.opentofu.org", "search.opentofu.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://search\.opentofu\.org/module/([^/\s]+/[^/\s]+/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`source\s*=\s*"registry\.opentofu\.org/([\w-]+/[\w-]+/[\w-]+)"`),
+			Description: "OpenTofu module reference",
+		},
+		{
+			URL:         regexp.MustCompile(`https?://search\.opentofu\.org/provider/([^/\s]+/[^/\s)]+)`),
+			Description: "OpenTofu provider reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &OpenTofuInvestigator{}
+	},
+}
+
+// Implementation of OpenTofu Investigator
+type OpenTofuInvestigator struct{}
+
+func (i *OpenTofuInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the OpenTofu registry
+	content, relatedSources, err := fetchOpenTofu(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *OpenTofuInvestigator) GetURL(packagePath string) string {
+	parts, err := splitTerraformAddress(packagePath)
+	if err != nil {
+		return fmt.Sprintf("https://search.opentofu.org/?q=%s", url.QueryEscape(packagePath))
+	}
+	if len(parts) == 2 {
+		return fmt.Sprintf("https://search.opentofu.org/provider/%s/latest", strings.Join(parts, "/"))
+	}
+	return fmt.Sprintf("https://search.opentofu.org/module/%s/latest", strings.Join(parts, "/"))
+}
+
+func (i *OpenTofuInvestigator) GetSourceType() source.Type {
+	return source.TypeOpenTofu
+}
+
+func (i *OpenTofuInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from OpenTofu registry URL
+	// Example: https://search.opentofu.org/module/terraform-aws-modules/vpc/aws/latest -> terraform-aws-modules/vpc/aws
+	// Example: https://search.opentofu.org/provider/hashicorp/aws/latest -> hashicorp/aws
+	for prefix, n := range map[string]int{
+		"https://search.opentofu.org/module/":   3,
+		"https://search.opentofu.org/provider/": 2,
+	} {
+		if strings.HasPrefix(url, prefix) {
+			parts := strings.Split(url[len(prefix):], "/")
+			if len(parts) < n {
+				return "", failure.New(ErrInvalidPackagePath,
+					failure.Message("Invalid OpenTofu registry path"),
+					failure.Context{"url": url},
+				)
+			}
+			return strings.Join(parts[:n], "/"), nil
+		}
+	}
+	return url, nil
+}
@@ -0,0 +1,96 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLatestOpenTofuVersion(t *testing.T) {
+	latest, versions := latestOpenTofuVersion([]openTofuVersion{
+		{ID: "v5.9.0", Published: "2024-06-01T00:00:00Z"},
+		{ID: "v5.13.0", Published: "2024-08-01T00:00:00Z"},
+		{ID: "v5.10.0", Published: "2024-07-01T00:00:00Z"},
+	})
+
+	if diff := cmp.Diff(openTofuVersion{ID: "v5.13.0", Published: "2024-08-01T00:00:00Z"}, latest); diff != "" {
+		t.Errorf("latestOpenTofuVersion() latest mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"5.9.0", "5.13.0", "5.10.0"}, versions); diff != "" {
+		t.Errorf("latestOpenTofuVersion() versions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewOpenTofuTerraformModule(t *testing.T) {
+	var version openTofuModuleVersion
+	err := json.Unmarshal([]byte(`{
+  "readme": true,
+  "variables": {
+    "tags": {"type": "map(string)", "description": "A map of tags", "required": true},
+    "name": {"type": "string", "default": "", "description": "Name of the VPC", "required": false},
+    "cidr": {"type": "string", "default": "10.0.0.0/16", "required": false}
+  },
+  "outputs": {
+    "vpc_id": {"description": "The ID of the VPC"},
+    "azs": {"description": "A list of availability zones"}
+  },
+  "providers": [{"full_name": "registry.opentofu.org/hashicorp/aws", "version_constraint": ">= 5.46"}],
+  "submodules": {"vpc-endpoints": {}},
+  "vcs_repository": "https://github.com/terraform-aws-modules/terraform-aws-vpc"
+}`), &version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	module := openTofuModule{Description: "Terraform module to create AWS VPC resources"}
+	latest := openTofuVersion{ID: "v5.13.0", Published: "2024-08-01T00:00:00Z"}
+	m := newOpenTofuTerraformModule("terraform-aws-modules", "vpc", "aws", module, latest, version)
+
+	want := "# terraform-aws-modules/vpc/aws v5.13.0\n\n" +
+		"Terraform module to create AWS VPC resources\n\n" +
+		"**Published:** 2024-08-01T00:00:00Z\n\n" +
+		"**Source:** https://github.com/terraform-aws-modules/terraform-aws-vpc\n\n" +
+		"## Inputs\n\n" +
+		"| Name | Description | Type | Default | Required |\n" +
+		"|------|-------------|------|---------|:--------:|\n" +
+		"| `cidr` |  | `string` | `\"10.0.0.0/16\"` | no |\n" +
+		"| `name` | Name of the VPC | `string` | `\"\"` | no |\n" +
+		"| `tags` | A map of tags | `map(string)` | n/a | yes |\n\n" +
+		"## Outputs\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| `azs` | A list of availability zones |\n" +
+		"| `vpc_id` | The ID of the VPC |\n\n" +
+		"## Providers\n\n" +
+		"- `hashicorp/aws` (>= 5.46)\n\n" +
+		"## Submodules\n\n" +
+		"- `modules/vpc-endpoints`"
+
+	if diff := cmp.Diff(want, formatTerraformModuleDoc(m)); diff != "" {
+		t.Errorf("formatTerraformModuleDoc() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOpenTofuPackageFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://search.opentofu.org/module/terraform-aws-modules/vpc/aws/latest", want: "terraform-aws-modules/vpc/aws"},
+		{url: "https://search.opentofu.org/provider/hashicorp/aws/v5.70.0", want: "hashicorp/aws"},
+	}
+
+	i := &OpenTofuInvestigator{}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := i.PackageFromURL(tt.url)
+			if err != nil {
+				t.Fatalf("PackageFromURL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("PackageFromURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrExtensionNotFound represents an error when the extension is not found in the registry
+	ErrExtensionNotFound ErrorCode = "ExtensionNotFound"
+)
+
+// openVSXExtension represents the Open VSX API response for an extension
+type openVSXExtension struct {
+	Namespace     string            `json:"namespace"`
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	DisplayName   string            `json:"displayName"`
+	Description   string            `json:"description"`
+	License       string            `json:"license"`
+	Homepage      string            `json:"homepage"`
+	Repository    string            `json:"repository"`
+	Categories    []string          `json:"categories"`
+	DownloadCount int               `json:"downloadCount"`
+	Timestamp     string            `json:"timestamp"`
+	AllVersions   map[string]string `json:"allVersions"`
+	Files         struct {
+		Readme    string `json:"readme"`
+		Changelog string `json:"changelog"`
+	} `json:"files"`
+}
+
+// openVSXRegistry returns the base URL of the Open VSX-compatible registry
+func openVSXRegistry() string {
+	if v := os.Getenv("MIRU_OPENVSX_URL"); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return "https://open-vsx.org"
+}
+
+// splitExtensionID splits an extension ID into the namespace (publisher) and name
+// Accepts both the VS Code style "publisher.name" and "publisher/name".
+// Example: golang.go -> golang, go
+func splitExtensionID(id string) (string, string, error) {
+	namespace, name, ok := strings.Cut(id, "/")
+	if !ok {
+		namespace, name, ok = strings.Cut(id, ".")
+	}
+	if !ok || namespace == "" || name == "" {
+		return "", "", failure.New(ErrInvalidPackagePath,
+			failure.Message("Extension ID must be formatted as 'publisher.name'"),
+			failure.Context{"path": id},
+		)
+	}
+	return namespace, name, nil
+}
+
+// fetchOpenVSXFile fetches a text file of the extension such as README or CHANGELOG
+// Returns an empty string if the file is not available.
+func fetchOpenVSXFile(fileURL string) (string, error) {
+	if fileURL == "" {
+		return "", nil
+	}
+	resp, err := http.Get(fileURL)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", failure.Wrap(err)
+	}
+	return string(b), nil
+}
+
+// fetchOpenVSX fetches the extension information, README and CHANGELOG from the Open VSX registry
+// Returns the content, related sources, and any error
+func fetchOpenVSX(pkgPath string) (string, []source.RelatedReference, error) {
+	namespace, name, err := splitExtensionID(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Get extension information from Open VSX API
+	apiURL := fmt.Sprintf("%s/api/%s/%s", openVSXRegistry(), url.PathEscape(namespace), url.PathEscape(name))
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrExtensionNotFound,
+			failure.Message("Failed to fetch extension from the Open VSX registry"),
+			failure.Context{
+				"pkg": pkgPath,
+				"url": apiURL,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var ext openVSXExtension
+	if err := json.NewDecoder(resp.Body).Decode(&ext); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	readme, err := fetchOpenVSXFile(ext.Files.Readme)
+	if err != nil {
+		return "", nil, err
+	}
+	changelog, err := fetchOpenVSXFile(ext.Files.Changelog)
+	if err != nil {
+		return "", nil, err
+	}
+
+	doc := formatOpenVSXDoc(ext, readme, changelog)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add repository if available
+	if ext.Repository != "" {
+		detected := source.DetectSourceTypeFromURL(ext.Repository)
+		if detected.IsRepository() {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(ext.Repository, detected),
+				From: "api",
+			})
+		}
+	}
+
+	// Add homepage if available and different from the repository
+	if ext.Homepage != "" && ext.Homepage != ext.Repository {
+		detected := source.DetectSourceTypeFromURL(ext.Homepage)
+		if detected != source.TypeUnknown {
+			// Add as repository if the URL is from GitHub/GitLab
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(ext.Homepage, detected),
+				From: "api",
+			})
+		} else {
+			// Add as homepage for other URLs
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  ext.Homepage,
+				From: "api",
+			})
+		}
+	}
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(readme, name)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// openVSXVersions returns the published versions, newest first
+// allVersions also contains the "latest" and "pre-release" aliases, which are skipped.
+func openVSXVersions(allVersions map[string]string) []string {
+	var versions []string
+	for v := range allVersions {
+		if v == "latest" || v == "pre-release" {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, func(a, b string) int {
+		return compareVersions(b, a)
+	})
+	return versions
+}
+
+// formatOpenVSXDoc formats the extension information into a markdown document
+func formatOpenVSXDoc(ext openVSXExtension, readme, changelog string) string {
+	var sections []string
+
+	// Title and version
+	title := ext.DisplayName
+	if title == "" {
+		title = ext.Name
+	}
+	sections = append(sections, fmt.Sprintf("# %s v%s", title, ext.Version))
+
+	// Description
+	if ext.Description != "" {
+		sections = append(sections, ext.Description)
+	}
+
+	// Metadata
+	var metadata []string
+	metadata = append(metadata, fmt.Sprintf("**ID:** %s.%s", ext.Namespace, ext.Name))
+	if ext.License != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", ext.License))
+	}
+	metadata = append(metadata, fmt.Sprintf("**Downloads:** %d", ext.DownloadCount))
+	if len(ext.Categories) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Categories:** %s", strings.Join(ext.Categories, ", ")))
+	}
+	sections = append(sections, strings.Join(metadata, " • "))
+
+	// Links
+	var links []string
+	if ext.Homepage != "" {
+		links = append(links, fmt.Sprintf("**Homepage:** %s", ext.Homepage))
+	}
+	if ext.Repository != "" {
+		links = append(links, fmt.Sprintf("**Repository:** %s", ext.Repository))
+	}
+	if len(links) > 0 {
+		sections = append(sections, strings.Join(links, "\n"))
+	}
+
+	// Versions
+	if versions := openVSXVersions(ext.AllVersions); len(versions) > 0 {
+		if len(versions) > 10 {
+			versions = versions[:10]
+		}
+		sections = append(sections, fmt.Sprintf("**Recent Versions:** %s", strings.Join(versions, ", ")))
+	}
+
+	// README content
+	if readme != "" {
+		sections = append(sections, readme)
+	}
+
+	// CHANGELOG content
+	if changelog != "" {
+		sections = append(sections, changelog)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// openVSXRegistration declares the Open VSX source
+var openVSXRegistration = investigator.Registration{
+	Type:         source.TypeOpenVSX,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"ext", "vsix", "openvsx"},
+	Hosts:        []string{"open-vsx.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://open-vsx\.org/extension/([^/\s]+/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`(?:code|codium) --install-extension ([\w-]+\.[\w.-]+)`),
+			Description: "Editor extension reference",
+		},
+		{
+			URL:         regexp.MustCompile(`https?://marketplace\.visualstudio\.com/items\?itemName=([\w-]+\.[\w-]+)`),
+			Description: "VS Code Marketplace extension reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &OpenVSXInvestigator{}
+	},
+}
+
+// Implementation of Open VSX Investigator
+type OpenVSXInvestigator struct{}
+
+func (i *OpenVSXInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from the Open VSX registry
+	content, relatedSources, err := fetchOpenVSX(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *OpenVSXInvestigator) GetURL(packagePath string) string {
+	namespace, name, err := splitExtensionID(packagePath)
+	if err != nil {
+		return fmt.Sprintf("%s/?search=%s", openVSXRegistry(), url.QueryEscape(packagePath))
+	}
+	return fmt.Sprintf("%s/extension/%s/%s", openVSXRegistry(), namespace, name)
+}
+
+func (i *OpenVSXInvestigator) GetSourceType() source.Type {
+	return source.TypeOpenVSX
+}
+
+func (i *OpenVSXInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Open VSX URL
+	// Example: https://open-vsx.org/extension/golang/Go -> golang.Go
+	prefix := openVSXRegistry() + "/extension/"
+	if strings.HasPrefix(url, prefix) {
+		parts := strings.Split(url[len(prefix):], "/")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Open VSX extension path"),
+				failure.Context{"url": url},
+			)
+		}
+		return parts[0] + "." + parts[1], nil
+	}
+	return url, nil
+}
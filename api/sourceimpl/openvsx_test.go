@@ -0,0 +1,96 @@
+package sourceimpl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestSplitExtensionID(t *testing.T) {
+	tests := []struct {
+		id            string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{id: "golang.go", wantNamespace: "golang", wantName: "go"},
+		{id: "rust-lang/rust-analyzer", wantNamespace: "rust-lang", wantName: "rust-analyzer"},
+		{id: "redhat.vscode-yaml", wantNamespace: "redhat", wantName: "vscode-yaml"},
+		{id: "prettier", wantErr: true},
+		{id: ".go", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			namespace, name, err := splitExtensionID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitExtensionID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("splitExtensionID() = %q, %q, want %q, %q", namespace, name, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFetchOpenVSX(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/golang/go":
+			fmt.Fprintf(w, `{
+				"namespace": "golang",
+				"name": "go",
+				"version": "0.42.1",
+				"displayName": "Go",
+				"description": "Rich Go language support",
+				"license": "MIT",
+				"homepage": "https://github.com/golang/vscode-go",
+				"repository": "https://github.com/golang/vscode-go",
+				"downloadCount": 1200,
+				"allVersions": {"latest": "", "0.9.0": "", "0.42.1": "", "0.41.4": ""},
+				"files": {"readme": "%[1]s/files/README.md", "changelog": "%[1]s/files/CHANGELOG.md"}
+			}`, server.URL)
+		case "/files/README.md":
+			fmt.Fprint(w, "# Go for Visual Studio Code")
+		case "/files/CHANGELOG.md":
+			fmt.Fprint(w, "## v0.42.1")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("MIRU_OPENVSX_URL", server.URL)
+
+	doc, sources, err := fetchOpenVSX("golang.go")
+	if err != nil {
+		t.Fatalf("fetchOpenVSX() error = %v", err)
+	}
+
+	wantDoc := "# Go v0.42.1\n\n" +
+		"Rich Go language support\n\n" +
+		"**ID:** golang.go • **License:** MIT • **Downloads:** 1200\n\n" +
+		"**Homepage:** https://github.com/golang/vscode-go\n" +
+		"**Repository:** https://github.com/golang/vscode-go\n\n" +
+		"**Recent Versions:** 0.42.1, 0.41.4, 0.9.0\n\n" +
+		"# Go for Visual Studio Code\n\n" +
+		"## v0.42.1"
+	if diff := cmp.Diff(wantDoc, doc); diff != "" {
+		t.Errorf("fetchOpenVSX() doc mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeGitHub, URL: "https://github.com/golang/vscode-go", From: "api"},
+	}
+	if diff := cmp.Diff(wantSources, sources); diff != "" {
+		t.Errorf("fetchOpenVSX() sources mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := fetchOpenVSX("golang.missing"); err == nil {
+		t.Error("fetchOpenVSX() expected error for unknown extension")
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -38,8 +40,8 @@ type packagistPackageInfo struct {
 // Returns the content, related sources, and any error
 func fetchPackagist(pkgPath string) (string, []source.RelatedReference, error) {
 	// Get package information from Packagist API
-	url := fmt.Sprintf("https://packagist.org/packages/%s.json", pkgPath)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/packages/%s.json", registryBaseURL("MIRU_PACKAGIST_URL", defaultPackagistURL), pkgPath)
+	resp, err := registryGet(url, "")
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -51,6 +53,7 @@ func fetchPackagist(pkgPath string) (string, []source.RelatedReference, error) {
 			failure.Context{
 				"pkg": pkgPath,
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -134,6 +137,24 @@ func fetchPackagist(pkgPath string) (string, []source.RelatedReference, error) {
 	return info.Package.Description, sources, nil
 }
 
+// packagistRegistration declares the Packagist source
+var packagistRegistration = investigator.Registration{
+	Type:         source.TypePackagist,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"php", "packagist", "composer"},
+	Hosts:        []string{"packagist.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?packagist\.org/packages/([^/\s]+/[^/\s]+)`),
+			Command:     regexp.MustCompile(`composer (?:require|install) ([^@\s]+)`),
+			Description: "PHP package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &PackagistInvestigator{}
+	},
+}
+
 // Implementation of Packagist Investigator
 type PackagistInvestigator struct{}
 
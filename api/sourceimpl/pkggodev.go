@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 	"golang.org/x/net/html"
@@ -19,12 +21,24 @@ const (
 // fetchPkgGoDev fetches the README file from pkg.go.dev or the source repository
 func fetchPkgGoDev(pkgPath string) (string, []source.RelatedReference, error) {
 	// https://pkg.go.dev/cmd/go#hdr-Remote_import_paths
+	// Read the README from the module zip served by the Go module proxy
+	content, sources, err := fetchGoModule(pkgPath)
+	if err == nil {
+		return content, sources, nil
+	}
+
+	// Packages in GitHub and GitLab repositories fall back to the README of the repository
+	// when the proxy does not serve them, such as private modules fetched directly
 	if strings.Contains(pkgPath, "github.com/") {
 		return fetchGitHub(pkgPath)
 	} else if strings.Contains(pkgPath, "gitlab.com/") {
 		return fetchGitlab(pkgPath)
 	}
+	if !failure.Is(err, ErrGoProxyDirect, ErrGoModuleNotFound, ErrPkgGoDevREADMENotFound) {
+		return "", nil, err
+	}
 
+	// Fall back to the repository declared by the go-import meta tag
 	repo, home, err := detectGoMetadata(pkgPath, nil)
 	if repo == nil {
 		return "", nil, err
@@ -90,6 +104,89 @@ func fetchPkgGoDev(pkgPath string) (string, []source.RelatedReference, error) {
 	)
 }
 
+// fetchGoModule fetches the documentation of the package through the Go module proxy
+// Packages in a subdirectory of the module show their own README or package documentation,
+// and fall back to the README of the module.
+// The repository is taken from the origin recorded by the proxy, and the homepage from the go-source meta tag if available.
+func fetchGoModule(pkgPath string) (string, []source.RelatedReference, error) {
+	modPath, info, err := resolveGoModule(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+	mod, err := goModuleFile(modPath, info.Version)
+	if err != nil {
+		return "", nil, err
+	}
+	zr, err := goModuleZip(modPath, info.Version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var content string
+	var ok bool
+	if dir, isSubpackage := strings.CutPrefix(pkgPath, modPath+"/"); isSubpackage {
+		if content, ok = readZipReadme(zr, modPath, info.Version, dir); !ok {
+			if content, ok = readZipPackageDoc(zr, modPath, info.Version, dir); ok {
+				content = fmt.Sprintf("# %s\n\n%s", pkgPath, content)
+			}
+		}
+	}
+	if !ok {
+		content, ok = readZipReadme(zr, modPath, info.Version, ".")
+	}
+	if !ok {
+		return "", nil, failure.New(ErrPkgGoDevREADMENotFound,
+			failure.Message("README not found in module"),
+			failure.Context{
+				"module":  modPath,
+				"version": info.Version,
+			},
+		)
+	}
+	if mod.Module != nil && mod.Module.Deprecated != "" {
+		content = fmt.Sprintf("> **Deprecated:** %s\n\n%s", mod.Module.Deprecated, content)
+	}
+
+	var sources []source.RelatedReference
+	if info.Origin != nil && info.Origin.URL != "" {
+		detected := source.DetectSourceTypeFromURL(info.Origin.URL)
+		if detected != source.TypeUnknown {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(info.Origin.URL, detected),
+				From: "api",
+			})
+		} else {
+			sources = append(sources, source.RelatedReference{
+				Type: source.TypeHomepage,
+				URL:  info.Origin.URL,
+				From: "api",
+			})
+		}
+	}
+	if _, home, err := detectGoMetadata(modPath, nil); err == nil && home != nil {
+		if detected := source.DetectSourceTypeFromURL(home.String()); detected != source.TypeUnknown {
+			sources = append(sources, source.RelatedReference{
+				Type: detected,
+				URL:  cleanupURL(home.String(), detected),
+				From: "api",
+			})
+		}
+	}
+
+	if modPath != pkgPath {
+		sources = append(sources, source.RelatedReference{
+			Type: source.TypeGoPkgDev,
+			Path: modPath,
+			URL:  fmt.Sprintf("https://pkg.go.dev/%s", modPath),
+			From: "api",
+		})
+	}
+
+	sources = append(sources, extractRelatedSources(content, pkgPath)...)
+	return content, sources, nil
+}
+
 var (
 	// ErrInvalidMetaTag represents errors when meta tag is invalid or missing
 	ErrInvalidMetaTag ErrorCode = "InvalidMetaTag"
@@ -138,6 +235,7 @@ func detectGoMetadata(pkgPath string, client *http.Client) (*url.URL, *url.URL,
 		return nil, nil, failure.New(ErrRepositoryNotFound,
 			failure.Message("Failed to fetch go-import meta tag"),
 			failure.Context{"url": u.String()},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -227,6 +325,25 @@ func detectGoMetadata(pkgPath string, client *http.Client) (*url.URL, *url.URL,
 	return repoURL, homepageURL, nil
 }
 
+// goPkgDevRegistration declares the pkg.go.dev source
+var goPkgDevRegistration = investigator.Registration{
+	Type:         source.TypeGoPkgDev,
+	Capabilities: source.CapabilityRegistry | source.CapabilityDocumentation | source.CapabilityRepositoryURL,
+	Aliases:      []string{"go", "golang"},
+	Hosts:        []string{"pkg.go.dev"},
+	Patterns: []investigator.Pattern{
+		{
+			// Go package paths contain dots, but not at the end, such as of a sentence
+			URL:         regexp.MustCompile(`https?://pkg\.go\.dev/(?:badge/)?([\w\-~]+(?:[./][\w\-~]+)*?)(?:\.svg)?(?:\.?[^\w\-~./]|\.?$)`),
+			Command:     regexp.MustCompile(`go (?:get|install|test)(?:\s-u)? ([^@\s]+)`),
+			Description: "Go package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &GoPkgDevInvestigator{}
+	},
+}
+
 // Implementation of GoPkgDev Investigator
 type GoPkgDevInvestigator struct{}
 
@@ -0,0 +1,336 @@
+package sourceimpl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/log"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrPluginFailed represents an error when a plugin fails to fetch the package
+	ErrPluginFailed ErrorCode = "PluginFailed"
+	// PluginPrefix is the executable name prefix of source plugins
+	PluginPrefix = "miru-source-"
+)
+
+var (
+	// pluginDescribeTimeout is how long a plugin may take to describe itself
+	// Plugins are described on every run, so a hanging plugin must not block the command.
+	pluginDescribeTimeout = 5 * time.Second
+	// pluginFetchTimeout is how long a plugin may take to fetch a package
+	pluginFetchTimeout = 2 * time.Minute
+)
+
+// pluginRequest is the JSON request written to the stdin of a plugin
+type pluginRequest struct {
+	// Action is either "describe" or "fetch"
+	Action  string `json:"action"`
+	Package string `json:"package,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// pluginDescription is the JSON response of the "describe" action
+type pluginDescription struct {
+	// Type is the source type, such as the registry host name. Defaults to the plugin name.
+	Type string `json:"type"`
+	// Kind is one of "registry", "repository" or "documentation". Defaults to "registry".
+	Kind    string   `json:"kind"`
+	Aliases []string `json:"aliases"`
+	// URLPatterns are regular expressions capturing the package path from URLs
+	URLPatterns []string `json:"url_patterns"`
+	// CommandPatterns are regular expressions capturing the package path from installation commands
+	CommandPatterns []string `json:"command_patterns"`
+	// URLTemplate is the browser URL of a package, where "{package}" is replaced by the package path
+	URLTemplate string `json:"url_template"`
+}
+
+// pluginResponse is the JSON response of the "fetch" action, shaped like source.Data
+type pluginResponse struct {
+	Error          string            `json:"error"`
+	Contents       map[string]string `json:"contents"`
+	Metadata       map[string]any    `json:"metadata"`
+	BrowserURL     string            `json:"browser_url"`
+	RelatedSources []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+		URL  string `json:"url"`
+	} `json:"related_sources"`
+}
+
+// Implementation of an investigator backed by an external plugin executable
+// A plugin is an executable named "miru-source-<name>" found on PATH.
+// It receives a pluginRequest on stdin and writes the JSON response to stdout.
+type PluginInvestigator struct {
+	Name            string
+	Path            string
+	Type            source.Type
+	Capabilities    source.Capability
+	Aliases         []string
+	URLPatterns     []*regexp.Regexp
+	CommandPatterns []*regexp.Regexp
+	URLTemplate     string
+}
+
+// loadPlugins registers the plugins discovered on PATH
+func loadPlugins() {
+	for _, p := range discoverPlugins(filepath.SplitList(os.Getenv("PATH"))) {
+		if err := investigator.Register(p.registration()); err != nil {
+			log.Logger.Warn("Skipping source plugin", "plugin", p.Path, "error", err.Error())
+		}
+	}
+}
+
+// discoverPlugins finds plugin executables in the directories and asks each for its description
+// When the same plugin name is found in several directories, the first one wins.
+// Plugins are described in parallel, and those that fail to describe themselves are skipped.
+func discoverPlugins(dirs []string) []*PluginInvestigator {
+	var names, paths []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+			paths = append(paths, path)
+		}
+	}
+
+	described := make([]*PluginInvestigator, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := newPluginInvestigator(names[i], path)
+			if err != nil {
+				log.Logger.Warn("Skipping source plugin", "plugin", path, "error", err.Error())
+				return
+			}
+			described[i] = p
+		}()
+	}
+	wg.Wait()
+
+	// Plugins are registered in the order they were found
+	var found []*PluginInvestigator
+	for _, p := range described {
+		if p != nil {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// pluginName returns the plugin name from the executable file name
+// Example: miru-source-internal -> internal
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name, ok := strings.CutPrefix(file, PluginPrefix)
+	return name, ok && name != ""
+}
+
+// isExecutable returns true if the path is a regular file that can be executed
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// newPluginInvestigator runs the "describe" action of the plugin and creates the investigator
+func newPluginInvestigator(name, path string) (*PluginInvestigator, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+
+	var desc pluginDescription
+	if err := execCmdJSONInput(ctx, path, nil, pluginRequest{Action: "describe"}, &desc); err != nil {
+		return nil, failure.Wrap(err)
+	}
+
+	capabilities, err := capabilitiesFromKind(desc.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PluginInvestigator{
+		Name:         name,
+		Path:         path,
+		Type:         source.Type(desc.Type),
+		Capabilities: capabilities,
+		Aliases:      desc.Aliases,
+		URLTemplate:  desc.URLTemplate,
+	}
+	if p.Type == source.TypeUnknown {
+		p.Type = source.Type(name)
+	}
+	if len(p.Aliases) == 0 {
+		p.Aliases = []string{name}
+	}
+
+	for _, pattern := range desc.URLPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, failure.Wrap(err, failure.Context{"pattern": pattern})
+		}
+		p.URLPatterns = append(p.URLPatterns, re)
+	}
+	for _, pattern := range desc.CommandPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, failure.Wrap(err, failure.Context{"pattern": pattern})
+		}
+		p.CommandPatterns = append(p.CommandPatterns, re)
+	}
+	return p, nil
+}
+
+// registration declares the source provided by the plugin
+func (i *PluginInvestigator) registration() investigator.Registration {
+	r := investigator.Registration{
+		Type:         i.Type,
+		Capabilities: i.Capabilities,
+		Aliases:      i.Aliases,
+		New: func() investigator.SourceInvestigator {
+			return i
+		},
+	}
+	for _, re := range i.URLPatterns {
+		r.Patterns = append(r.Patterns, investigator.Pattern{
+			URL:         re,
+			Description: fmt.Sprintf("%s plugin reference", i.Name),
+		})
+	}
+	for _, re := range i.CommandPatterns {
+		r.Patterns = append(r.Patterns, investigator.Pattern{
+			Command:     re,
+			Description: fmt.Sprintf("%s plugin command", i.Name),
+		})
+	}
+	return r
+}
+
+// splitPluginPackage splits the version from a package path such as "name@1.2.3"
+// A leading "@" is part of the name, as in scoped npm packages.
+func splitPluginPackage(packagePath string) (string, string) {
+	if i := strings.LastIndex(packagePath, "@"); i > 0 {
+		return packagePath[:i], packagePath[i+1:]
+	}
+	return packagePath, ""
+}
+
+func (i *PluginInvestigator) Fetch(packagePath string) (source.Data, error) {
+	name, version := splitPluginPackage(packagePath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginFetchTimeout)
+	defer cancel()
+
+	var resp pluginResponse
+	req := pluginRequest{Action: "fetch", Package: name, Version: version}
+	if err := execCmdJSONInput(ctx, i.Path, nil, req, &resp); err != nil {
+		return source.Data{}, failure.New(ErrPluginFailed,
+			failure.Message(fmt.Sprintf("Source plugin %s failed", i.Name)),
+			failure.Context{
+				"pkg":    packagePath,
+				"plugin": i.Path,
+				"error":  err.Error(),
+			},
+		)
+	}
+	if resp.Error != "" {
+		return source.Data{}, failure.New(ErrPluginFailed,
+			failure.Message(resp.Error),
+			failure.Context{
+				"pkg":    packagePath,
+				"plugin": i.Path,
+			},
+		)
+	}
+
+	// Related sources without a type are detected from their URL
+	var relatedSources []source.RelatedReference
+	for _, r := range resp.RelatedSources {
+		t := source.Type(r.Type)
+		if t == source.TypeUnknown {
+			t = source.DetectSourceTypeFromURL(r.URL)
+		}
+		if t == source.TypeUnknown {
+			t = source.TypeHomepage
+		}
+		relatedSources = append(relatedSources, source.RelatedReference{
+			Type: t,
+			Path: r.Path,
+			URL:  r.URL,
+			From: "api",
+		})
+	}
+	if readme := resp.Contents["README.md"]; readme != "" {
+		relatedSources = append(relatedSources, extractRelatedSources(readme, name)...)
+	}
+
+	// Generate browser URL
+	rawURL := resp.BrowserURL
+	if rawURL == "" {
+		rawURL = i.GetURL(packagePath)
+	}
+	browserURL, _ := url.Parse(rawURL)
+
+	return source.Data{
+		Contents:       resp.Contents,
+		Metadata:       resp.Metadata,
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *PluginInvestigator) GetURL(packagePath string) string {
+	if i.URLTemplate == "" {
+		return ""
+	}
+	name, _ := splitPluginPackage(packagePath)
+	return strings.ReplaceAll(i.URLTemplate, "{package}", name)
+}
+
+func (i *PluginInvestigator) GetSourceType() source.Type {
+	return i.Type
+}
+
+func (i *PluginInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path with the URL patterns declared by the plugin
+	// Example: https://registry.example.com/packages/foo -> foo
+	for _, re := range i.URLPatterns {
+		if matches := re.FindStringSubmatch(url); len(matches) > 1 {
+			return matches[1], nil
+		}
+	}
+	return url, nil
+}
@@ -0,0 +1,141 @@
+package sourceimpl
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/source"
+)
+
+// examplePlugin answers the describe and fetch actions with fixed responses
+const examplePlugin = `#!/bin/sh
+input=$(cat)
+case "$input" in
+*'"describe"'*)
+	printf '%s\n' '{"type":"registry.example.com","aliases":["ex"],"url_patterns":["https?://registry\\.example\\.com/packages/([^/\\s]+)"],"url_template":"https://registry.example.com/packages/{package}"}'
+	;;
+*'"package":"broken"'*)
+	printf '%s\n' '{"error":"package not found"}'
+	;;
+*'"version":"1.2.0"'*)
+	printf '%s\n' '{"contents":{"README.md":"# widget v1.2.0"},"metadata":{"version":"1.2.0"},"related_sources":[{"url":"https://github.com/example/widget"},{"url":"https://widget.example.com"}]}'
+	;;
+esac
+`
+
+func TestPluginInvestigator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "miru-source-example"), []byte(examplePlugin), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Not executable, so it must be ignored
+	if err := os.WriteFile(filepath.Join(dir, "miru-source-disabled"), []byte(examplePlugin), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := discoverPlugins([]string{dir})
+	if len(plugins) != 1 {
+		t.Fatalf("discoverPlugins() found %d plugins, want 1", len(plugins))
+	}
+	p := plugins[0]
+
+	want := &PluginInvestigator{
+		Name:         "example",
+		Path:         filepath.Join(dir, "miru-source-example"),
+		Type:         source.Type("registry.example.com"),
+		Capabilities: source.CapabilityRegistry,
+		Aliases:      []string{"ex"},
+		URLPatterns:  []*regexp.Regexp{regexp.MustCompile(`https?://registry\.example\.com/packages/([^/\s]+)`)},
+		URLTemplate:  "https://registry.example.com/packages/{package}",
+	}
+	opt := cmp.Comparer(func(a, b *regexp.Regexp) bool { return a.String() == b.String() })
+	if diff := cmp.Diff(want, p, opt); diff != "" {
+		t.Errorf("discoverPlugins() mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := p.Fetch("widget@1.2.0")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"README.md": "# widget v1.2.0"}, data.Contents); diff != "" {
+		t.Errorf("Fetch() contents mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"version": "1.2.0"}, data.Metadata); diff != "" {
+		t.Errorf("Fetch() metadata mismatch (-want +got):\n%s", diff)
+	}
+	if got := data.BrowserURL.String(); got != "https://registry.example.com/packages/widget" {
+		t.Errorf("Fetch() browser URL = %s", got)
+	}
+	wantSources := []source.RelatedReference{
+		{Type: source.TypeGitHub, URL: "https://github.com/example/widget", From: "api"},
+		{Type: source.TypeHomepage, URL: "https://widget.example.com", From: "api"},
+	}
+	if diff := cmp.Diff(wantSources, data.RelatedSources); diff != "" {
+		t.Errorf("Fetch() sources mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := p.Fetch("broken"); err == nil {
+		t.Error("Fetch() expected error reported by the plugin")
+	}
+
+	pkg, err := p.PackageFromURL("https://registry.example.com/packages/widget")
+	if err != nil || pkg != "widget" {
+		t.Errorf("PackageFromURL() = %q, %v", pkg, err)
+	}
+}
+
+func TestDiscoverPluginsTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on Windows")
+	}
+	timeout := pluginDescribeTimeout
+	pluginDescribeTimeout = 500 * time.Millisecond
+	t.Cleanup(func() { pluginDescribeTimeout = timeout })
+
+	dir := t.TempDir()
+	for _, name := range []string{"hang1", "hang2", "hang3", "hang4"} {
+		if err := os.WriteFile(filepath.Join(dir, "miru-source-"+name), []byte("#!/bin/sh\nsleep 60\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Plugins are described in parallel, so that they do not time out one after another
+	start := time.Now()
+	if plugins := discoverPlugins([]string{dir}); len(plugins) != 0 {
+		t.Errorf("discoverPlugins() found %d plugins, want the hanging plugins to be skipped", len(plugins))
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("discoverPlugins() took %v", elapsed)
+	}
+}
+
+func TestSplitPluginPackage(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantName    string
+		wantVersion string
+	}{
+		{"widget", "widget", ""},
+		{"widget@1.2.0", "widget", "1.2.0"},
+		{"@scope/widget", "@scope/widget", ""},
+		{"@scope/widget@2.0.0", "@scope/widget", "2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, version := splitPluginPackage(tt.input)
+			if name != tt.wantName || version != tt.wantVersion {
+				t.Errorf("splitPluginPackage(%q) = %q, %q, want %q, %q", tt.input, name, version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
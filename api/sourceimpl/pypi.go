@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -35,9 +37,10 @@ func fetchPyPI(pkgPath string) (string, []source.RelatedReference, error) {
 		pkgName = pkgPath[idx+1:]
 	}
 
-	// Get package information from PyPI API
-	url := fmt.Sprintf("https://pypi.org/pypi/%s/json", pkgName)
-	resp, err := http.Get(url)
+	// Get package information from PyPI API, or the index configured for pip
+	base := pypiBaseURL()
+	url := fmt.Sprintf("%s/pypi/%s/json", base, pkgName)
+	resp, err := registryGet(url, "")
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -47,8 +50,10 @@ func fetchPyPI(pkgPath string) (string, []source.RelatedReference, error) {
 		return "", nil, failure.New(ErrRepositoryNotFound,
 			failure.Message("Failed to fetch package information from pypi.org"),
 			failure.Context{
-				"pkg": pkgPath,
+				"pkg":   pkgPath,
+				"index": redactURL(base),
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -130,6 +135,23 @@ func fetchPyPI(pkgPath string) (string, []source.RelatedReference, error) {
 	return info.Info.Description, sources, nil
 }
 
+// pyPIRegistration declares the PyPI source
+var pyPIRegistration = investigator.Registration{
+	Type:         source.TypePyPI,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"python", "py", "pypi", "pip"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?pypi\.org/project/([^/\s]+)`),
+			Command:     regexp.MustCompile(`pip install ([^@=\s]+)`),
+			Description: "Python package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &PyPIInvestigator{}
+	},
+}
+
 // Implementation of PyPI Investigator
 type PyPIInvestigator struct{}
 
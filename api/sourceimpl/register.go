@@ -0,0 +1,73 @@
+package sourceimpl
+
+import (
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrInvalidKind represents an error when the kind of a custom source or plugin is unknown
+	ErrInvalidKind ErrorCode = "InvalidKind"
+)
+
+// builtinRegistrations are the built-in sources in the order of precedence
+// Code repositories come first so that URLs such as https://github.com/owner/crates.io
+// are detected as repositories rather than registries mentioned in the path.
+var builtinRegistrations = []investigator.Registration{
+	gitHubRegistration,
+	gitLabRegistration,
+	jsrRegistration,
+	npmRegistration,
+	goPkgDevRegistration,
+	cratesIORegistration,
+	rubyGemsRegistration,
+	pyPIRegistration,
+	packagistRegistration,
+	swiftPackageIndexRegistration,
+	cocoaPodsRegistration,
+	hackageRegistration,
+	opamRegistration,
+	cranRegistration,
+	bioconductorRegistration,
+	dockerHubRegistration,
+	helmRegistration,
+	terraformRegistration,
+	openTofuRegistration,
+	homebrewRegistration,
+	alpineRegistration,
+	debianRegistration,
+	archRegistration,
+	condaForgeRegistration,
+	openVSXRegistration,
+	homepageRegistration,
+	documentationRegistration,
+}
+
+func init() {
+	for _, r := range builtinRegistrations {
+		investigator.MustRegister(r)
+	}
+
+	// Custom sources and plugins are registered after the built-in ones, which take precedence
+	investigator.RegisterLoader(loadCustomSources)
+	investigator.RegisterLoader(loadPlugins)
+}
+
+// capabilitiesFromKind returns the capabilities of a custom source or plugin from its kind
+// The kind is one of "registry", "repository" or "documentation" and defaults to "registry".
+func capabilitiesFromKind(kind string) (source.Capability, error) {
+	switch kind {
+	case "", "registry":
+		return source.CapabilityRegistry, nil
+	case "repository":
+		return source.CapabilityRepository, nil
+	case "documentation":
+		return source.CapabilityDocumentation, nil
+	default:
+		return 0, failure.New(ErrInvalidKind,
+			failure.Message("Kind must be one of registry, repository or documentation"),
+			failure.Context{"kind": kind},
+		)
+	}
+}
@@ -0,0 +1,129 @@
+package sourceimpl
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+)
+
+func TestBuiltinRegistrations(t *testing.T) {
+	for _, r := range builtinRegistrations {
+		t.Run(r.Type.String(), func(t *testing.T) {
+			if got := r.New().GetSourceType(); got != r.Type {
+				t.Errorf("New().GetSourceType() = %s, want %s", got, r.Type)
+			}
+			for _, alias := range r.Aliases {
+				if got := investigator.Aliases()[alias]; got != r.Type {
+					t.Errorf("alias %q resolves to %s, want %s", alias, got, r.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectSourceTypeFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want source.Type
+	}{
+		{"https://github.com/spf13/cobra", source.TypeGitHub},
+		{"git+https://gitlab.com/gitlab-org/cli.git", source.TypeGitLab},
+		{"https://www.npmjs.com/package/react", source.TypeNPM},
+		{"https://crates.io/crates/serde", source.TypeCratesIO},
+		{"https://archlinux.org/packages/extra/x86_64/ripgrep/", source.TypeArch},
+		{"https://wiki.archlinux.org/title/Ripgrep", source.TypeUnknown},
+		// Repositories take precedence over registries mentioned in the path
+		{"https://github.com/rust-lang/crates.io", source.TypeGitHub},
+		{"https://example.com", source.TypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := source.DetectSourceTypeFromURL(tt.url); got != tt.want {
+				t.Errorf("DetectSourceTypeFromURL(%q) = %s, want %s", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceTypeCapabilities(t *testing.T) {
+	tests := []struct {
+		sourceType        source.Type
+		wantRegistry      bool
+		wantRepository    bool
+		wantDocumentation bool
+	}{
+		{source.TypeGitHub, false, true, false},
+		{source.TypeGoPkgDev, true, false, true},
+		{source.TypeCratesIO, true, false, false},
+		{source.TypeHomepage, false, false, false},
+		{source.TypeUnknown, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceType.String(), func(t *testing.T) {
+			if got := tt.sourceType.IsRegistry(); got != tt.wantRegistry {
+				t.Errorf("IsRegistry() = %v, want %v", got, tt.wantRegistry)
+			}
+			if got := tt.sourceType.IsRepository(); got != tt.wantRepository {
+				t.Errorf("IsRepository() = %v, want %v", got, tt.wantRepository)
+			}
+			if got := tt.sourceType.IsDocumentation(); got != tt.wantDocumentation {
+				t.Errorf("IsDocumentation() = %v, want %v", got, tt.wantDocumentation)
+			}
+		})
+	}
+}
+
+func TestRegisterThirdPartySource(t *testing.T) {
+	sourceType := source.Type("registry.test.example")
+	registration := investigator.Registration{
+		Type:         sourceType,
+		Capabilities: source.CapabilityRegistry,
+		// "go" is taken by pkg.go.dev and must not be overridden
+		Aliases: []string{"testreg", "go"},
+		Hosts:   []string{"registry.test.example"},
+		Patterns: []investigator.Pattern{{
+			URL:         regexp.MustCompile(`https?://registry\.test\.example/p/([^/\s]+)`),
+			Command:     regexp.MustCompile(`testreg get ([^\s]+)`),
+			Description: "Test registry reference",
+		}},
+		New: func() investigator.SourceInvestigator {
+			return &WebsiteInvestigator{Type: sourceType}
+		},
+	}
+	// The registry is global, so register only once when the test is repeated
+	if _, ok := investigator.Lookup(sourceType); !ok {
+		if err := investigator.Register(registration); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	if err := investigator.Register(registration); err == nil {
+		t.Error("Register() expected error for a duplicate source type")
+	}
+
+	aliases := investigator.Aliases()
+	if aliases["testreg"] != sourceType || aliases["go"] != source.TypeGoPkgDev {
+		t.Errorf("Aliases() = testreg: %s, go: %s", aliases["testreg"], aliases["go"])
+	}
+	if got := investigator.New(sourceType); got == nil || got.GetSourceType() != sourceType {
+		t.Errorf("New() = %v", got)
+	}
+	if !sourceType.IsRegistry() {
+		t.Error("IsRegistry() = false, want true")
+	}
+	if got := source.DetectSourceTypeFromURL("https://registry.test.example/p/widget"); got != sourceType {
+		t.Errorf("DetectSourceTypeFromURL() = %s", got)
+	}
+	if got, path := investigator.FromURL("https://registry.test.example/p/widget"); got != sourceType || path != "widget" {
+		t.Errorf("FromURL() = %s, %s", got, path)
+	}
+
+	sources := extractRelatedSources("Install with\n\n    testreg get widget\n", "widget")
+	if len(sources) != 1 || sources[0].Type != sourceType || sources[0].Path != "widget" {
+		t.Errorf("extractRelatedSources() = %v", sources)
+	}
+}
@@ -0,0 +1,114 @@
+package sourceimpl
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/ka2n/miru/api/cache"
+)
+
+// maxCachedResponseSize is the maximum size of response bodies cached for revalidation
+// Larger responses such as module archives are transferred every time.
+const maxCachedResponseSize = 4 << 20
+
+// httpResponse is a cached HTTP response with the validators to revalidate it
+type httpResponse struct {
+	Body         string
+	ETag         string
+	LastModified string
+}
+
+// String returns the body, so that cached responses are shown as is
+func (r httpResponse) String() string {
+	return r.Body
+}
+
+// hasValidators reports whether the response can be revalidated
+func (r httpResponse) hasValidators() bool {
+	return r.ETag != "" || r.LastModified != ""
+}
+
+// newHTTPResponse returns the response to be cached
+func newHTTPResponse(resp *http.Response, body []byte) httpResponse {
+	return httpResponse{
+		Body:         string(body),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// doRevalidate sends the request, conditional on the validators of the cached response if any.
+// When the server responds 304 Not Modified, a 200 OK response with the cached body is returned instead,
+// carrying the validators of the cached response unless the server sent new ones.
+func doRevalidate(client *http.Client, req *http.Request, cached *httpResponse) (*http.Response, error) {
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNotModified || cached == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	resp.StatusCode = http.StatusOK
+	resp.Status = "200 OK"
+	resp.Body = io.NopCloser(bytes.NewReader([]byte(cached.Body)))
+	resp.ContentLength = int64(len(cached.Body))
+	if resp.Header.Get("ETag") == "" && cached.ETag != "" {
+		resp.Header.Set("ETag", cached.ETag)
+	}
+	if resp.Header.Get("Last-Modified") == "" && cached.LastModified != "" {
+		resp.Header.Set("Last-Modified", cached.LastModified)
+	}
+	return resp, nil
+}
+
+// getRevalidated sends the request, revalidating the response cached in c by the URL of the request.
+// Successful responses with validators are cached, so that they are not transferred again while unchanged.
+func getRevalidated(c *cache.Cache[httpResponse], client *http.Client, req *http.Request) (*http.Response, error) {
+	key := req.URL.Redacted()
+
+	var cached *httpResponse
+	if entry, ok := c.Get(key); ok && entry.Value.hasValidators() {
+		cached = &entry.Value
+	}
+
+	resp, err := doRevalidate(client, req, cached)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	// Larger responses are streamed to the caller without being cached
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedResponseSize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedResponseSize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if r := newHTTPResponse(resp, body); r.hasValidators() {
+		_ = c.Set(key, r)
+	}
+	return resp, nil
+}
+
+// readCloser reads from the reader and closes the closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
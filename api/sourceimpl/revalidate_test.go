@@ -0,0 +1,108 @@
+package sourceimpl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api/cache"
+)
+
+func TestGetRevalidated(t *testing.T) {
+	const etag = `"v1"`
+	var requests, transferred int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transferred++
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		io.WriteString(w, `{"name":"widget"}`)
+	}))
+	defer server.Close()
+
+	c := cache.New[httpResponse]("test-http")
+	c.SetBackend(cache.NewMemory())
+
+	var bodies []string
+	for range 2 {
+		req, err := http.NewRequest("GET", server.URL+"/widget", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := getRevalidated(c, http.DefaultClient, req)
+		if err != nil {
+			t.Fatalf("getRevalidated() error = %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") != etag {
+			t.Errorf("getRevalidated() status = %d, ETag = %q", resp.StatusCode, resp.Header.Get("ETag"))
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		bodies = append(bodies, string(body))
+	}
+
+	if diff := cmp.Diff([]string{`{"name":"widget"}`, `{"name":"widget"}`}, bodies); diff != "" {
+		t.Errorf("bodies mismatch (-want +got):\n%s", diff)
+	}
+	if requests != 2 || transferred != 1 {
+		t.Errorf("requests = %d, transferred = %d, want 2 requests and 1 transfer", requests, transferred)
+	}
+}
+
+func TestGetRevalidatedWithoutValidators(t *testing.T) {
+	var conditional bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = conditional || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	c := cache.New[httpResponse]("test-http")
+	c.SetBackend(cache.NewMemory())
+	for range 2 {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := getRevalidated(c, http.DefaultClient, req)
+		if err != nil {
+			t.Fatalf("getRevalidated() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+	if conditional {
+		t.Error("conditional request sent for a response without validators")
+	}
+	if _, ok := c.Get(server.URL); ok {
+		t.Error("response without validators cached")
+	}
+}
+
+func TestGetRevalidatedLargeResponse(t *testing.T) {
+	large := strings.Repeat("x", maxCachedResponseSize+1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, large)
+	}))
+	defer server.Close()
+
+	c := cache.New[httpResponse]("test-http")
+	c.SetBackend(cache.NewMemory())
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := getRevalidated(c, http.DefaultClient, req)
+	if err != nil {
+		t.Fatalf("getRevalidated() error = %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != large {
+		t.Errorf("getRevalidated() body of %d bytes, %v, want %d bytes", len(body), err, len(large))
+	}
+	if _, ok := c.Get(server.URL); ok {
+		t.Error("response larger than maxCachedResponseSize cached")
+	}
+}
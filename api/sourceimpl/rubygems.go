@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 	"github.com/morikuni/failure/v2"
 )
@@ -36,8 +38,8 @@ type rubyGemsPackageInfo struct {
 // Returns the formatted documentation and related sources
 func fetchRubyGemsReadme(pkgPath string) (string, []source.RelatedReference, error) {
 	// Get package information from RubyGems API
-	url := fmt.Sprintf("https://rubygems.org/api/v1/gems/%s.json", pkgPath)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/api/v1/gems/%s.json", registryBaseURL("MIRU_RUBYGEMS_URL", defaultRubyGemsURL), pkgPath)
+	resp, err := registryGet(url, "")
 	if err != nil {
 		return "", nil, failure.Wrap(err)
 	}
@@ -49,6 +51,7 @@ func fetchRubyGemsReadme(pkgPath string) (string, []source.RelatedReference, err
 			failure.Context{
 				"pkg": pkgPath,
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -58,6 +61,7 @@ func fetchRubyGemsReadme(pkgPath string) (string, []source.RelatedReference, err
 			failure.Context{
 				"pkg": pkgPath,
 			},
+			httpStatus(resp.StatusCode),
 		)
 	}
 
@@ -171,6 +175,24 @@ func formatRubyGemsDoc(info rubyGemsPackageInfo) string {
 	return strings.Join(sections, "\n\n")
 }
 
+// rubyGemsRegistration declares the RubyGems source
+var rubyGemsRegistration = investigator.Registration{
+	Type:         source.TypeRubyGems,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"ruby", "rb", "gem"},
+	Hosts:        []string{"rubygems.org"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?rubygems\.org/gems/([^/\s]+)`),
+			Command:     regexp.MustCompile(`gem install ([^@\s]+)`),
+			Description: "RubyGems package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &RubyGemsInvestigator{}
+	},
+}
+
 // Implementation of RubyGems Investigator
 type RubyGemsInvestigator struct{}
 
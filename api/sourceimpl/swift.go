@@ -0,0 +1,316 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrSwiftPackageNotFound represents an error when the package is not listed in the Swift Package Index
+	ErrSwiftPackageNotFound ErrorCode = "SwiftPackageNotFound"
+)
+
+// swiftPackageCollection represents a package collection published by the Swift Package Index
+// See: https://github.com/swiftlang/swift-package-manager/blob/main/Sources/PackageCollectionsModel/Formats/v1.md
+type swiftPackageCollection struct {
+	Name     string               `json:"name"`
+	Packages []swiftPackageDetail `json:"packages"`
+}
+
+type swiftPackageDetail struct {
+	URL       string   `json:"url"`
+	Summary   string   `json:"summary"`
+	Keywords  []string `json:"keywords"`
+	ReadmeURL string   `json:"readmeURL"`
+	License   struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"license"`
+	Versions []swiftPackageVersion `json:"versions"`
+}
+
+type swiftPackageVersion struct {
+	Version             string `json:"version"`
+	DefaultToolsVersion string `json:"defaultToolsVersion"`
+	Manifests           map[string]struct {
+		PackageName  string `json:"packageName"`
+		ToolsVersion string `json:"toolsVersion"`
+		Products     []struct {
+			Name string         `json:"name"`
+			Type map[string]any `json:"type"`
+		} `json:"products"`
+		MinimumPlatformVersions []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"minimumPlatformVersions"`
+	} `json:"manifests"`
+}
+
+// splitSwiftPackagePath splits a Swift package path into the owner and repository name
+// Accepts "owner/repo", "github.com/owner/repo", "https://github.com/owner/repo.git" and "https://swiftpackageindex.com/owner/repo"
+func splitSwiftPackagePath(pkgPath string) (string, string, error) {
+	p := strings.TrimPrefix(pkgPath, "https://")
+	p = strings.TrimPrefix(p, "http://")
+	p = strings.TrimPrefix(p, "www.")
+	p = strings.TrimPrefix(p, "github.com/")
+	p = strings.TrimPrefix(p, "swiftpackageindex.com/")
+	p = strings.TrimSuffix(p, "/")
+	p = strings.TrimSuffix(p, ".git")
+
+	parts := strings.Split(p, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", failure.New(ErrInvalidPackagePath,
+			failure.Message("Swift package path must be formatted as '<owner>/<repo>'"),
+			failure.Context{"path": pkgPath},
+		)
+	}
+	return parts[0], parts[1], nil
+}
+
+// fetchSwiftPackageIndex fetches the package metadata from the Swift Package Index
+// Returns the content, related sources, and any error
+func fetchSwiftPackageIndex(pkgPath string) (string, []source.RelatedReference, error) {
+	owner, repo, err := splitSwiftPackagePath(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The Swift Package Index publishes a package collection for each owner
+	url := fmt.Sprintf("https://swiftpackageindex.com/%s/collection.json", owner)
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrRepositoryNotFound,
+			failure.Message("Failed to fetch package collection from swiftpackageindex.com"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	// Parse JSON response
+	var collection swiftPackageCollection
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	pkg := findSwiftPackage(collection, owner, repo)
+	if pkg == nil {
+		return "", nil, failure.New(ErrSwiftPackageNotFound,
+			failure.Message("Package not found in the Swift Package Index"),
+			failure.Context{
+				"pkg": pkgPath,
+			},
+		)
+	}
+
+	// Fetch README content if available
+	var readme string
+	if pkg.ReadmeURL != "" {
+		readmeResp, err := http.Get(pkg.ReadmeURL)
+		if err != nil {
+			return "", nil, failure.Wrap(err)
+		}
+		defer readmeResp.Body.Close()
+
+		if readmeResp.StatusCode == http.StatusOK {
+			content, err := io.ReadAll(readmeResp.Body)
+			if err != nil {
+				return "", nil, failure.Wrap(err)
+			}
+			readme = string(content)
+		}
+	}
+
+	doc := formatSwiftPackageDoc(*pkg, repo, readme)
+
+	// Extract related sources
+	var sources []source.RelatedReference
+
+	// Add repository
+	repoURL := cleanupURL(pkg.URL, source.TypeUnknown)
+	sources = append(sources, source.RelatedReference{
+		Type: source.DetectSourceTypeFromURL(repoURL),
+		URL:  repoURL,
+		From: "api",
+	})
+
+	// Add documentation hosted by the Swift Package Index
+	sources = append(sources, source.RelatedReference{
+		Type: source.TypeDocumentation,
+		URL:  fmt.Sprintf("https://swiftpackageindex.com/%s/%s/documentation", owner, repo),
+		From: "api",
+	})
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(readme, repo)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// findSwiftPackage finds the package matching owner/repo in the collection
+func findSwiftPackage(collection swiftPackageCollection, owner, repo string) *swiftPackageDetail {
+	for i, pkg := range collection.Packages {
+		o, r, err := splitSwiftPackagePath(pkg.URL)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(o, owner) && strings.EqualFold(r, repo) {
+			return &collection.Packages[i]
+		}
+	}
+	return nil
+}
+
+// formatSwiftPackageDoc formats the Swift package information into a markdown document
+func formatSwiftPackageDoc(pkg swiftPackageDetail, repo, readme string) string {
+	var sections []string
+
+	// Title and version
+	title := repo
+	var latest *swiftPackageVersion
+	if len(pkg.Versions) > 0 {
+		latest = &pkg.Versions[0]
+		manifest, ok := latest.Manifests[latest.DefaultToolsVersion]
+		if ok && manifest.PackageName != "" {
+			title = manifest.PackageName
+		}
+		title = fmt.Sprintf("%s v%s", title, latest.Version)
+	}
+	sections = append(sections, fmt.Sprintf("# %s", title))
+
+	// Description
+	if pkg.Summary != "" {
+		sections = append(sections, pkg.Summary)
+	}
+
+	// Metadata
+	var metadata []string
+	if pkg.License.Name != "" {
+		metadata = append(metadata, fmt.Sprintf("**License:** %s", pkg.License.Name))
+	}
+	if latest != nil {
+		if manifest, ok := latest.Manifests[latest.DefaultToolsVersion]; ok {
+			if manifest.ToolsVersion != "" {
+				metadata = append(metadata, fmt.Sprintf("**Swift Tools:** %s", manifest.ToolsVersion))
+			}
+			var products []string
+			for _, p := range manifest.Products {
+				products = append(products, p.Name)
+			}
+			if len(products) > 0 {
+				metadata = append(metadata, fmt.Sprintf("**Products:** %s", strings.Join(products, ", ")))
+			}
+			var platforms []string
+			for _, p := range manifest.MinimumPlatformVersions {
+				platforms = append(platforms, fmt.Sprintf("%s %s", p.Name, p.Version))
+			}
+			sort.Strings(platforms)
+			if len(platforms) > 0 {
+				metadata = append(metadata, fmt.Sprintf("**Platforms:** %s", strings.Join(platforms, ", ")))
+			}
+		}
+	}
+	if len(pkg.Keywords) > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Keywords:** %s", strings.Join(pkg.Keywords, ", ")))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	sections = append(sections, fmt.Sprintf("**Repository:** %s", cleanupURL(pkg.URL, source.TypeUnknown)))
+
+	// README content
+	if readme != "" {
+		sections = append(sections, readme)
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// swiftPackageIndexRegistration declares the Swift Package Index source
+var swiftPackageIndexRegistration = investigator.Registration{
+	Type:         source.TypeSwiftPackageIndex,
+	Capabilities: source.CapabilityRegistry | source.CapabilityDocumentation,
+	Aliases:      []string{"swift", "spm"},
+	Hosts:        []string{"swiftpackageindex.com"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://(?:www\.)?swiftpackageindex\.com/([^/\s]+/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`\.package\(\s*url:\s*"https://github\.com/([^/"]+/[^/"]+?)(?:\.git)?"`),
+			Description: "Swift package reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &SwiftPackageIndexInvestigator{}
+	},
+}
+
+// Implementation of Swift Package Index Investigator
+type SwiftPackageIndexInvestigator struct{}
+
+func (i *SwiftPackageIndexInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from swiftpackageindex.com
+	content, relatedSources, err := fetchSwiftPackageIndex(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *SwiftPackageIndexInvestigator) GetURL(packagePath string) string {
+	owner, repo, err := splitSwiftPackagePath(packagePath)
+	if err != nil {
+		return fmt.Sprintf("https://swiftpackageindex.com/search?query=%s", url.QueryEscape(packagePath))
+	}
+	return fmt.Sprintf("https://swiftpackageindex.com/%s/%s", owner, repo)
+}
+
+func (i *SwiftPackageIndexInvestigator) GetSourceType() source.Type {
+	return source.TypeSwiftPackageIndex
+}
+
+func (i *SwiftPackageIndexInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Swift Package Index URL
+	// Example: https://swiftpackageindex.com/owner/repo -> owner/repo
+	prefix := "https://swiftpackageindex.com/"
+	if strings.HasPrefix(url, prefix) {
+		packagePath := url[len(prefix):]
+		if packagePath == "" {
+			return "", failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Swift package path"),
+				failure.Context{"url": url},
+			)
+		}
+		return packagePath, nil
+	}
+	return url, nil
+}
@@ -0,0 +1,148 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitSwiftPackagePath(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkgPath   string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{
+			name:      "Owner and repository",
+			pkgPath:   "apple/swift-nio",
+			wantOwner: "apple",
+			wantRepo:  "swift-nio",
+		},
+		{
+			name:      "GitHub path",
+			pkgPath:   "github.com/apple/swift-nio",
+			wantOwner: "apple",
+			wantRepo:  "swift-nio",
+		},
+		{
+			name:      "Repository URL",
+			pkgPath:   "https://github.com/apple/swift-nio.git",
+			wantOwner: "apple",
+			wantRepo:  "swift-nio",
+		},
+		{
+			name:      "Swift Package Index URL",
+			pkgPath:   "https://www.swiftpackageindex.com/apple/swift-nio/",
+			wantOwner: "apple",
+			wantRepo:  "swift-nio",
+		},
+		{
+			name:    "Missing repository",
+			pkgPath: "apple",
+			wantErr: true,
+		},
+		{
+			name:    "Nested path",
+			pkgPath: "https://swiftpackageindex.com/apple/swift-nio/documentation",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, err := splitSwiftPackagePath(tt.pkgPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitSwiftPackagePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("splitSwiftPackagePath() = %v, %v, want %v, %v", owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestFindSwiftPackage(t *testing.T) {
+	collection := swiftPackageCollection{
+		Packages: []swiftPackageDetail{
+			{URL: "https://github.com/apple/swift-log.git"},
+			{URL: "https://github.com/apple/swift-nio.git"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		owner string
+		repo  string
+		want  string
+	}{
+		{
+			name:  "Exact match",
+			owner: "apple",
+			repo:  "swift-nio",
+			want:  "https://github.com/apple/swift-nio.git",
+		},
+		{
+			name:  "Case insensitive match",
+			owner: "Apple",
+			repo:  "Swift-Log",
+			want:  "https://github.com/apple/swift-log.git",
+		},
+		{
+			name:  "Not found",
+			owner: "apple",
+			repo:  "swift-crypto",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if pkg := findSwiftPackage(collection, tt.owner, tt.repo); pkg != nil {
+				got = pkg.URL
+			}
+			if got != tt.want {
+				t.Errorf("findSwiftPackage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSwiftPackageDoc(t *testing.T) {
+	var pkg swiftPackageDetail
+	err := json.Unmarshal([]byte(`{
+  "url": "https://github.com/apple/swift-nio.git",
+  "summary": "Event-driven network application framework",
+  "keywords": ["networking", "async"],
+  "license": {"name": "Apache-2.0"},
+  "versions": [{
+    "version": "2.80.0",
+    "defaultToolsVersion": "5.9",
+    "manifests": {
+      "5.9": {
+        "packageName": "swift-nio",
+        "toolsVersion": "5.9",
+        "products": [{"name": "NIO", "type": {"library": ["automatic"]}}],
+        "minimumPlatformVersions": [{"name": "macos", "version": "10.15"}, {"name": "ios", "version": "13.0"}]
+      }
+    }
+  }]
+}`), &pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"# swift-nio v2.80.0",
+		"Event-driven network application framework",
+		"**License:** Apache-2.0 • **Swift Tools:** 5.9 • **Products:** NIO • **Platforms:** ios 13.0, macos 10.15 • **Keywords:** networking, async",
+		"**Repository:** https://github.com/apple/swift-nio",
+		"README",
+	}, "\n\n")
+	if diff := cmp.Diff(want, formatSwiftPackageDoc(pkg, "swift-nio", "README")); diff != "" {
+		t.Errorf("formatSwiftPackageDoc() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,492 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ka2n/miru/api/investigator"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+)
+
+const (
+	// ErrTerraformNotFound represents an error when the module or provider is not found in the registry
+	ErrTerraformNotFound ErrorCode = "TerraformNotFound"
+)
+
+// terraformModule represents the Terraform Registry API response for a module
+type terraformModule struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Provider    string   `json:"provider"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Source      string   `json:"source"`
+	PublishedAt string   `json:"published_at"`
+	Downloads   int      `json:"downloads"`
+	Verified    bool     `json:"verified"`
+	Versions    []string `json:"versions"`
+	Root        struct {
+		Readme  string                  `json:"readme"`
+		Inputs  []terraformModuleInput  `json:"inputs"`
+		Outputs []terraformModuleOutput `json:"outputs"`
+		// Providers required by the module
+		ProviderDependencies []terraformProviderDependency `json:"provider_dependencies"`
+	} `json:"root"`
+	Submodules []terraformSubmodule `json:"submodules"`
+}
+
+// terraformProviderDependency represents a provider required by a module
+type terraformProviderDependency struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Source    string `json:"source"`
+	Version   string `json:"version"`
+}
+
+// terraformSubmodule represents a submodule of a module
+type terraformSubmodule struct {
+	Path string `json:"path"`
+}
+
+// terraformModuleInput represents an input variable of a module
+type terraformModuleInput struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     string `json:"default"`
+	Required    bool   `json:"required"`
+}
+
+// terraformModuleOutput represents an output value of a module
+type terraformModuleOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// terraformProvider represents the Terraform Registry API response for a provider
+type terraformProvider struct {
+	Namespace   string   `json:"namespace"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Source      string   `json:"source"`
+	Tier        string   `json:"tier"`
+	PublishedAt string   `json:"published_at"`
+	Downloads   int      `json:"downloads"`
+	Versions    []string `json:"versions"`
+	Docs        []struct {
+		ID       string `json:"id"`
+		Category string `json:"category"`
+		Slug     string `json:"slug"`
+	} `json:"docs"`
+}
+
+// terraformProviderDoc represents the Terraform Registry API response for a provider document
+type terraformProviderDoc struct {
+	Data struct {
+		Attributes struct {
+			Content string `json:"content"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// splitTerraformAddress splits a registry address into its parts
+// Modules are addressed as "namespace/name/provider" and providers as "namespace/name".
+// OpenTofu shares the same address scheme, so registry hostnames of both are accepted.
+// Example: registry.terraform.io/terraform-aws-modules/vpc/aws -> [terraform-aws-modules vpc aws]
+func splitTerraformAddress(pkgPath string) ([]string, error) {
+	pkgPath = strings.TrimPrefix(pkgPath, "registry.terraform.io/")
+	pkgPath = strings.TrimPrefix(pkgPath, "registry.opentofu.org/")
+	pkgPath = strings.TrimSuffix(pkgPath, "/latest")
+
+	parts := strings.Split(strings.Trim(pkgPath, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, failure.New(ErrInvalidPackagePath,
+			failure.Message("Terraform path must be formatted as 'namespace/name/provider' for modules or 'namespace/name' for providers"),
+			failure.Context{"path": pkgPath},
+		)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil, failure.New(ErrInvalidPackagePath,
+				failure.Message("Invalid Terraform path"),
+				failure.Context{"path": pkgPath},
+			)
+		}
+	}
+	return parts, nil
+}
+
+// fetchTerraform fetches the module or provider documentation from the Terraform Registry
+// Returns the content, related sources, and any error
+func fetchTerraform(pkgPath string) (string, []source.RelatedReference, error) {
+	parts, err := splitTerraformAddress(pkgPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(parts) == 2 {
+		return fetchTerraformProvider(parts[0], parts[1])
+	}
+	return fetchTerraformModule(parts[0], parts[1], parts[2])
+}
+
+// fetchTerraformModule fetches the module README, inputs and outputs from the Terraform Registry
+func fetchTerraformModule(namespace, name, provider string) (string, []source.RelatedReference, error) {
+	apiURL := fmt.Sprintf("https://registry.terraform.io/v1/modules/%s/%s/%s", namespace, name, provider)
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrTerraformNotFound,
+			failure.Message("Failed to fetch module from registry.terraform.io"),
+			failure.Context{
+				"pkg": fmt.Sprintf("%s/%s/%s", namespace, name, provider),
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var module terraformModule
+	if err := json.NewDecoder(resp.Body).Decode(&module); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	doc := formatTerraformModuleDoc(module)
+
+	// Extract related sources
+	sources := terraformSourceReferences(module.Source)
+
+	// Extract additional sources from README content
+	docSources := extractRelatedSources(module.Root.Readme, name)
+	sources = append(sources, docSources...)
+
+	return doc, sources, nil
+}
+
+// fetchTerraformProvider fetches the provider overview from the Terraform Registry
+func fetchTerraformProvider(namespace, name string) (string, []source.RelatedReference, error) {
+	apiURL := fmt.Sprintf("https://registry.terraform.io/v1/providers/%s/%s", namespace, name)
+	resp, err := http.Get(apiURL)
+	if err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, failure.New(ErrTerraformNotFound,
+			failure.Message("Failed to fetch provider from registry.terraform.io"),
+			failure.Context{
+				"pkg": fmt.Sprintf("%s/%s", namespace, name),
+			},
+			httpStatus(resp.StatusCode),
+		)
+	}
+
+	var provider terraformProvider
+	if err := json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return "", nil, failure.Wrap(err)
+	}
+
+	// Get the provider overview document (index page of the provider docs)
+	var overview string
+	for _, d := range provider.Docs {
+		if d.Category != "overview" {
+			continue
+		}
+		docResp, err := http.Get(fmt.Sprintf("https://registry.terraform.io/v2/provider-docs/%s", d.ID))
+		if err != nil {
+			return "", nil, failure.Wrap(err)
+		}
+		defer docResp.Body.Close()
+
+		if docResp.StatusCode == http.StatusOK {
+			var providerDoc terraformProviderDoc
+			if err := json.NewDecoder(docResp.Body).Decode(&providerDoc); err != nil {
+				return "", nil, failure.Wrap(err)
+			}
+			overview = providerDoc.Data.Attributes.Content
+		}
+		break
+	}
+
+	doc := formatTerraformProviderDoc(provider, overview)
+
+	// Extract related sources
+	sources := terraformSourceReferences(provider.Source)
+
+	return doc, sources, nil
+}
+
+// terraformSourceReferences returns the repository of a module or provider source URL if it is a code repository
+func terraformSourceReferences(sourceURL string) []source.RelatedReference {
+	if sourceURL == "" {
+		return nil
+	}
+	detected := source.DetectSourceTypeFromURL(sourceURL)
+	if !detected.IsRepository() {
+		return nil
+	}
+	return []source.RelatedReference{{
+		Type: detected,
+		URL:  cleanupURL(sourceURL, detected),
+		From: "api",
+	}}
+}
+
+// formatTerraformProviderDoc formats the provider metadata and overview into a markdown document
+func formatTerraformProviderDoc(provider terraformProvider, overview string) string {
+	var sections []string
+	sections = append(sections, fmt.Sprintf("# %s/%s v%s", provider.Namespace, provider.Name, provider.Version))
+	if provider.Description != "" {
+		sections = append(sections, provider.Description)
+	}
+	var metadata []string
+	if provider.Tier != "" {
+		metadata = append(metadata, fmt.Sprintf("**Tier:** %s", provider.Tier))
+	}
+	if provider.Downloads > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Downloads:** %d", provider.Downloads))
+	}
+	if provider.PublishedAt != "" {
+		metadata = append(metadata, fmt.Sprintf("**Published:** %s", provider.PublishedAt))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+	if provider.Source != "" {
+		sections = append(sections, fmt.Sprintf("**Source:** %s", provider.Source))
+	}
+	if overview != "" {
+		sections = append(sections, overview)
+	}
+	if len(provider.Versions) > 0 {
+		sections = append(sections, formatTerraformVersions(provider.Versions))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// formatTerraformModuleDoc formats the module metadata into a markdown document
+// Inputs and outputs are rendered as tables under their own headings so they can be jumped to.
+func formatTerraformModuleDoc(module terraformModule) string {
+	var sections []string
+
+	// Title and version
+	sections = append(sections, fmt.Sprintf("# %s/%s/%s v%s", module.Namespace, module.Name, module.Provider, module.Version))
+
+	// Description
+	if module.Description != "" {
+		sections = append(sections, module.Description)
+	}
+
+	// Metadata
+	var metadata []string
+	if module.Verified {
+		metadata = append(metadata, "**Verified**")
+	}
+	if module.Downloads > 0 {
+		metadata = append(metadata, fmt.Sprintf("**Downloads:** %d", module.Downloads))
+	}
+	if module.PublishedAt != "" {
+		metadata = append(metadata, fmt.Sprintf("**Published:** %s", module.PublishedAt))
+	}
+	if len(metadata) > 0 {
+		sections = append(sections, strings.Join(metadata, " • "))
+	}
+
+	// Links
+	if module.Source != "" {
+		sections = append(sections, fmt.Sprintf("**Source:** %s", module.Source))
+	}
+
+	// README content
+	if module.Root.Readme != "" {
+		sections = append(sections, module.Root.Readme)
+	}
+
+	// Inputs
+	if len(module.Root.Inputs) > 0 {
+		rows := []string{
+			"## Inputs",
+			"",
+			"| Name | Description | Type | Default | Required |",
+			"|------|-------------|------|---------|:--------:|",
+		}
+		for _, in := range module.Root.Inputs {
+			required := "no"
+			if in.Required {
+				required = "yes"
+			}
+			def := "n/a"
+			if !in.Required {
+				def = terraformCode(in.Default)
+			}
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s | %s |",
+				terraformCode(in.Name), terraformCell(in.Description), terraformCode(in.Type), def, required))
+		}
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+
+	// Outputs
+	if len(module.Root.Outputs) > 0 {
+		rows := []string{
+			"## Outputs",
+			"",
+			"| Name | Description |",
+			"|------|-------------|",
+		}
+		for _, out := range module.Root.Outputs {
+			rows = append(rows, fmt.Sprintf("| %s | %s |", terraformCode(out.Name), terraformCell(out.Description)))
+		}
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+
+	// Required providers
+	if len(module.Root.ProviderDependencies) > 0 {
+		rows := []string{"## Providers", ""}
+		for _, p := range module.Root.ProviderDependencies {
+			line := fmt.Sprintf("- `%s`", p.Source)
+			if p.Version != "" {
+				line += fmt.Sprintf(" (%s)", p.Version)
+			}
+			rows = append(rows, line)
+		}
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+
+	// Submodules
+	if len(module.Submodules) > 0 {
+		rows := []string{"## Submodules", ""}
+		for _, s := range module.Submodules {
+			rows = append(rows, fmt.Sprintf("- `%s`", s.Path))
+		}
+		sections = append(sections, strings.Join(rows, "\n"))
+	}
+
+	// Versions
+	if len(module.Versions) > 0 {
+		sections = append(sections, formatTerraformVersions(module.Versions))
+	}
+
+	// Join all sections with double newlines
+	return strings.Join(sections, "\n\n")
+}
+
+// formatTerraformVersions formats the most recent versions, newest first
+func formatTerraformVersions(versions []string) string {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return compareVersions(b, a)
+	})
+	if len(sorted) > 10 {
+		sorted = sorted[:10]
+	}
+	return fmt.Sprintf("**Recent Versions:** %s", strings.Join(sorted, ", "))
+}
+
+// terraformCell escapes a value for use in a markdown table cell
+func terraformCell(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// terraformCode formats a value as inline code for use in a markdown table cell
+func terraformCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	// Multi-line values such as object types are collapsed onto a single line
+	s = strings.Join(strings.Fields(s), " ")
+	return "`" + strings.ReplaceAll(s, "|", "\\|") + "`"
+}
+
+// terraformRegistration declares the Terraform source
+var terraformRegistration = investigator.Registration{
+	Type:         source.TypeTerraform,
+	Capabilities: source.CapabilityRegistry,
+	Aliases:      []string{"terraform", "tf"},
+	Hosts:        []string{"registry.terraform.io"},
+	Patterns: []investigator.Pattern{
+		{
+			URL:         regexp.MustCompile(`https?://registry\.terraform\.io/modules/([^/\s]+/[^/\s]+/[^/\s)]+)`),
+			Command:     regexp.MustCompile(`source\s*=\s*"(?:registry\.terraform\.io/)?([\w-]+/[\w-]+/[\w-]+)"`),
+			Description: "Terraform module reference",
+		},
+		{
+			URL:         regexp.MustCompile(`https?://registry\.terraform\.io/providers/([^/\s]+/[^/\s)]+)`),
+			Description: "Terraform provider reference",
+		},
+	},
+	New: func() investigator.SourceInvestigator {
+		return &TerraformInvestigator{}
+	},
+}
+
+// Implementation of Terraform Investigator
+type TerraformInvestigator struct{}
+
+func (i *TerraformInvestigator) Fetch(packagePath string) (source.Data, error) {
+	// Process to retrieve data from registry.terraform.io
+	content, relatedSources, err := fetchTerraform(packagePath)
+	if err != nil {
+		return source.Data{}, err
+	}
+
+	// Generate browser URL
+	browserURL, _ := url.Parse(i.GetURL(packagePath))
+
+	return source.Data{
+		Contents:       map[string]string{"README.md": content},
+		FetchedAt:      time.Now(),
+		RelatedSources: relatedSources,
+		BrowserURL:     browserURL,
+	}, nil
+}
+
+func (i *TerraformInvestigator) GetURL(packagePath string) string {
+	parts, err := splitTerraformAddress(packagePath)
+	if err != nil {
+		return fmt.Sprintf("https://registry.terraform.io/search?q=%s", url.QueryEscape(packagePath))
+	}
+	if len(parts) == 2 {
+		return fmt.Sprintf("https://registry.terraform.io/providers/%s/latest", strings.Join(parts, "/"))
+	}
+	return fmt.Sprintf("https://registry.terraform.io/modules/%s/latest", strings.Join(parts, "/"))
+}
+
+func (i *TerraformInvestigator) GetSourceType() source.Type {
+	return source.TypeTerraform
+}
+
+func (i *TerraformInvestigator) PackageFromURL(url string) (string, error) {
+	// Extract package path from Terraform Registry URL
+	// Example: https://registry.terraform.io/modules/terraform-aws-modules/vpc/aws/latest -> terraform-aws-modules/vpc/aws
+	// Example: https://registry.terraform.io/providers/hashicorp/aws/latest -> hashicorp/aws
+	for prefix, n := range map[string]int{
+		"https://registry.terraform.io/modules/":   3,
+		"https://registry.terraform.io/providers/": 2,
+	} {
+		if strings.HasPrefix(url, prefix) {
+			parts := strings.Split(url[len(prefix):], "/")
+			if len(parts) < n {
+				return "", failure.New(ErrInvalidPackagePath,
+					failure.Message("Invalid Terraform Registry path"),
+					failure.Context{"url": url},
+				)
+			}
+			return strings.Join(parts[:n], "/"), nil
+		}
+	}
+	return url, nil
+}
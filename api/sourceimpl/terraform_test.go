@@ -0,0 +1,75 @@
+package sourceimpl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitTerraformAddress(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "terraform-aws-modules/vpc/aws", want: []string{"terraform-aws-modules", "vpc", "aws"}},
+		{path: "registry.terraform.io/terraform-aws-modules/vpc/aws", want: []string{"terraform-aws-modules", "vpc", "aws"}},
+		{path: "registry.opentofu.org/hashicorp/aws", want: []string{"hashicorp", "aws"}},
+		{path: "hashicorp/aws/latest", want: []string{"hashicorp", "aws"}},
+		{path: "aws", wantErr: true},
+		{path: "a/b/c/d", wantErr: true},
+		{path: "hashicorp//aws", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := splitTerraformAddress(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitTerraformAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("splitTerraformAddress() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatTerraformModuleDoc(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "terraform_module.json"))
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	var module terraformModule
+	if err := json.Unmarshal(content, &module); err != nil {
+		t.Fatalf("Failed to decode test file: %v", err)
+	}
+
+	want := "# terraform-aws-modules/s3-bucket/aws v4.1.2\n\n" +
+		"Terraform module to create AWS S3 resources\n\n" +
+		"**Downloads:** 1024 • **Published:** 2024-04-26T09:35:51.000Z\n\n" +
+		"**Source:** https://github.com/terraform-aws-modules/terraform-aws-s3-bucket\n\n" +
+		"# AWS S3 bucket Terraform module\n\n" +
+		"## Inputs\n\n" +
+		"| Name | Description | Type | Default | Required |\n" +
+		"|------|-------------|------|---------|:--------:|\n" +
+		"| `bucket` | The name of the bucket. | `string` | `null` | no |\n" +
+		"| `tags` | A mapping of tags<br>to assign to the bucket \\| object. | `map(string)` | n/a | yes |\n" +
+		"| `lifecycle_rule` |  | `object({ id = string })` | `{}` | no |\n\n" +
+		"## Outputs\n\n" +
+		"| Name | Description |\n" +
+		"|------|-------------|\n" +
+		"| `s3_bucket_id` | The name of the bucket. |\n\n" +
+		"## Providers\n\n" +
+		"- `hashicorp/aws` (>= 5.27)\n\n" +
+		"## Submodules\n\n" +
+		"- `modules/notification`\n\n" +
+		"**Recent Versions:** 4.1.2, 4.1.1, 4.0.0, 3.15.2, 3.9.0"
+
+	if diff := cmp.Diff(want, formatTerraformModuleDoc(module)); diff != "" {
+		t.Errorf("formatTerraformModuleDoc() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,15 +1,20 @@
 package sourceimpl
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	html2md "github.com/JohannesKaufmann/html-to-markdown"
-	"github.com/ka2n/miru/api/cache"
 	"github.com/ka2n/miru/api/source"
 	"github.com/ka2n/miru/log"
 	"github.com/mackee/go-readability"
@@ -17,34 +22,46 @@ import (
 
 // execCmdJSON executes a command and unmarshals the JSON output into the provided struct
 func execCmdJSON(cmdStr string, args []string, out interface{}) error {
+	return execCmdJSONInput(context.Background(), cmdStr, args, nil, out)
+}
+
+// execCmdJSONInput executes a command with the JSON encoded input on stdin
+// and unmarshals the JSON output into the provided struct.
+// If in is nil, nothing is written to stdin. The command is killed when the context is done.
+func execCmdJSONInput(ctx context.Context, cmdStr string, args []string, in interface{}, out interface{}) error {
 	logger := log.Logger.With("cmd", cmdStr, "args", args)
 
 	logger.Debug("Executing command")
-	cmd := exec.Command(cmdStr, args...)
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-
-	decoder := json.NewDecoder(stdoutPipe)
-	err = decoder.Decode(out)
-	if err != nil {
-		return err
+	cmd := exec.CommandContext(ctx, cmdStr, args...)
+	// Do not wait for the output of child processes left by the killed command
+	cmd.WaitDelay = time.Second
+	if in != nil {
+		input, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = bytes.NewReader(input)
 	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 
-	err = cmd.Wait()
-	if err != nil {
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			logger.Error("Command timed out", "error", ctxErr.Error(), "stderr", stderr.String())
+			return fmt.Errorf("%s: %w", cmdStr, ctxErr)
+		}
 		if exitError, ok := err.(*exec.ExitError); ok {
-			logger.Error("Command failed", "error", exitError.Error())
+			logger.Error("Command failed", "error", exitError.Error(), "stderr", stderr.String())
 		} else {
 			logger.Error("Command error", "error", err.Error())
 		}
 		return err
 	}
+
+	if err := json.NewDecoder(&stdout).Decode(out); err != nil {
+		return err
+	}
 	logger.Debug("Command completed successfully")
 	return nil
 }
@@ -53,40 +70,44 @@ func fetchHTML(url *url.URL, forceUpdate bool) (string, error) {
 	// Generate cache key
 	cacheKey := url.String()
 
-	// Create cache instance for string type
-	htmlCache := cache.New[string]("html")
-
 	// Get HTML from cache or fetch it
-	html, err := htmlCache.GetOrSet(cacheKey, func() (string, error) {
+	ConfigureCache()
+	page, err := htmlCache.GetOrSet(cacheKey, func() (httpResponse, error) {
 		// Create HTTP client
 		client := &http.Client{}
 
 		// Create request
 		req, err := http.NewRequest("GET", url.String(), nil)
 		if err != nil {
-			return "", err
+			return httpResponse{}, err
 		}
 
 		// Set user agent to avoid being blocked
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 
+		// Revalidate the expired page if possible
+		var cached *httpResponse
+		if entry, ok := htmlCache.Get(cacheKey); ok && entry.Value.hasValidators() {
+			cached = &entry.Value
+		}
+
 		// Send request
-		resp, err := client.Do(req)
+		resp, err := doRevalidate(client, req, cached)
 		if err != nil {
-			return "", err
+			return httpResponse{}, err
 		}
 		defer resp.Body.Close()
 
 		// Read response body
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return "", err
+			return httpResponse{}, err
 		}
 
-		return string(body), nil
+		return newHTTPResponse(resp, body), nil
 	}, forceUpdate)
 
-	return html, err
+	return page.Body, err
 }
 
 // FetchHTML fetches HTML content from a URL with cache support
@@ -185,3 +206,73 @@ func cleanupURL(url string, t source.Type) string {
 		return url
 	}
 }
+
+// compareVersions compares two version strings segment by segment
+// Numeric segments are compared numerically and other segments lexically,
+// so "1.10.0" is newer than "1.9.2". A leading "v" is ignored.
+// It returns a negative number if a < b, zero if a == b, and a positive number if a > b.
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		v = strings.TrimPrefix(v, "v")
+		var parts []string
+		var current strings.Builder
+		var digit bool
+		for _, r := range v {
+			isDigit := r >= '0' && r <= '9'
+			if !isDigit && !unicode.IsLetter(r) {
+				// Separators such as ".", "-", "+" and "~"
+				if current.Len() > 0 {
+					parts = append(parts, current.String())
+					current.Reset()
+				}
+				continue
+			}
+			if current.Len() > 0 && isDigit != digit {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			digit = isDigit
+			current.WriteRune(r)
+		}
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+		}
+		return parts
+	}
+
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return na - nb
+			}
+		case errA == nil:
+			// Numeric segments are newer than pre-release labels
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	// "1.0.1" is newer than "1.0", but "1.0" is newer than "1.0-beta"
+	switch {
+	case len(pa) > len(pb):
+		if _, err := strconv.Atoi(pa[len(pb)]); err != nil {
+			return -1
+		}
+		return 1
+	case len(pa) < len(pb):
+		if _, err := strconv.Atoi(pb[len(pa)]); err != nil {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
@@ -67,3 +67,27 @@ func TestCleanupURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.10.0", b: "1.9.2", want: 1},
+		{a: "1.0.0", b: "1.0.0", want: 0},
+		{a: "v2.0.0", b: "2.0.0", want: 0},
+		{a: "1.0", b: "1.0.1", want: -1},
+		{a: "1.0", b: "1.0-beta", want: 1},
+		{a: "5.7.1", b: "5.7.1~rc1", want: 1},
+		{a: "1.0.0-rc2", b: "1.0.0-rc10", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+				t.Errorf("compareVersions(%q, %q) = %v, want sign of %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
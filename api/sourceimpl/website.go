@@ -5,9 +5,26 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/ka2n/miru/api/investigator"
 	"github.com/ka2n/miru/api/source"
 )
 
+// homepageRegistration declares homepages of packages, which are only opened in the browser
+var homepageRegistration = investigator.Registration{
+	Type: source.TypeHomepage,
+	New: func() investigator.SourceInvestigator {
+		return &WebsiteInvestigator{Type: source.TypeHomepage}
+	},
+}
+
+// documentationRegistration declares documentation sites of packages, which are only opened in the browser
+var documentationRegistration = investigator.Registration{
+	Type: source.TypeDocumentation,
+	New: func() investigator.SourceInvestigator {
+		return &WebsiteInvestigator{Type: source.TypeDocumentation}
+	},
+}
+
 // Implementation of Website Investigator
 type WebsiteInvestigator struct {
 	Type source.Type
@@ -0,0 +1,469 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ka2n/miru/api"
+	"github.com/ka2n/miru/api/cache"
+	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/api/sourceimpl"
+	"github.com/morikuni/failure/v2"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheKindFlg      string
+	cacheOlderThanFlg string
+	cacheExpiredFlg   bool
+	cacheNewerThanFlg string
+	cachePackageFlg   []string
+	cacheSourceFlg    []string
+
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Cache management commands",
+	}
+)
+
+func init() {
+	cacheClearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Clear all cached documentation",
+		Long:  "Remove all cached documentation files from the cache directory",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cache.Clear(); err != nil {
+				return failure.Wrap(err)
+			}
+			fmt.Println("Cache cleared successfully")
+			return nil
+		},
+	}
+
+	cacheListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached entries",
+		Long:  "List cached entries with their kind, key, size, age and when they expire",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheList,
+	}
+	cacheListCmd.Flags().StringVar(&cacheKindFlg, "kind", "", "Only list entries of the kind (fetch, html, http, failure)")
+
+	cacheStatsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show cache statistics",
+		Long:  "Show the number of cached entries and their size per kind and source type",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheStats,
+	}
+
+	cacheShowCmd := &cobra.Command{
+		Use:   "show <key>",
+		Short: "Show a cached entry",
+		Long:  "Show the decoded value of a cached entry. Keys are listed by `miru cache list`.",
+		Example: `  miru cache show npmjs.com:react
+  miru cache show --kind html https://example.com/`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCacheShow,
+	}
+	cacheShowCmd.Flags().StringVar(&cacheKindFlg, "kind", "", "Kind of the entry (fetch, html, http, failure)")
+
+	cachePruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached entries",
+		Long:  "Remove cached entries matching all of the given conditions. Without conditions, expired entries are removed.",
+		Example: `  miru cache prune --older-than 7d
+  miru cache prune --kind html`,
+		Args: cobra.NoArgs,
+		RunE: runCachePrune,
+	}
+	cachePruneCmd.Flags().StringVar(&cacheKindFlg, "kind", "", "Only remove entries of the kind (fetch, html, http, failure)")
+	cachePruneCmd.Flags().StringVar(&cacheOlderThanFlg, "older-than", "", "Only remove entries older than the duration (e.g. 36h, 7d)")
+	cachePruneCmd.Flags().BoolVar(&cacheExpiredFlg, "expired", false, "Only remove expired entries")
+
+	cacheExportCmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export cached entries to an archive",
+		Long: `Export cached entries matching all of the given conditions to an archive, to be imported on another machine.
+Without conditions, all entries are exported. Packages are exported with the sources related to them.`,
+		Example: `  miru prefetch package.json && miru cache export docs.tar.gz --source npm
+  miru cache export docs.tar.gz --package npm:react --package go:github.com/spf13/cobra
+  miru cache export docs.tar.gz --newer-than 30d`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCacheExport,
+	}
+	cacheExportCmd.Flags().StringVar(&cacheKindFlg, "kind", "", "Only export entries of the kind (fetch, html, http, failure)")
+	cacheExportCmd.Flags().StringArrayVar(&cachePackageFlg, "package", nil, "Only export the package as <lang>:<package> and its related sources (repeatable)")
+	cacheExportCmd.Flags().StringArrayVar(&cacheSourceFlg, "source", nil, "Only export entries of the source type, language or host (repeatable)")
+	cacheExportCmd.Flags().StringVar(&cacheNewerThanFlg, "newer-than", "", "Only export entries newer than the duration (e.g. 36h, 7d)")
+
+	cacheImportCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import cached entries from an archive",
+		Long:  "Import cached entries from an archive created by `miru cache export`. Entries older than the cached ones are skipped.",
+		Example: `  miru cache import docs.tar.gz
+  miru --offline npm react`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCacheImport,
+	}
+
+	cacheCmd.AddCommand(cacheClearCmd, cacheListCmd, cacheStatsCmd, cacheShowCmd, cachePruneCmd, cacheExportCmd, cacheImportCmd)
+}
+
+func runCacheList(cmd *cobra.Command, args []string) error {
+	infos, err := cache.List()
+	if err != nil {
+		return failure.Wrap(err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tKEY\tSIZE\tAGE\tEXPIRES")
+	for _, info := range infos {
+		if cacheKindFlg != "" && info.Kind != cacheKindFlg {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Kind, info.Key, formatBytes(info.Size), formatAge(info.Age()), formatExpiry(info))
+	}
+	return w.Flush()
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	infos, err := cache.List()
+	if err != nil {
+		return failure.Wrap(err)
+	}
+
+	type stat struct {
+		entries int
+		bytes   int64
+	}
+	var total stat
+	byKind := map[string]*stat{}
+	bySource := map[string]*stat{}
+	add := func(m map[string]*stat, name string, size int64) {
+		if m[name] == nil {
+			m[name] = &stat{}
+		}
+		m[name].entries++
+		m[name].bytes += size
+	}
+	for _, info := range infos {
+		total.entries++
+		total.bytes += info.Size
+		add(byKind, info.Kind, info.Size)
+		add(bySource, cacheEntrySource(info), info.Size)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Location: %s\n", cache.Default().Location())
+	fmt.Fprintf(out, "Entries:  %d (%s)\n", total.entries, formatBytes(total.bytes))
+
+	for _, group := range []struct {
+		title string
+		stats map[string]*stat
+	}{
+		{"KIND", byKind},
+		{"SOURCE", bySource},
+	} {
+		names := make([]string, 0, len(group.stats))
+		for name := range group.stats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(out)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\tENTRIES\tSIZE\n", group.title)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", name, group.stats[name].entries, formatBytes(group.stats[name].bytes))
+		}
+		if err := w.Flush(); err != nil {
+			return failure.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func runCacheShow(cmd *cobra.Command, args []string) error {
+	infos, err := cache.Lookup(cacheKindFlg, args[0])
+	if err != nil {
+		return failure.Wrap(err)
+	}
+	if len(infos) == 0 {
+		return failure.New(InvalidArguments,
+			failure.Message("Cache entry not found"),
+			failure.Context{"key": args[0], "kind": cacheKindFlg},
+		)
+	}
+
+	out := cmd.OutOrStdout()
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		if err := showCacheEntry(out, info); err != nil {
+			return failure.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// showCacheEntry writes the metadata and the value of the entry
+// Strings and values with a String method, such as cached pages, are written as is, and other values as JSON.
+func showCacheEntry(out io.Writer, info cache.Info) error {
+	fmt.Fprintf(out, "Kind:    %s\n", info.Kind)
+	fmt.Fprintf(out, "Key:     %s\n", info.Key)
+	fmt.Fprintf(out, "Created: %s (%s ago)\n", info.CreatedAt.Format(time.RFC3339), formatAge(info.Age()))
+	fmt.Fprintf(out, "Expires: %s (%s)\n", info.ExpiresAt().Format(time.RFC3339), formatExpiry(info))
+	fmt.Fprintf(out, "Size:    %s\n", formatBytes(info.Size))
+	if info.Path != "" {
+		fmt.Fprintf(out, "Path:    %s\n", info.Path)
+	}
+	fmt.Fprintln(out)
+
+	value, err := cache.Decode(info)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case string:
+		fmt.Fprintln(out, v)
+		return nil
+	case fmt.Stringer:
+		fmt.Fprintln(out, v.String())
+		return nil
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(value)
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	var olderThan time.Duration
+	if cacheOlderThanFlg != "" {
+		d, err := cache.ParseDuration(cacheOlderThanFlg)
+		if err != nil {
+			return failure.Wrap(err, failure.WithCode(InvalidArguments),
+				failure.Message("Invalid duration for --older-than"),
+				failure.Context{"value": cacheOlderThanFlg},
+			)
+		}
+		olderThan = d
+	}
+	expiredOnly := cacheExpiredFlg || (cacheKindFlg == "" && olderThan == 0)
+
+	removed, freed, err := cache.Prune(func(info cache.Info) bool {
+		if cacheKindFlg != "" && info.Kind != cacheKindFlg {
+			return false
+		}
+		if olderThan > 0 && info.Age() < olderThan {
+			return false
+		}
+		return !expiredOnly || info.Expired
+	})
+	if err != nil {
+		return failure.Wrap(err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %d entries (%s)\n", removed, formatBytes(freed))
+	return nil
+}
+
+func runCacheExport(cmd *cobra.Command, args []string) error {
+	infos, err := cache.List()
+	if err != nil {
+		return failure.Wrap(err)
+	}
+	selected, err := selectCacheEntries(infos)
+	if err != nil {
+		return failure.Wrap(err)
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return failure.Wrap(err)
+	}
+	n, err := cache.Export(f, selected)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return failure.Wrap(err, failure.Context{"file": args[0]})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d entries to %s\n", n, args[0])
+	return nil
+}
+
+// selectCacheEntries returns the entries matching the flags of the export command
+func selectCacheEntries(infos []cache.Info) ([]cache.Info, error) {
+	var newerThan time.Duration
+	if cacheNewerThanFlg != "" {
+		d, err := cache.ParseDuration(cacheNewerThanFlg)
+		if err != nil {
+			return nil, failure.Wrap(err, failure.WithCode(InvalidArguments),
+				failure.Message("Invalid duration for --newer-than"),
+				failure.Context{"value": cacheNewerThanFlg},
+			)
+		}
+		newerThan = d
+	}
+
+	// Sources are given as source types, languages or hosts
+	sources := map[string]bool{}
+	for _, s := range cacheSourceFlg {
+		if t, ok := api.ResolveSourceName(s); ok {
+			s = t.String()
+		}
+		sources[s] = true
+	}
+
+	var packages map[string]bool
+	if len(cachePackageFlg) > 0 {
+		keys, err := cachePackageKeys(infos, cachePackageFlg)
+		if err != nil {
+			return nil, err
+		}
+		packages = keys
+	}
+
+	var selected []cache.Info
+	for _, info := range infos {
+		if cacheKindFlg != "" && info.Kind != cacheKindFlg {
+			continue
+		}
+		if newerThan > 0 && info.Age() >= newerThan {
+			continue
+		}
+		if len(sources) > 0 && !sources[cacheEntrySource(info)] {
+			continue
+		}
+		if packages != nil && (info.Kind != "fetch" || !packages[info.Key]) {
+			continue
+		}
+		selected = append(selected, info)
+	}
+	return selected, nil
+}
+
+// cachePackageKeys returns the keys of the fetched data of the packages given as <lang>:<package>,
+// and of the sources related to them, which are also used to show the documentation.
+func cachePackageKeys(infos []cache.Info, packages []string) (map[string]bool, error) {
+	fetched := map[string]cache.Info{}
+	for _, info := range infos {
+		if info.Kind == "fetch" {
+			fetched[info.Key] = info
+		}
+	}
+
+	keys := map[string]bool{}
+	var queue []string
+	for _, p := range packages {
+		lang, pkg, ok := strings.Cut(p, ":")
+		if !ok || lang == "" || pkg == "" {
+			return nil, failure.New(InvalidArguments,
+				failure.Message("Packages must be given as <lang>:<package>"),
+				failure.Context{"package": p},
+			)
+		}
+		query, err := api.NewInitialQuery(api.UserInput{PackagePath: pkg, Language: lang})
+		if err != nil {
+			return nil, failure.Wrap(err)
+		}
+		queue = append(queue, sourceimpl.FetchCacheKey(query.SourceRef.Type, query.SourceRef.Path))
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+
+		info, ok := fetched[key]
+		if !ok {
+			continue
+		}
+		value, err := cache.Decode(info)
+		if err != nil {
+			continue
+		}
+		if data, ok := value.(source.Data); ok {
+			for _, r := range data.RelatedSources {
+				ref := r.ToSourceReference()
+				queue = append(queue, sourceimpl.FetchCacheKey(ref.Type, ref.Path))
+			}
+		}
+	}
+	return keys, nil
+}
+
+func runCacheImport(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return failure.Wrap(err)
+	}
+	defer f.Close()
+
+	imported, skipped, err := cache.Import(f)
+	if err != nil {
+		return failure.Wrap(err, failure.Context{"file": args[0]})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported %d entries (%d skipped as not newer than the cached ones)\n", imported, skipped)
+	return nil
+}
+
+// cacheEntrySource returns the source of the entry for statistics
+// Fetched data is keyed by "type:path", and HTML pages by the URL.
+func cacheEntrySource(info cache.Info) string {
+	if u, err := url.Parse(info.Key); err == nil && u.Host != "" {
+		return u.Host
+	}
+	if sourceType, _, ok := strings.Cut(info.Key, ":"); ok {
+		return sourceType
+	}
+	return "unknown"
+}
+
+// formatAge formats a duration in the largest unit, such as "3d" or "5h"
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
+
+// formatExpiry formats when the entry expires, such as "in 5h" or "expired"
+func formatExpiry(info cache.Info) string {
+	if info.Expired {
+		return "expired"
+	}
+	return "in " + formatAge(time.Until(info.ExpiresAt()))
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/ka2n/miru/api"
 	"github.com/pkg/browser"
 )
@@ -28,6 +29,8 @@ type keyMap struct {
 	Search     key.Binding
 	NextMatch  key.Binding
 	PrevMatch  key.Binding
+	NextHead   key.Binding
+	PrevHead   key.Binding
 	ShowMenu   key.Binding
 	Reload     key.Binding
 	Help       key.Binding
@@ -73,6 +76,14 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("N"),
 			key.WithHelp("N", "previous search result"),
 		),
+		NextHead: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "next section"),
+		),
+		PrevHead: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "previous section"),
+		),
 		ShowMenu: key.NewBinding(
 			key.WithKeys("tab"),
 			key.WithHelp("tab", "show menu"),
@@ -101,7 +112,8 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown},
-		{k.GotoTop, k.GotoBottom, k.Search, k.NextMatch, k.PrevMatch},
+		{k.GotoTop, k.GotoBottom, k.NextHead, k.PrevHead},
+		{k.Search, k.NextMatch, k.PrevMatch},
 		{k.ShowMenu, k.Reload, k.Help, k.Quit},
 	}
 }
@@ -170,6 +182,7 @@ func (i listItem) Description() string { return i.desc }
 type pagerModel struct {
 	viewport viewport.Model
 	content  string
+	headings []int // Line numbers of headings in the rendered content
 	search   searchState
 	keyMap   keyMap     // Keyboard shortcuts
 	help     help.Model // Help model
@@ -250,6 +263,8 @@ func (s *stashModel) View(width int, isReloading bool, pagerError string, result
 		statusBar = " " + defaultStyle.Foreground(lipgloss.Color("110")).Render("Reloading...")
 	} else if pagerError != "" {
 		statusBar = " " + defaultStyle.Foreground(lipgloss.Color("9")).Render("Error: "+pagerError)
+	} else if resultData.Stale {
+		statusBar = " " + defaultStyle.Foreground(lipgloss.Color("214")).Render("Stale")
 	}
 
 	// Calculate width for padding
@@ -353,6 +368,7 @@ func (m *model) SetContent(content string) {
 		return
 	}
 	m.pager.content = renderedContent
+	m.pager.headings = headingLines(renderedContent, markdownHeadings(content))
 }
 
 // initMenuList initializes the list model for menu mode
@@ -659,6 +675,10 @@ func (m *model) updateNormalMode(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.inputMode = searchMode
 			m.pager.search.input.Focus()
 			return m, textinput.Blink
+		case "]":
+			m.nextHeading()
+		case "[":
+			m.previousHeading()
 		case "n":
 			if len(m.pager.search.matches) > 0 {
 				m.nextMatch()
@@ -941,6 +961,78 @@ func (m *model) clearHighlights() {
 	m.pager.viewport.SetContent(m.pager.content)
 }
 
+// nextHeading scrolls to the first heading below the top of the viewport
+func (m *model) nextHeading() {
+	for _, line := range m.pager.headings {
+		if line > m.pager.viewport.YOffset {
+			m.pager.viewport.SetYOffset(line)
+			return
+		}
+	}
+}
+
+// previousHeading scrolls to the last heading above the top of the viewport
+func (m *model) previousHeading() {
+	for i := len(m.pager.headings) - 1; i >= 0; i-- {
+		if line := m.pager.headings[i]; line < m.pager.viewport.YOffset {
+			m.pager.viewport.SetYOffset(line)
+			return
+		}
+	}
+}
+
+// markdownHeadings returns the text of the ATX headings in the markdown source
+// Lines inside fenced code blocks are ignored.
+func markdownHeadings(content string) []string {
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		text := strings.TrimLeft(trimmed, "#")
+		if len(trimmed)-len(text) > 6 || (text != "" && text[0] != ' ' && text[0] != '\t') {
+			continue
+		}
+		if text = headingText(text); text != "" {
+			headings = append(headings, text)
+		}
+	}
+	return headings
+}
+
+// headingLines locates the headings in the rendered content in order and returns their line numbers
+func headingLines(rendered string, headings []string) []int {
+	var lines []int
+	renderedLines := strings.Split(ansi.Strip(rendered), "\n")
+	next := 0
+	for _, heading := range headings {
+		// Long headings may be wrapped by the renderer, so only the beginning is compared
+		if runes := []rune(heading); len(runes) > 40 {
+			heading = string(runes[:40])
+		}
+		for i := next; i < len(renderedLines); i++ {
+			if strings.Contains(headingText(renderedLines[i]), heading) {
+				lines = append(lines, i)
+				next = i + 1
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// headingText normalizes heading text by dropping emphasis markers and extra spaces
+func headingText(s string) string {
+	s = strings.NewReplacer("`", "", "*", "", "_", "").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
 func filterMenuItemByShortcut(items []menuItem, shortcut string) (menuItem, bool) {
 	for _, item := range items {
 		if item.shortcut == shortcut {
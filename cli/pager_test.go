@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/go-cmp/cmp"
+	"github.com/ka2n/miru/api"
+)
+
+func TestMarkdownHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "ATX headings",
+			content: "# Title\n\ntext\n\n## Inputs\n\n### `name` *option*\n",
+			want:    []string{"Title", "Inputs", "name option"},
+		},
+		{
+			name:    "Headings in fenced code blocks",
+			content: "# Usage\n\n```sh\n# install\n```\n\n~~~\n## not a heading\n~~~\n\n## Outputs\n",
+			want:    []string{"Usage", "Outputs"},
+		},
+		{
+			name:    "Not headings",
+			content: "#hashtag\n####### seven\n#\n  ## Indented\n",
+			want:    []string{"Indented"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, markdownHeadings(tt.content)); diff != "" {
+				t.Errorf("markdownHeadings() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHeadingLines(t *testing.T) {
+	rendered := strings.Join([]string{
+		"  \x1b[1mInputs\x1b[0m",
+		"  The inputs of the module. Inputs are listed below.",
+		"",
+		"  \x1b[1mOutputs\x1b[0m",
+		"  Outputs",
+		"  A very long heading that is wrapped by the renderer onto",
+		"  two lines",
+	}, "\n")
+	headings := []string{"Inputs", "Outputs", "Outputs", "A very long heading that is wrapped by the renderer onto two lines", "Missing"}
+
+	want := []int{0, 3, 4, 5}
+	if diff := cmp.Diff(want, headingLines(rendered, headings)); diff != "" {
+		t.Errorf("headingLines() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHeadingNavigation(t *testing.T) {
+	var sections []string
+	for _, heading := range []string{"# Title", "## Inputs", "## Outputs"} {
+		sections = append(sections, heading, strings.Repeat("Lorem ipsum dolor sit amet.\n\n", 10))
+	}
+	m, err := NewPager(strings.Join(sections, "\n\n"), "dark", nil, api.Result{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.pager.viewport = viewport.New(80, 5)
+	m.pager.viewport.SetContent(m.pager.content)
+
+	if len(m.pager.headings) != 3 {
+		t.Fatalf("headings = %v, want 3 lines", m.pager.headings)
+	}
+	lines := strings.Split(ansi.Strip(m.pager.content), "\n")
+	topLine := func() string {
+		return strings.TrimSpace(lines[m.pager.viewport.YOffset])
+	}
+
+	// "]" moves to the headings below the top of the viewport
+	// The title is rendered without "#" below a blank line.
+	var got []string
+	for range 4 {
+		m.nextHeading()
+		got = append(got, topLine())
+	}
+	if diff := cmp.Diff([]string{"Title", "## Inputs", "## Outputs", "## Outputs"}, got); diff != "" {
+		t.Errorf("nextHeading() mismatch (-want +got):\n%s", diff)
+	}
+
+	// "[" moves back to the headings above it
+	got = nil
+	for range 3 {
+		m.previousHeading()
+		got = append(got, topLine())
+	}
+	if diff := cmp.Diff([]string{"## Inputs", "Title", "Title"}, got); diff != "" {
+		t.Errorf("previousHeading() mismatch (-want +got):\n%s", diff)
+	}
+}
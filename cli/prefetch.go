@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ka2n/miru/api"
+	"github.com/ka2n/miru/api/cache"
+	"github.com/ka2n/miru/api/source"
+	"github.com/morikuni/failure/v2"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	prefetchJobsFlg int
+
+	prefetchCmd = &cobra.Command{
+		Use:   "prefetch <manifest>",
+		Short: "Fetch documentation of the dependencies of a project into the cache",
+		Long: `Fetch documentation of the packages declared in a dependency manifest into the cache,
+so that it can be read offline or exported with ` + "`miru cache export`" + `.
+
+Supported manifests are package.json, go.mod, requirements.txt, pyproject.toml, Cargo.toml, composer.json and Gemfile.
+Other files are read as a list of "<lang> <package>" lines.`,
+		Example: `  miru prefetch package.json
+  miru prefetch go.mod && miru cache export docs.tar.gz --source go
+  miru prefetch packages.txt -j 8`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPrefetch,
+	}
+)
+
+func init() {
+	prefetchCmd.Flags().IntVarP(&prefetchJobsFlg, "jobs", "j", 4, "Number of packages fetched in parallel")
+}
+
+func runPrefetch(cmd *cobra.Command, args []string) error {
+	if prefetchJobsFlg < 1 {
+		return failure.New(InvalidArguments,
+			failure.Message("--jobs must be at least 1"),
+			failure.Context{"jobs": fmt.Sprint(prefetchJobsFlg)},
+		)
+	}
+
+	inputs, err := api.ParseManifest(args[0])
+	if err != nil {
+		return failure.Wrap(err)
+	}
+
+	out := cmd.OutOrStdout()
+	var (
+		mu      sync.Mutex
+		fetched int
+		failed  int
+	)
+	report := func(input api.UserInput, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "✗ %s %s: %v\n", input.Language, input.PackagePath, err)
+			return
+		}
+		fetched++
+		fmt.Fprintf(out, "✓ %s %s\n", input.Language, input.PackagePath)
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(prefetchJobsFlg)
+	for _, input := range inputs {
+		eg.Go(func() error {
+			report(input, prefetch(input))
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	// Let expired entries being refreshed in the background be saved
+	cache.Wait()
+
+	fmt.Fprintf(out, "Prefetched %d packages (%d failed)\n", fetched, failed)
+	return nil
+}
+
+// prefetch fetches the documentation of the package and the sources related to it into the cache
+func prefetch(input api.UserInput) error {
+	query, err := api.NewInitialQuery(input)
+	if err != nil {
+		return err
+	}
+	if query.SourceRef.Type == source.TypeUnknown {
+		return failure.New(UnsupportedLanguage, failure.Message("Unsupported language"))
+	}
+
+	investigation := api.NewInvestigation(query)
+	if err := investigation.Do(); err != nil {
+		return err
+	}
+	if data := investigation.CollectedData[query.SourceRef.Type]; data.FetchError != nil {
+		return data.FetchError
+	}
+	return nil
+}
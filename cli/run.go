@@ -12,6 +12,7 @@ import (
 	"github.com/ka2n/miru/api"
 	"github.com/ka2n/miru/api/cache"
 	"github.com/ka2n/miru/api/source"
+	"github.com/ka2n/miru/api/sourceimpl"
 	"github.com/ka2n/miru/mcp"
 	"github.com/mattn/go-isatty"
 	"github.com/morikuni/failure/v2"
@@ -25,6 +26,8 @@ var (
 	browserFlg browseTargetFlag
 	langFlg    string
 	outputFlag string
+	offlineFlg bool
+	maxAgeFlg  string
 
 	rootCmd    *cobra.Command
 	versionCmd *cobra.Command
@@ -37,15 +40,6 @@ func init() {
 		Short:         "View package documentation",
 		SilenceErrors: true,
 		SilenceUsage:  true,
-		Example: `1. lang as the first argument
-  miru go github.com/spf13/cobra
-2. Using the -l flag
-  miru github.com/spf13/cobra --lang go 
-
-Supported languages:
-` + formatSupportedLanguages() + `
-Supported target(for -b= flag):
-` + formatSupportedBrowserTargets(),
 		Long: `miru is a CLI tool for viewing package documentation with a man-like interface.
 It supports multiple documentation sources and can display documentation in both
 terminal and browser.`,
@@ -58,13 +52,42 @@ terminal and browser.`,
 			// Validate the number of arguments
 			return cobra.RangeArgs(1, 2)(cmd, args)
 		},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			sourceimpl.ConfigureCache()
+			if offlineFlg {
+				cache.Offline = true
+			}
+			if maxAgeFlg != "" {
+				d, err := cache.ParseDuration(maxAgeFlg)
+				if err != nil || d == 0 {
+					return failure.New(InvalidArguments,
+						failure.Message("Invalid duration for --max-age"),
+						failure.Context{"value": maxAgeFlg},
+					)
+				}
+				cache.MaxAge = d
+			}
+			return nil
+		},
 		RunE: runRoot,
 	}
 
+	// The supported languages include those of source plugins, which are run to describe
+	// themselves, so the example is formatted only when the help is shown
+	helpFunc := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		if cmd == rootCmd && cmd.Example == "" {
+			cmd.Example = formatRootExample()
+		}
+		helpFunc(cmd, args)
+	})
+
 	rootCmd.Flags().VarP(&browserFlg, "browser", "b", "Open browser")
 	rootCmd.Flag("browser").NoOptDefVal = "default"
 	rootCmd.Flags().StringVarP(&langFlg, "lang", "l", "", "Specify package language explicitly")
 	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output format (json)")
+	rootCmd.PersistentFlags().StringVar(&maxAgeFlg, "max-age", "", "Refetch cached documentation older than the duration (e.g. 1h, 7d)")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlg, "offline", false, "Serve documentation only from the cache, including expired entries (MIRU_OFFLINE=1)")
 
 	// Version command
 	versionCmd = &cobra.Command{
@@ -79,25 +102,21 @@ terminal and browser.`,
 	}
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(mcp.Command())
-
-	cacheCmd := &cobra.Command{
-		Use:   "cache",
-		Short: "Cache management commands",
-	}
-	cacheClearCmd := &cobra.Command{
-		Use:   "clear",
-		Short: "Clear all cached documentation",
-		Long:  "Remove all cached documentation files from the cache directory",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := cache.Clear(); err != nil {
-				return failure.Wrap(err)
-			}
-			fmt.Println("Cache cleared successfully")
-			return nil
-		},
-	}
-	cacheCmd.AddCommand(cacheClearCmd)
 	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(prefetchCmd)
+}
+
+// formatRootExample formats the example of the root command
+func formatRootExample() string {
+	return `1. lang as the first argument
+  miru go github.com/spf13/cobra
+2. Using the -l flag
+  miru github.com/spf13/cobra --lang go 
+
+Supported languages:
+` + formatSupportedLanguages() + `
+Supported target(for -b= flag):
+` + formatSupportedBrowserTargets()
 }
 
 // formatSupportedLanguages formats the supported languages for display
@@ -179,9 +198,16 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		if err := investigation.Do(); err != nil {
 			return api.Result{}, err
 		}
-		return api.CreateResult(investigation), nil
+		result := api.CreateResult(investigation)
+		if result.Stale {
+			fmt.Fprintln(logOut, "Showing expired cached documentation, which may be outdated")
+		}
+		return result, nil
 	}
 
+	// Let expired entries being refreshed in the background be saved for the next run
+	defer cache.Wait()
+
 	// Browse mode
 	if browserFlg.IsSet {
 		result, err := l(false)
@@ -298,6 +324,7 @@ func displayJSON(i api.InitialQuery, r api.Result, writer io.Writer) error {
 		Registry   string      `json:"registry,omitempty"`
 		Document   string      `json:"document,omitempty"`
 		URLs       []strLink   `json:"urls"`
+		Stale      bool        `json:"stale,omitempty"`
 	}
 
 	var (
@@ -344,6 +371,7 @@ func displayJSON(i api.InitialQuery, r api.Result, writer io.Writer) error {
 		Registry:   registry,
 		Document:   docs,
 		URLs:       urls,
+		Stale:      r.Stale,
 	}
 
 	enc := json.NewEncoder(writer)